@@ -0,0 +1,814 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/card"
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/deposit"
+	"btc-giftcard/internal/featureflag"
+	"btc-giftcard/internal/guardrail"
+	"btc-giftcard/internal/lnd"
+	"btc-giftcard/internal/treasury"
+	"btc-giftcard/internal/withdrawal"
+	"btc-giftcard/internal/worker"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+	"btc-giftcard/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+)
+
+// ============================================================================
+// ADMIN CLI
+// ============================================================================
+//
+// Operator commands run against the configured environment:
+//
+//	go run ./cmd/admin slow-queries [-limit 20] [-min-mean-ms 10] [-reset]
+//	go run ./cmd/admin flags list
+//	go run ./cmd/admin flags set <flag> [-enabled] [-percentage 0-100] [-merchants a,b]
+//	go run ./cmd/admin flags clear <flag>
+//	go run ./cmd/admin deposit-invoice -amount <sats> [-account <id>]
+//	go run ./cmd/admin products list
+//	go run ./cmd/admin products add -sku <sku> -name <name> -amount <cents> -currency <ccy> [-artwork <url>] [-fee-bps 0] [-fee-fixed 0]
+//	go run ./cmd/admin products enable|disable <sku>
+//	go run ./cmd/admin products report [-days 30]
+//	go run ./cmd/admin batches generate -retailer <name> -quantity <n> (-sku <sku> | -amount <cents> -currency <ccy>)
+//	go run ./cmd/admin batches list
+//	go run ./cmd/admin batches export <batch-id>
+//	go run ./cmd/admin withdrawals list
+//	go run ./cmd/admin withdrawals request -amount <sats> -to <address> -by <operator> [-reason <text>]
+//	go run ./cmd/admin withdrawals approve|reject <id> -by <operator>
+//	go run ./cmd/admin rotate-code <card-id>
+//	go run ./cmd/admin otc-fills list [-limit 50]
+//	go run ./cmd/admin otc-fills record -fiat <cents> -currency <ccy> -sats <sats> [-reference <id>] [-at <RFC3339>]
+//	go run ./cmd/admin otc-fills report [-weeks 12]
+//
+// slow-queries reads pg_stat_statements (enabled in docker-compose.yml and
+// pgsql/init.d) and prints the statements with the highest mean execution
+// time, so index regressions are caught as volume grows.
+//
+// flags manages the Redis overrides of feature flags (internal/featureflag);
+// clear reverts a flag to its config.toml default.
+//
+// deposit-invoice issues a Lightning invoice for a treasury deposit (e.g. a
+// B2B top-up); cmd/worker/invoice_settlement credits it once paid.
+//
+// products manages the fixed-denomination card catalog: availability toggles
+// and the per-SKU sales report (cards sold, face value, revenue).
+//
+// batches pre-generates inactive retail cards and exports their codes (one
+// per line) for printing; cards are activated at the POS via
+// card.Service.ActivateCard.
+//
+// withdrawals pays profit out of the treasury to an allowlisted address
+// ([withdrawals] allowed_destinations). A request is only sent once a second
+// operator approves it, and only if the treasury still covers card
+// liabilities plus the reserve buffer afterwards.
+//
+// rotate-code issues a new code for a card whose code may have leaked and
+// emails it to the owner; the old code stops working (card.Service.RotateCode).
+//
+// otc-fills records the treasury's OTC purchases of BTC. report compares,
+// per currency and week, the price cards were funded at with the realized
+// OTC price, so the quote spread can be tuned on data: a negative delta
+// means cards were priced below what the BTC actually cost.
+// ============================================================================
+
+// command is an admin subcommand. args excludes the subcommand name.
+type command struct {
+	usage string
+	run   func(ctx context.Context, cfg config.ApiConfig, args []string) error
+}
+
+var commands = map[string]command{
+	"slow-queries": {
+		usage: "report the slowest statements from pg_stat_statements",
+		run:   runSlowQueries,
+	},
+	"flags": {
+		usage: "list, set or clear feature flag overrides (list | set <flag> | clear <flag>)",
+		run:   runFlags,
+	},
+	"deposit-invoice": {
+		usage: "issue a Lightning invoice for a treasury or account deposit",
+		run:   runDepositInvoice,
+	},
+	"products": {
+		usage: "manage the card product catalog (list | add | enable <sku> | disable <sku> | report)",
+		run:   runProducts,
+	},
+	"batches": {
+		usage: "generate, list or export retail card batches (generate | list | export <batch-id>)",
+		run:   runBatches,
+	},
+	"withdrawals": {
+		usage: "request and approve owner withdrawals (list | request | approve <id> | reject <id>)",
+		run:   runWithdrawals,
+	},
+	"rotate-code": {
+		usage: "replace a card's code after a suspected exposure (rotate-code <card-id>)",
+		run:   runRotateCode,
+	},
+	"otc-fills": {
+		usage: "record OTC fills and report conversion accuracy (list | record | report)",
+		run:   runOTCFills,
+	},
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		printUsage()
+		return errors.New("missing command")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	if err := logger.Init(logger.GetEnv()); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer logger.Sync()
+
+	// Resolve config path relative to this file (project root)
+	_, filename, _, _ := runtime.Caller(0)
+	root := filepath.Dir(filename)
+	configPath := config.Path(root).Join("config.toml", "..", "..")
+
+	var cfg config.ApiConfig
+	if err := config.Load(configPath, &cfg); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return cmd.run(context.Background(), cfg, args[1:])
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: admin <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for name, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, cmd.usage)
+	}
+}
+
+// openDB connects to the configured database.
+func openDB(cfg config.ApiConfig) (*database.DB, error) {
+	var dbCfg database.Config
+	if err := copier.Copy(&dbCfg, &cfg.Database); err != nil {
+		return nil, fmt.Errorf("failed to copy database config: %w", err)
+	}
+	db, err := database.NewDB(dbCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database connection: %w", err)
+	}
+	return db, nil
+}
+
+// connectLND connects to the configured LND node, refusing the same network
+// configurations the services refuse at startup (internal/guardrail): the
+// admin CLI issues invoices and sends withdrawals, so a testnet config pointed
+// at a mainnet node must fail here too.
+func connectLND(ctx context.Context, cfg config.ApiConfig) (*lnd.Client, error) {
+	if err := guardrail.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("refusing to connect to LND: %w", err)
+	}
+
+	client, err := lnd.NewClient(worker.LNDConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LND: %w", err)
+	}
+
+	info, err := client.GetInfo(ctx)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to get LND node info: %w", err)
+	}
+	if err := guardrail.CheckNode(cfg.LND.Network, info); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("refusing to connect to LND: %w", err)
+	}
+	return client, nil
+}
+
+// initCache connects the global Redis client.
+func initCache(cfg config.ApiConfig) error {
+	var redisCfg cache.Config
+	if err := copier.Copy(&redisCfg, &cfg.Redis); err != nil {
+		return fmt.Errorf("failed to copy cache config: %w", err)
+	}
+	if err := cache.Init(redisCfg); err != nil {
+		return fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	return nil
+}
+
+func runSlowQueries(ctx context.Context, cfg config.ApiConfig, args []string) error {
+	fs := flag.NewFlagSet("slow-queries", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "maximum number of statements to show")
+	minMeanMs := fs.Float64("min-mean-ms", 10, "only show statements with a mean time of at least this many ms")
+	reset := fs.Bool("reset", false, "reset pg_stat_statements counters after printing the report")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := db.SlowQueries(ctx, *limit, *minMeanMs)
+	if err != nil {
+		if errors.Is(err, database.ErrQueryStatsUnavailable) {
+			return fmt.Errorf("%w (add shared_preload_libraries=pg_stat_statements and run CREATE EXTENSION pg_stat_statements)", err)
+		}
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MEAN_MS\tCALLS\tTOTAL_MS\tROWS\tCACHE_HIT\tQUERY")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%.2f\t%d\t%.0f\t%d\t%.1f%%\t%s\n",
+			s.MeanTimeMs, s.Calls, s.TotalTimeMs, s.Rows, s.CacheHitRatio*100, compactQuery(s.Query, 120))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(stats) == 0 {
+		fmt.Printf("No statements with mean time >= %.1f ms\n", *minMeanMs)
+	}
+
+	if *reset {
+		if err := db.ResetQueryStats(ctx); err != nil {
+			return err
+		}
+		fmt.Println("pg_stat_statements counters reset")
+	}
+
+	return nil
+}
+
+// compactQuery collapses whitespace so a statement fits on one report line,
+// truncating it to width characters.
+func compactQuery(query string, width int) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > width {
+		return query[:width-3] + "..."
+	}
+	return query
+}
+
+func runFlags(ctx context.Context, cfg config.ApiConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: flags list | set <flag> [flags] | clear <flag>")
+	}
+
+	if err := initCache(cfg); err != nil {
+		return err
+	}
+	defer cache.Close()
+
+	flags := featureflag.FromConfig(cfg.Features)
+
+	switch args[0] {
+	case "list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FLAG\tENABLED\tPERCENTAGE\tMERCHANTS")
+		for _, name := range featureflag.Known {
+			rule, err := flags.Rule(ctx, name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s\t%t\t%d\t%s\n", name, rule.Enabled, rule.Percentage, strings.Join(rule.Merchants, ","))
+		}
+		return w.Flush()
+
+	case "set":
+		if len(args) < 2 {
+			return errors.New("usage: flags set <flag> [-enabled] [-percentage 0-100] [-merchants a,b]")
+		}
+		name, err := knownFlag(args[1])
+		if err != nil {
+			return err
+		}
+
+		fs := flag.NewFlagSet("flags set", flag.ContinueOnError)
+		enabled := fs.Bool("enabled", false, "enable the flag (omit to disable)")
+		percentage := fs.Int("percentage", 0, "rollout percentage (0-100)")
+		merchants := fs.String("merchants", "", "comma-separated merchant IDs always enabled")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+
+		rule := featureflag.Rule{Enabled: *enabled, Percentage: *percentage}
+		if *merchants != "" {
+			rule.Merchants = strings.Split(*merchants, ",")
+		}
+		if err := featureflag.SetOverride(ctx, name, rule); err != nil {
+			return err
+		}
+		fmt.Printf("%s override set: enabled=%t percentage=%d merchants=%v\n", name, rule.Enabled, rule.Percentage, rule.Merchants)
+		return nil
+
+	case "clear":
+		if len(args) < 2 {
+			return errors.New("usage: flags clear <flag>")
+		}
+		name, err := knownFlag(args[1])
+		if err != nil {
+			return err
+		}
+		if err := featureflag.ClearOverride(ctx, name); err != nil {
+			return err
+		}
+		fmt.Printf("%s override cleared (config default applies)\n", name)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown flags subcommand %q", args[0])
+	}
+}
+
+// knownFlag rejects typos so an override is never written for a flag nothing checks.
+func knownFlag(name string) (featureflag.Flag, error) {
+	for _, known := range featureflag.Known {
+		if string(known) == name {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("unknown feature flag %q", name)
+}
+
+func runDepositInvoice(ctx context.Context, cfg config.ApiConfig, args []string) error {
+	fs := flag.NewFlagSet("deposit-invoice", flag.ContinueOnError)
+	amount := fs.Int64("amount", 0, "invoice amount in sats")
+	account := fs.String("account", "", "account to credit (empty = house treasury)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *amount <= 0 {
+		return errors.New("-amount must be positive")
+	}
+
+	var accountID *string
+	if *account != "" {
+		accountID = account
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	client, err := connectLND(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	expiry := time.Duration(cfg.Deposits.InvoiceExpirySeconds) * time.Second
+	svc := deposit.NewService(database.NewDepositRepository(db), client, expiry)
+
+	d, err := svc.CreateInvoice(ctx, accountID, *amount)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deposit %s (%d sats, expires %s)\n%s\n", d.ID, d.ExpectedSats, d.ExpiresAt.Format(time.RFC3339), d.PaymentRequest)
+	return nil
+}
+
+func runProducts(ctx context.Context, cfg config.ApiConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: products list | add [flags] | enable <sku> | disable <sku> | report [-days 30]")
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	products := database.NewProductRepository(db)
+
+	switch args[0] {
+	case "list":
+		list, err := products.List(ctx, false)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SKU\tNAME\tFACE\tPRICE\tCURRENCY\tAVAILABLE\tID")
+		for _, p := range list {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n",
+				p.SKU, p.Name, formatCents(p.FiatAmountCents), formatCents(p.PurchasePriceCents()), p.FiatCurrency, p.IsAvailable, p.ID)
+		}
+		return w.Flush()
+
+	case "add":
+		fs := flag.NewFlagSet("products add", flag.ContinueOnError)
+		sku := fs.String("sku", "", "stock keeping unit (e.g. EUR-50)")
+		name := fs.String("name", "", "display name")
+		amount := fs.Int64("amount", 0, "face value in cents")
+		currency := fs.String("currency", "", "fiat currency (e.g. EUR)")
+		artwork := fs.String("artwork", "", "artwork image URL")
+		feeBps := fs.Int64("fee-bps", 0, "percentage fee on top of face value, in basis points")
+		feeFixed := fs.Int64("fee-fixed", 0, "flat fee on top of face value, in cents")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *sku == "" || *name == "" || *amount <= 0 || len(*currency) != 3 {
+			return errors.New("-sku, -name, -amount and a 3-letter -currency are required")
+		}
+
+		p := &database.CardProduct{
+			ID:              uuid.New().String(),
+			SKU:             *sku,
+			Name:            *name,
+			FiatAmountCents: *amount,
+			FiatCurrency:    strings.ToUpper(*currency),
+			ArtworkURL:      *artwork,
+			FeeBps:          *feeBps,
+			FeeFixedCents:   *feeFixed,
+			IsAvailable:     true,
+			CreatedAt:       time.Now().UTC(),
+		}
+		if err := products.Create(ctx, p); err != nil {
+			return err
+		}
+		fmt.Printf("Product %s created (%s, price %s %s)\n", p.SKU, p.ID, formatCents(p.PurchasePriceCents()), p.FiatCurrency)
+		return nil
+
+	case "enable", "disable":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: products %s <sku>", args[0])
+		}
+		if err := products.SetAvailable(ctx, args[1], args[0] == "enable"); err != nil {
+			return err
+		}
+		fmt.Printf("%s %sd\n", args[1], args[0])
+		return nil
+
+	case "report":
+		fs := flag.NewFlagSet("products report", flag.ContinueOnError)
+		days := fs.Int("days", 30, "report on cards created in the last N days")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		to := time.Now().UTC()
+		sales, err := products.SalesBySKU(ctx, to.AddDate(0, 0, -*days), to)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SKU\tNAME\tCARDS\tFACE_VALUE\tREVENUE\tCURRENCY")
+		for _, s := range sales {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+				s.SKU, s.Name, s.CardsSold, formatCents(s.FaceValueCents), formatCents(s.PurchasePriceCents), s.FiatCurrency)
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown products subcommand %q", args[0])
+	}
+}
+
+// formatCents renders a fiat amount in cents as "12.34".
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+func runBatches(ctx context.Context, cfg config.ApiConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: batches generate [flags] | list | export <batch-id>")
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	batches := database.NewBatchRepository(db)
+
+	switch args[0] {
+	case "generate":
+		fs := flag.NewFlagSet("batches generate", flag.ContinueOnError)
+		retailer := fs.String("retailer", "", "retail partner the batch is distributed through")
+		quantity := fs.Int("quantity", 0, "number of cards to generate")
+		sku := fs.String("sku", "", "catalog product the cards are sold as")
+		amount := fs.Int64("amount", 0, "face value in cents (without -sku)")
+		currency := fs.String("currency", "", "fiat currency (without -sku)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		products := database.NewProductRepository(db)
+		req := card.GenerateBatchRequest{Retailer: *retailer, Quantity: *quantity, FiatAmountCents: *amount, FiatCurrency: *currency}
+		if *sku != "" {
+			product, err := products.GetBySKU(ctx, *sku)
+			if err != nil {
+				return err
+			}
+			req.ProductID = product.ID
+		}
+
+		// Batch generation only needs the card, product and batch repositories
+		svc := card.NewService(card.Config{}, card.Deps{Cards: database.NewCardRepository(db), Products: products, Batches: batches})
+		batch, codes, err := svc.GenerateBatch(ctx, req)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Batch %s: %d inactive %s %s cards for %s (export with: batches export %s)\n",
+			batch.ID, len(codes), formatCents(batch.FiatAmountCents), batch.FiatCurrency, batch.Retailer, batch.ID)
+		return nil
+
+	case "list":
+		list, err := batches.List(ctx, 50)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tRETAILER\tFACE\tCURRENCY\tQUANTITY\tACTIVATED\tPRINTED\tCREATED")
+		for _, b := range list {
+			printed := "-"
+			if b.PrintedAt != nil {
+				printed = b.PrintedAt.Format(time.DateOnly)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+				b.ID, b.Retailer, formatCents(b.FiatAmountCents), b.FiatCurrency, b.Quantity, b.ActivatedCount, printed, b.CreatedAt.Format(time.DateOnly))
+		}
+		return w.Flush()
+
+	case "export":
+		if len(args) < 2 {
+			return errors.New("usage: batches export <batch-id>")
+		}
+		if _, err := batches.GetByID(ctx, args[1]); err != nil {
+			return err
+		}
+		codes, err := batches.Codes(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		for _, code := range codes {
+			fmt.Println(code)
+		}
+		return batches.MarkPrinted(ctx, args[1], time.Now().UTC())
+
+	default:
+		return fmt.Errorf("unknown batches subcommand %q", args[0])
+	}
+}
+
+func runWithdrawals(ctx context.Context, cfg config.ApiConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: withdrawals list | request [flags] | approve <id> -by <operator> | reject <id> -by <operator>")
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := database.NewWithdrawalRepository(db)
+
+	if args[0] == "list" {
+		list, err := repo.List(ctx, 50)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSATS\tDESTINATION\tSTATUS\tREQUESTED_BY\tDECIDED_BY\tTX_HASH\tCREATED")
+		for _, wd := range list {
+			decidedBy, txHash := "-", "-"
+			if wd.DecidedBy != nil {
+				decidedBy = *wd.DecidedBy
+			}
+			if wd.TxHash != nil {
+				txHash = *wd.TxHash
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				wd.ID, wd.AmountSats, wd.DestinationAddress, wd.Status, wd.RequestedBy, decidedBy, txHash, wd.CreatedAt.Format(time.DateOnly))
+		}
+		return w.Flush()
+	}
+
+	// Everything else checks reserves against the custodian and takes the treasury lock
+	if err := initCache(cfg); err != nil {
+		return err
+	}
+	defer cache.Close()
+
+	client, err := connectLND(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	custodian := treasury.NewLNDCustodian(client, cfg.LND.MaxPaymentFeeSats)
+	lock := card.NewService(card.Config{}, card.Deps{Custodian: custodian})
+	svc := withdrawal.NewService(repo, database.NewCardRepository(db), custodian, lock, withdrawal.Config{
+		AllowedDestinations:  cfg.Withdrawals.AllowedDestinations,
+		ReserveBufferBps:     cfg.Withdrawals.ReserveBufferBps,
+		MinReserveBufferSats: cfg.Withdrawals.MinReserveBufferSats,
+		TargetConf:           cfg.Withdrawals.TargetConf,
+	})
+
+	switch args[0] {
+	case "request":
+		fs := flag.NewFlagSet("withdrawals request", flag.ContinueOnError)
+		amount := fs.Int64("amount", 0, "amount to withdraw in sats")
+		to := fs.String("to", "", "allowlisted destination address")
+		by := fs.String("by", "", "operator requesting the withdrawal")
+		reason := fs.String("reason", "", "note for the approver and the books")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		wd, err := svc.Request(ctx, *amount, *to, *by, *reason)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Withdrawal %s of %d sats to %s requested, approve with: withdrawals approve %s -by <another operator>\n",
+			wd.ID, wd.AmountSats, wd.DestinationAddress, wd.ID)
+		return nil
+
+	case "approve", "reject":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: withdrawals %s <id> -by <operator>", args[0])
+		}
+		fs := flag.NewFlagSet("withdrawals "+args[0], flag.ContinueOnError)
+		by := fs.String("by", "", "operator deciding (must differ from the requester)")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+
+		if args[0] == "reject" {
+			if err := svc.Reject(ctx, args[1], *by); err != nil {
+				return err
+			}
+			fmt.Printf("Withdrawal %s rejected\n", args[1])
+			return nil
+		}
+
+		wd, err := svc.Approve(ctx, args[1], *by)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Withdrawal %s sent: %d sats to %s (tx %s)\n", wd.ID, wd.AmountSats, wd.DestinationAddress, *wd.TxHash)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown withdrawals subcommand %q", args[0])
+	}
+}
+
+func runRotateCode(ctx context.Context, cfg config.ApiConfig, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: rotate-code <card-id>")
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// The card lock and the owner notification are in Redis
+	if err := initCache(cfg); err != nil {
+		return err
+	}
+	defer cache.Close()
+
+	svc := card.NewService(card.Config{}, card.Deps{
+		Cards: database.NewCardRepository(db),
+		Queue: queue.NewStreamQueue(cache.Client),
+	})
+	rotated, err := svc.RotateCode(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	// Printed in case the owner email doesn't arrive; give it to the verified owner only
+	fmt.Printf("Card %s: new code %s (emailed to the owner); the old code no longer redeems\n", rotated.CardID, rotated.Code)
+	return nil
+}
+
+func runOTCFills(ctx context.Context, cfg config.ApiConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: otc-fills list [-limit 50] | record [flags] | report [-weeks 12]")
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	fills := database.NewOTCFillRepository(db)
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("otc-fills list", flag.ContinueOnError)
+		limit := fs.Int("limit", 50, "number of fills to show")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		list, err := fills.List(ctx, *limit)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FILLED\tFIAT\tCURRENCY\tSATS\tPRICE\tREFERENCE\tID")
+		for _, f := range list {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%.2f\t%s\t%s\n",
+				f.FilledAt.Format(time.RFC3339), formatCents(f.FiatAmountCents), f.FiatCurrency, f.BTCAmountSats, f.Price(), f.Reference, f.ID)
+		}
+		return w.Flush()
+
+	case "record":
+		fs := flag.NewFlagSet("otc-fills record", flag.ContinueOnError)
+		fiat := fs.Int64("fiat", 0, "fiat paid in cents, fees included")
+		currency := fs.String("currency", "", "fiat currency (e.g. EUR)")
+		sats := fs.Int64("sats", 0, "BTC received in satoshis")
+		reference := fs.String("reference", "", "OTC desk trade ID")
+		at := fs.String("at", "", "fill time (RFC3339, default now)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *fiat <= 0 || *sats <= 0 || len(*currency) != 3 {
+			return errors.New("-fiat, -sats and a 3-letter -currency are required")
+		}
+
+		filledAt := time.Now().UTC()
+		if *at != "" {
+			if filledAt, err = time.Parse(time.RFC3339, *at); err != nil {
+				return fmt.Errorf("invalid -at: %w", err)
+			}
+		}
+
+		f := &database.OTCFill{
+			ID:              uuid.New().String(),
+			FiatCurrency:    strings.ToUpper(*currency),
+			FiatAmountCents: *fiat,
+			BTCAmountSats:   *sats,
+			Reference:       *reference,
+			FilledAt:        filledAt.UTC(),
+			CreatedAt:       time.Now().UTC(),
+		}
+		if err := fills.Create(ctx, f); err != nil {
+			return err
+		}
+		fmt.Printf("OTC fill %s recorded (%.2f %s/BTC)\n", f.ID, f.Price(), f.FiatCurrency)
+		return nil
+
+	case "report":
+		fs := flag.NewFlagSet("otc-fills report", flag.ContinueOnError)
+		weeks := fs.Int("weeks", 12, "report on the last N weeks")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		report, err := fills.WeeklyAccuracy(ctx, time.Now().UTC().AddDate(0, 0, -7**weeks))
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "WEEK\tCURRENCY\tCARDS\tFUNDING_PRICE\tFILLS\tOTC_PRICE\tDELTA_BPS")
+		for _, a := range report {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%d\t%.2f\t%+.1f\n",
+				a.Week.Format("2006-01-02"), a.FiatCurrency, a.CardsFunded, a.FundingPrice, a.Fills, a.OTCPrice, a.DeltaBps())
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown otc-fills subcommand %q", args[0])
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"btc-giftcard/config"
 	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/guardrail"
 	"btc-giftcard/pkg/cache"
 	"btc-giftcard/pkg/logger"
 	"context"
@@ -42,6 +43,19 @@ func run() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Apply [logging] (sinks, level, sampling) now that the config is loaded
+	var logCfg logger.Config
+	if err := copier.Copy(&logCfg, &Cfg.Logging); err != nil {
+		return fmt.Errorf("failed to copy logging config: %w", err)
+	}
+	if err := logger.InitWithConfig(logger.GetEnv(), logCfg); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	if err := guardrail.Validate(Cfg); err != nil {
+		return fmt.Errorf("refusing to start: %w", err)
+	}
+
 	logger.Info("Server starting", zap.Int("port", 8080))
 	logger.Debug("Debug mode enabled")
 	logger.Warn("This is a warning", zap.String("reason", "testing"))
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/doctor"
+	"btc-giftcard/internal/exchange"
+	"btc-giftcard/internal/lnd"
+	"btc-giftcard/internal/worker"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+	"btc-giftcard/pkg/queue"
+
+	"github.com/jinzhu/copier"
+)
+
+// ============================================================================
+// GIFTCARDCTL
+// ============================================================================
+//
+// Deployment tooling for operators:
+//
+//	go run ./cmd/giftcardctl doctor [-currency EUR] [-timeout 10s]
+//
+// doctor validates the configuration against everything it points to —
+// network guardrails, database connectivity and schema version, Redis
+// consumer groups, LND reachability, network, sync and macaroon permissions,
+// price providers — and prints
+// a report with a remedy for each problem. It exits non-zero if any check
+// fails, so it can gate a deployment.
+// ============================================================================
+
+// command is a giftcardctl subcommand. args excludes the subcommand name.
+type command struct {
+	usage string
+	run   func(ctx context.Context, cfg config.ApiConfig, root string, args []string) error
+}
+
+var commands = map[string]command{
+	"doctor": {
+		usage: "check configuration, database, Redis streams, LND and price providers",
+		run:   runDoctor,
+	},
+}
+
+// errChecksFailed makes main exit non-zero without repeating the report.
+var errChecksFailed = errors.New("some checks failed")
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		printUsage()
+		return errors.New("missing command")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	if err := logger.Init(logger.GetEnv()); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer logger.Sync()
+
+	// Resolve config path relative to this file (project root)
+	_, filename, _, _ := runtime.Caller(0)
+	root := filepath.Join(filepath.Dir(filename), "..", "..")
+	configPath := config.Path(root).Join("config.toml")
+
+	var cfg config.ApiConfig
+	if err := config.Load(configPath, &cfg); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return cmd.run(context.Background(), cfg, root, args[1:])
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: giftcardctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for name, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, cmd.usage)
+	}
+}
+
+func runDoctor(ctx context.Context, cfg config.ApiConfig, root string, args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	currency := fs.String("currency", "EUR", "fiat currency to fetch prices in")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout for each group of checks")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var report doctor.Report
+	doctor.CheckConfig(&report, cfg)
+
+	// Database
+	func() {
+		ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+
+		latest, err := database.LatestMigration(filepath.Join(root, "migrations"))
+		if err != nil {
+			report.Fail("database.schema", err.Error(), "run giftcardctl from a source checkout with the migrations/ directory")
+			return
+		}
+		var dbCfg database.Config
+		if err := copier.Copy(&dbCfg, &cfg.Database); err != nil {
+			report.Fail("database.connection", err.Error(), "check the [database] section")
+			return
+		}
+		db, err := database.NewDB(dbCfg)
+		if err != nil {
+			report.Fail("database.connection", err.Error(), "check [database] host, port and credentials, and that PostgreSQL is running")
+			return
+		}
+		defer db.Close()
+		doctor.CheckDatabase(ctx, &report, db, latest)
+	}()
+
+	// Redis streams
+	func() {
+		ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+
+		var redisCfg cache.Config
+		if err := copier.Copy(&redisCfg, &cfg.Redis); err != nil {
+			report.Fail("redis.connection", err.Error(), "check the [redis] section")
+			return
+		}
+		if err := cache.Init(redisCfg); err != nil {
+			report.Fail("redis.connection", err.Error(), "check [redis] host, port and password, and that Redis is running")
+			return
+		}
+		defer cache.Close()
+		report.OK("redis.connection", "reachable")
+		consumers := doctor.Consumers
+		if cfg.Analytics.Enabled {
+			consumers = append(consumers, doctor.AnalyticsConsumer)
+		}
+		doctor.CheckStreams(ctx, &report, queue.NewStreamQueue(cache.Client), consumers)
+	}()
+
+	// LND (config.lnd_* already failed if the cert or macaroon is missing)
+	func() {
+		ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+
+		client, err := lnd.NewClient(worker.LNDConfig(cfg))
+		if err != nil {
+			report.Fail("lnd.connection", err.Error(), "check [lnd] grpc_host, port, tls_cert_path and macaroon_path, and that the wallet is unlocked")
+			return
+		}
+		defer client.Close()
+		doctor.CheckLND(ctx, &report, client, cfg.LND.Network, lnd.RequiredRPCs)
+	}()
+
+	// Price providers
+	func() {
+		ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+
+		providers := map[string]exchange.PriceProvider{}
+		for _, name := range []string{"coinbase", "coingecko", "bitstamp"} {
+			provider, err := exchange.NewProvider(name, "", nil)
+			if err != nil {
+				report.Fail("price."+name, err.Error(), "")
+				continue
+			}
+			providers[name] = provider
+		}
+		doctor.CheckPrices(ctx, &report, providers, cfg.Quotes.Provider, *currency)
+	}()
+
+	fmt.Println()
+	if err := report.Write(os.Stdout); err != nil {
+		return err
+	}
+	if report.Failed() {
+		return errChecksFailed
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/database"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// TRANSACTION ARCHIVAL JOB
+// ============================================================================
+//
+// One-shot job (run from cron / a k8s CronJob, e.g. nightly) that moves old
+// transactions out of the hot `transactions` table into `transactions_archive`.
+//
+// A transaction is archived when ALL of:
+//   - created more than retention.archive_after_months ago
+//   - status is terminal (confirmed / failed)
+//   - its card is terminal (redeemed / expired)
+//
+// Rows are moved in batches of retention.archive_batch_size, each batch in a
+// single DELETE ... RETURNING / INSERT statement, so the job is safe to
+// interrupt and re-run. Archived history stays queryable via
+// TransactionRepository.ListByCardIDWithArchive.
+//
+// The job then redacts Lightning invoices stored in full (retention.
+// invoice_memo_policy = "keep") once they are older than
+// retention.invoice_retention_days, in both tables: their memos may hold
+// personal data, the hash left in place still matches the invoice.
+// ============================================================================
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if err := logger.Init(logger.GetEnv()); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer logger.Sync()
+
+	// Resolve config path relative to this file (project root)
+	_, filename, _, _ := runtime.Caller(0)
+	root := filepath.Dir(filename)
+	configPath := config.Path(root).Join("config.toml", "..", "..", "..")
+
+	var cfg config.ApiConfig
+	if err := config.Load(configPath, &cfg); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Apply [logging] (sinks, level, sampling) now that the config is loaded
+	var logCfg logger.Config
+	if err := copier.Copy(&logCfg, &cfg.Logging); err != nil {
+		return fmt.Errorf("failed to copy logging config: %w", err)
+	}
+	if err := logger.InitWithConfig(logger.GetEnv(), logCfg); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	if cfg.Retention.ArchiveAfterMonths <= 0 || cfg.Retention.ArchiveBatchSize <= 0 {
+		return fmt.Errorf("invalid retention config: archive_after_months=%d archive_batch_size=%d",
+			cfg.Retention.ArchiveAfterMonths, cfg.Retention.ArchiveBatchSize)
+	}
+
+	var dbCfg database.Config
+	if err := copier.Copy(&dbCfg, &cfg.Database); err != nil {
+		return fmt.Errorf("failed to copy database config: %w", err)
+	}
+	db, err := database.NewDB(dbCfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database connection: %w", err)
+	}
+	defer db.Close()
+
+	// Stop between batches on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cutoff := time.Now().UTC().AddDate(0, -cfg.Retention.ArchiveAfterMonths, 0)
+	logger.Info("Archiving transactions",
+		zap.Time("cutoff", cutoff),
+		zap.Int("batch_size", cfg.Retention.ArchiveBatchSize),
+	)
+
+	total, err := archive(ctx, database.NewTransactionRepository(db), cutoff, cfg.Retention.ArchiveBatchSize)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Transaction archival complete", zap.Int64("archived", total))
+
+	if cfg.Retention.InvoiceRetentionDays > 0 {
+		cutoff := time.Now().UTC().AddDate(0, 0, -cfg.Retention.InvoiceRetentionDays)
+		redacted, err := redact(ctx, database.NewTransactionRepository(db), cutoff, cfg.Retention.ArchiveBatchSize)
+		if err != nil {
+			return err
+		}
+		logger.Info("Invoice redaction complete", zap.Time("cutoff", cutoff), zap.Int64("redacted", redacted))
+	}
+	return nil
+}
+
+// archive moves batches until a batch comes back short (nothing left) or ctx is cancelled.
+func archive(ctx context.Context, txRepo *database.TransactionRepository, cutoff time.Time, batchSize int) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		moved, err := txRepo.ArchiveBefore(ctx, cutoff, batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += moved
+
+		logger.Debug("Archived batch", zap.Int64("moved", moved), zap.Int64("total", total))
+
+		if moved < int64(batchSize) {
+			break
+		}
+	}
+	return total, nil
+}
+
+// redact hashes stored invoices in batches until a batch comes back short or ctx is cancelled.
+func redact(ctx context.Context, txRepo *database.TransactionRepository, cutoff time.Time, batchSize int) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		redacted, err := txRepo.RedactInvoicesBefore(ctx, cutoff, batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += redacted
+
+		if redacted < int64(batchSize) {
+			break
+		}
+	}
+	return total, nil
+}
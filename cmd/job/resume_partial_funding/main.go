@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/database"
+	messages "btc-giftcard/internal/queue"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// RESUME PARTIAL FUNDING JOB
+// ============================================================================
+//
+// One-shot job (run from cron, or by hand after the treasury is replenished)
+// that re-queues cards left in PartiallyFunded status by the fund worker.
+//
+// For each such card (oldest first, up to funding.resume_batch_size) a
+// FundCardMessage is published to the "fund_card" stream. The fund worker
+// then reserves the next tranche under the treasury lock, so running this job
+// twice, or while the worker is busy, only produces no-op messages.
+// ============================================================================
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if err := logger.Init(logger.GetEnv()); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer logger.Sync()
+
+	// Resolve config path relative to this file (project root)
+	_, filename, _, _ := runtime.Caller(0)
+	root := filepath.Dir(filename)
+	configPath := config.Path(root).Join("config.toml", "..", "..", "..")
+
+	var cfg config.ApiConfig
+	if err := config.Load(configPath, &cfg); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Apply [logging] (sinks, level, sampling) now that the config is loaded
+	var logCfg logger.Config
+	if err := copier.Copy(&logCfg, &cfg.Logging); err != nil {
+		return fmt.Errorf("failed to copy logging config: %w", err)
+	}
+	if err := logger.InitWithConfig(logger.GetEnv(), logCfg); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	if cfg.Funding.ResumeBatchSize <= 0 {
+		return fmt.Errorf("invalid funding config: resume_batch_size=%d", cfg.Funding.ResumeBatchSize)
+	}
+
+	var dbCfg database.Config
+	if err := copier.Copy(&dbCfg, &cfg.Database); err != nil {
+		return fmt.Errorf("failed to copy database config: %w", err)
+	}
+	db, err := database.NewDB(dbCfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database connection: %w", err)
+	}
+	defer db.Close()
+
+	var redisCfg cache.Config
+	if err := copier.Copy(&redisCfg, &cfg.Redis); err != nil {
+		return fmt.Errorf("failed to copy cache config: %w", err)
+	}
+	if err := cache.Init(redisCfg); err != nil {
+		return fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	defer cache.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	queued, err := resume(ctx, database.NewCardRepository(db), streams.NewStreamQueue(cache.Client), cfg.Funding.ResumeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Partial funding resume complete", zap.Int("queued", queued))
+	return nil
+}
+
+// resume publishes a FundCardMessage for each partially funded card.
+func resume(ctx context.Context, cardRepo *database.CardRepository, queue *streams.StreamQueue, batchSize int) (int, error) {
+	cards, err := cardRepo.ListByStatus(ctx, database.PartiallyFunded, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list partially funded cards: %w", err)
+	}
+
+	queued := 0
+	for _, card := range cards {
+		if ctx.Err() != nil {
+			break
+		}
+
+		msg := messages.FundCardMessage{
+			CardID:          card.ID,
+			FiatAmountCents: card.FiatAmountCents,
+			FiatCurrency:    card.FiatCurrency,
+		}
+		msgJSON, err := msg.ToJSON()
+		if err != nil {
+			return queued, fmt.Errorf("failed to serialize FundCardMessage for card %s: %w", card.ID, err)
+		}
+		if _, err := queue.Publish(ctx, "fund_card", msgJSON); err != nil {
+			return queued, fmt.Errorf("failed to publish FundCardMessage for card %s: %w", card.ID, err)
+		}
+
+		logger.Debug("Re-queued partially funded card",
+			zap.String("card_id", card.ID),
+			zap.Int64("funded_sats", card.FundedSats),
+		)
+		queued++
+	}
+	return queued, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/analytics"
+	"btc-giftcard/internal/worker"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	// Resolve config path relative to this file (project root)
+	_, filename, _, _ := runtime.Caller(0)
+	root := filepath.Dir(filename)
+	configPath := config.Path(root).Join("config.toml", "..", "..", "..")
+
+	// ========================================================================
+	// ANALYTICS WORKER
+	// ========================================================================
+	//
+	// Forwards card lifecycle events (card_created, card_funded,
+	// card_first_redemption, card_fully_redeemed) from the "analytics_events"
+	// stream to the [analytics] sink: a JSON-lines file, Segment or Kafka.
+	//
+	// Events are validated against analytics.Registry again before they are
+	// forwarded; one that doesn't match its schema (e.g. published by an
+	// older or newer release) is logged and dropped, not retried. A sink
+	// error leaves the message pending for retry. Processed message IDs are
+	// recorded (Database dependency), and every event carries an ID sinks
+	// can deduplicate on.
+	// ========================================================================
+
+	var sink analytics.Sink
+	defer func() {
+		if sink != nil {
+			if err := sink.Close(); err != nil {
+				logger.Warn("Failed to close analytics sink", zap.Error(err))
+			}
+		}
+	}()
+
+	return worker.Run(context.Background(), worker.Options{
+		Name:         "analytics-worker",
+		Stream:       analytics.Stream,
+		Group:        "analytics_workers",
+		ConfigPath:   configPath,
+		Dependencies: []worker.Dependency{worker.Database},
+		Handler: func(deps *worker.Deps) (worker.HandlerFunc, error) {
+			var sinkCfg analytics.SinkConfig
+			if err := copier.Copy(&sinkCfg, &deps.Config.Analytics); err != nil {
+				return nil, fmt.Errorf("failed to copy analytics config: %w", err)
+			}
+			var err error
+			if sink, err = analytics.NewSink(sinkCfg); err != nil {
+				return nil, fmt.Errorf("failed to initialize analytics sink: %w", err)
+			}
+
+			return func(ctx context.Context, messageID string, data []byte) error {
+				return forward(ctx, sink, messageID, data)
+			}, nil
+		},
+	})
+}
+
+// forward validates one event and writes it to the sink.
+func forward(ctx context.Context, sink analytics.Sink, messageID string, data []byte) error {
+	e, err := analytics.FromJSON(data)
+	if err != nil {
+		logger.Error("Dropping analytics event that doesn't match its schema", zap.String("messageID", messageID), zap.Error(err))
+		return nil // Permanent failure, don't retry
+	}
+
+	if err := sink.Write(ctx, e); err != nil {
+		return fmt.Errorf("failed to forward %s event: %w", e.Name, err)
+	}
+	return nil
+}
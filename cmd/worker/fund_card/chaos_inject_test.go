@@ -0,0 +1,85 @@
+//go:build integration && chaos
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"btc-giftcard/internal/chaos"
+	"btc-giftcard/internal/database"
+	messages "btc-giftcard/internal/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A failure between the card update and the Fund transaction insert leaves the
+// balance unrecorded; the redelivered message records it without re-funding.
+func TestChaos_FundAfterCardUpdate_Heals(t *testing.T) {
+	h, db := setupChaosHandler(t, 10_000_000)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+	defer chaos.Configure("")
+
+	ctx := context.Background()
+	data := createChaosCard(t, h)
+	msg, err := messages.FromJSONFundCard(data)
+	require.NoError(t, err)
+
+	require.NoError(t, chaos.Configure("fund.after_card_update=1"))
+	err = h.processMessage(ctx, "1-0", data)
+	require.ErrorIs(t, err, chaos.ErrInjected)
+
+	card, err := h.cardRepo.GetByID(ctx, msg.CardID)
+	require.NoError(t, err)
+	assert.Equal(t, database.Active, card.Status)
+	assert.Equal(t, int64(200_000), card.BTCAmountSats)
+	recorded, err := h.txRepo.FundedSats(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Zero(t, recorded, "fault hit before the Fund transaction insert")
+
+	// Redelivery heals the ledger and doesn't fund twice
+	require.NoError(t, chaos.Configure(""))
+	require.NoError(t, h.processMessage(ctx, "1-0", data))
+	require.NoError(t, h.processMessage(ctx, "1-0", data))
+
+	card, err = h.cardRepo.GetByID(ctx, msg.CardID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200_000), card.BTCAmountSats)
+	recorded, err = h.txRepo.FundedSats(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200_000), recorded)
+	assertLedgerHealed(t, h)
+}
+
+// The same fault on a partial tranche is healed before the next tranche.
+func TestChaos_FundAfterCardUpdate_PartialTranche_Heals(t *testing.T) {
+	h, db := setupChaosHandler(t, 150_000)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+	defer chaos.Configure("")
+
+	ctx := context.Background()
+	data := createChaosCard(t, h)
+	msg, err := messages.FromJSONFundCard(data)
+	require.NoError(t, err)
+
+	require.NoError(t, chaos.Configure("fund.after_card_update=1"))
+	require.ErrorIs(t, h.processMessage(ctx, "1-0", data), chaos.ErrInjected)
+
+	// Treasury replenished, message redelivered (or re-queued by resume_partial_funding)
+	require.NoError(t, chaos.Configure(""))
+	h.treasury = &stubTreasury{availableSats: 10_000_000}
+	require.NoError(t, h.processMessage(ctx, "1-0", data))
+
+	card, err := h.cardRepo.GetByID(ctx, msg.CardID)
+	require.NoError(t, err)
+	assert.Equal(t, database.Active, card.Status)
+	assert.Equal(t, int64(200_000), card.BTCAmountSats)
+
+	txs, err := h.txRepo.ListByCardID(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Len(t, txs, 2, "healed first tranche + second tranche")
+	assertLedgerHealed(t, h)
+}
@@ -0,0 +1,105 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/database"
+	messages "btc-giftcard/internal/queue"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Initialize logger for tests
+	_ = logger.Init("development")
+}
+
+// stubTreasury is a treasuryReserver with a fixed available balance.
+type stubTreasury struct {
+	availableSats int64
+}
+
+func (s *stubTreasury) AcquireTreasuryLock(_ context.Context) (bool, error) { return true, nil }
+func (s *stubTreasury) ReleaseTreasuryLock(_ context.Context)               {}
+func (s *stubTreasury) InvalidateTreasuryCache(_ context.Context)           {}
+func (s *stubTreasury) GetTreasuryAvailableBalance(_ context.Context) (int64, error) {
+	return s.availableSats, nil
+}
+
+// stubPrice is an exchange.PriceProvider returning a constant BTC price.
+type stubPrice float64
+
+func (p stubPrice) GetPrice(_ context.Context, _ string) (float64, error) { return float64(p), nil }
+
+func setupChaosHandler(t *testing.T, availableSats int64) (*messageHandler, *database.DB) {
+	t.Helper()
+
+	db := database.SetupTestDB(t)
+	require.NoError(t, cache.Init(cache.Config{Host: "localhost", Port: "6379", DB: 1}))
+
+	cardRepo := database.NewCardRepository(db)
+	txRepo := database.NewTransactionRepository(db)
+	queue := streams.NewStreamQueue(cache.Client)
+
+	return newMessageHandler(cardRepo, txRepo, "coinbase", stubPrice(50_000), &stubTreasury{availableSats: availableSats}, queue, nil, 1), db
+}
+
+func createChaosCard(t *testing.T, h *messageHandler) []byte {
+	t.Helper()
+
+	card := &database.Card{
+		ID:                 uuid.New().String(),
+		PurchaseEmail:      "chaos@example.com",
+		OwnerEmail:         "chaos@example.com",
+		Code:               "GIFT-CHAO-" + uuid.New().String()[:9],
+		FiatAmountCents:    10_000, // €100 at €50,000/BTC = 200,000 sats
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 10_000,
+		Status:             database.Created,
+		CreatedAt:          time.Now().UTC(),
+	}
+	require.NoError(t, h.cardRepo.Create(context.Background(), card))
+
+	msg := messages.FundCardMessage{CardID: card.ID, FiatAmountCents: card.FiatAmountCents, FiatCurrency: card.FiatCurrency}
+	data, err := msg.ToJSON()
+	require.NoError(t, err)
+	return data
+}
+
+// assertLedgerHealed checks that every card balance matches its transactions.
+func assertLedgerHealed(t *testing.T, h *messageHandler) {
+	t.Helper()
+
+	discrepancies, err := h.txRepo.LedgerDiscrepancies(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, discrepancies)
+}
+
+// A card left in Funding (failure before its first tranche) is funded on redelivery.
+func TestChaos_StuckInFunding_Heals(t *testing.T) {
+	h, db := setupChaosHandler(t, 10_000_000)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	data := createChaosCard(t, h)
+	msg, err := messages.FromJSONFundCard(data)
+	require.NoError(t, err)
+	require.NoError(t, h.cardRepo.Update(ctx, msg.CardID, database.Funding, nil, nil, nil))
+
+	require.NoError(t, h.processMessage(ctx, "1-0", data))
+
+	card, err := h.cardRepo.GetByID(ctx, msg.CardID)
+	require.NoError(t, err)
+	assert.Equal(t, database.Active, card.Status)
+	assertLedgerHealed(t, h)
+}
@@ -2,28 +2,39 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"runtime"
-	"syscall"
 	"time"
 
 	"btc-giftcard/config"
+	"btc-giftcard/internal/analytics"
+	"btc-giftcard/internal/card"
+	"btc-giftcard/internal/chaos"
 	"btc-giftcard/internal/database"
 	"btc-giftcard/internal/exchange"
 	messages "btc-giftcard/internal/queue"
-	"btc-giftcard/pkg/cache"
+	"btc-giftcard/internal/treasury"
+	"btc-giftcard/internal/worker"
 	"btc-giftcard/pkg/logger"
 	streams "btc-giftcard/pkg/queue"
 
 	"github.com/google/uuid"
-	"github.com/jinzhu/copier"
 	"go.uber.org/zap"
 )
 
-var Cfg config.ApiConfig
+// Conversion metrics are published under "price_conversion" on /debug/vars;
+// the realized side (OTC fills) is in the admin conversion-report.
+var conversionMetrics = expvar.NewMap("price_conversion")
+
+const (
+	metricQuotesHonored    = "quotes_honored"
+	metricQuotesNotHonored = "quotes_not_honored"
+	metricFundingPrice     = "funding_price."   // + currency: last price a card was priced at
+	metricQuoteDriftBps    = "quote_drift_bps." // + currency: last quoted vs current price drift
+)
 
 func main() {
 	if err := run(); err != nil {
@@ -33,23 +44,11 @@ func main() {
 }
 
 func run() error {
-	// Initialize logger
-	if err := logger.Init("development"); err != nil {
-		return fmt.Errorf("failed to initialize logger: %w", err)
-	}
-	defer logger.Sync()
-
-	// Load configuration
+	// Resolve config path relative to this file (project root)
 	_, filename, _, _ := runtime.Caller(0)
 	root := filepath.Dir(filename)
 	configPath := config.Path(root).Join("config.toml", "..", "..", "..")
 
-	if err := config.Load(configPath, &Cfg); err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
-	logger.Info("Starting fund_card worker...")
-
 	// ========================================================================
 	// CUSTODIAL FUNDING MODEL
 	// ========================================================================
@@ -68,141 +67,95 @@ func run() error {
 	//      → Calculate satoshis for the card's fiat value
 	//      → Check treasury has enough available balance (prevent overselling)
 	//      → Reserve balance: Update card BTCAmountSats + Status=Active
+	//        (or PartiallyFunded if treasury only covers part of it)
 	//      → Create Fund transaction record (accounting only, no tx_hash)
 	//   4. Card is now active and spendable by the user
 	//
 	// ⚠️  NO on-chain tx, NO wallet generation, NO private keys
 	// ⚠️  BTC only moves when user REDEEMS (Lightning or on-chain)
-	// ========================================================================
-
-	// Initialize Redis
-	var redisCfg cache.Config
-	if err := copier.Copy(&redisCfg, &Cfg.Redis); err != nil {
-		return fmt.Errorf("failed to copy cache config: %w", err)
-	}
-	if err := cache.Init(redisCfg); err != nil {
-		return fmt.Errorf("failed to initialize cache: %w", err)
-	}
-	defer cache.Close()
-
-	// Initialize database
-	var dbCfg database.Config
-	if err := copier.Copy(&dbCfg, &Cfg.Database); err != nil {
-		return fmt.Errorf("failed to copy database config: %w", err)
-	}
-	db, err := database.NewDB(dbCfg)
-	if err != nil {
-		return fmt.Errorf("failed to initialize database connection: %w", err)
-	}
-	defer db.Close()
-
-	// Create repositories
-	cardRepo := database.NewCardRepository(db)
-	txRepo := database.NewTransactionRepository(db)
-
-	// Create OTC price provider
-	// TODO: Switch to "cryptocom_otc" provider once implemented
-	// This reflects our actual BTC cost basis (not a random public exchange)
-	// Fallback chain: OTC provider → Coinbase → CoinGecko
-	provider, err := exchange.NewProvider("coinbase", "", nil)
-	if err != nil {
-		return fmt.Errorf("failed to initialize exchange provider: %w", err)
-	}
-
-	// TODO: Load treasury config
-	//    - treasuryTotalSats: total BTC held (Lightning channels + hot wallet)
-	//    - Available = treasuryTotalSats - SUM(unredeemed card balances)
-	//    - Replace with treasury service that queries LND + hot wallet in real-time
-	//
-	// IMPLEMENT: Initialize LND client from config
-	//   var lndCfg lnd.Config
-	//   lndCfg.GRPCHost     = Cfg.LND.GRPCHost          // "gift-card-backend.lnd:10009"
-	//   lndCfg.TLSCertPath  = Cfg.LND.TLSCertPath       // "./lnd-data/tls.cert"
-	//   lndCfg.MacaroonPath = Cfg.LND.MacaroonPath       // "./lnd-data/admin.macaroon"
-	//   lndCfg.Network      = Cfg.LND.Network            // "testnet"
 	//
-	//   lndClient, err := lnd.NewClient(lndCfg)
-	//   if err != nil {
-	//       return fmt.Errorf("failed to connect to LND: %w", err)
-	//   }
-	//   defer lndClient.Close()
-	//
-	//   // Verify LND is synced at startup
-	//   info, err := lndClient.GetInfo(ctx)
-	//   logger.Info("Connected to LND",
-	//       zap.String("alias", info.Alias),
-	//       zap.Bool("synced", info.SyncedToChain),
-	//       zap.Uint32("block_height", info.BlockHeight),
-	//   )
-	//
-	// Then pass lndClient to newMessageHandler() so processMessage can check treasury balance.
-
-	// Setup queue consumer
-	queue := streams.NewStreamQueue(cache.Client)
-	streamName := "fund_card"
-	groupName := "fund_workers"
-	consumerName := fmt.Sprintf("fund-worker-%d", time.Now().Unix())
-
-	// Graceful shutdown context
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	if err := queue.DeclareStream(ctx, streamName, groupName); err != nil {
-		return fmt.Errorf("failed to declare the consumer group: %w", err)
-	}
-
-	// Start consumer goroutine
-	handler := newMessageHandler(cardRepo, txRepo, provider)
+	// Config, logger, Redis/DB wiring, idempotency, dead-lettering, health
+	// endpoint and graceful shutdown are handled by internal/worker.
+	// ========================================================================
 
-	go func() {
-		err := queue.Consume(ctx, streamName, groupName, consumerName,
-			func(messageID string, data []byte) error {
-				return handler.processMessage(ctx, messageID, data)
+	return worker.Run(context.Background(), worker.Options{
+		Name:         "fund-worker",
+		Stream:       "fund_card",
+		Group:        "fund_workers",
+		ConfigPath:   configPath,
+		Dependencies: []worker.Dependency{worker.Database, worker.LND},
+		Handler: func(deps *worker.Deps) (worker.HandlerFunc, error) {
+			// Create OTC price provider
+			// TODO: Switch to "cryptocom_otc" provider once implemented
+			// This reflects our actual BTC cost basis (not a random public exchange)
+			// Fallback chain: OTC provider → Coinbase → CoinGecko
+			providerName := deps.Config.Quotes.Provider
+			provider, err := exchange.NewProvider(providerName, "", nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize exchange provider: %w", err)
+			}
+
+			var events *analytics.Publisher
+			if deps.Config.Analytics.Enabled {
+				events = analytics.NewPublisher(deps.Queue)
+			}
+
+			// card.Service owns the treasury lock and available-balance cache
+			custodian := treasury.NewLNDCustodian(deps.LND, deps.Config.LND.MaxPaymentFeeSats)
+			cardService := card.NewService(card.Config{}, card.Deps{
+				Cards:        deps.CardRepo,
+				Transactions: deps.TxRepo,
+				Queue:        deps.Queue,
+				Custodian:    custodian,
+				Flags:        deps.Flags,
+				Analytics:    events,
 			})
-		if err != nil && err != context.Canceled {
-			logger.Error("Consumer error", zap.Error(err))
-		}
-	}()
-
-	logger.Info("Fund card worker is running, waiting for messages...",
-		zap.String("stream", streamName),
-		zap.String("group", groupName),
-		zap.String("consumer", consumerName),
-	)
-
-	// Wait for shutdown signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	sig := <-sigChan
-	logger.Info("Received shutdown signal", zap.String("signal", sig.String()))
-
-	// Cancel context to stop consumer
-	cancel()
-
-	// Give the consumer time to finish processing current message
-	time.Sleep(3 * time.Second)
-	logger.Info("Fund card worker shut down gracefully")
+			handler := newMessageHandler(deps.CardRepo, deps.TxRepo, providerName, provider, cardService, deps.Queue, events, deps.Config.Funding.MinTrancheSats)
+			return handler.processMessage, nil
+		},
+	})
+}
 
-	return nil
+// treasuryReserver guards treasury reservations (implemented by card.Service).
+type treasuryReserver interface {
+	AcquireTreasuryLock(ctx context.Context) (bool, error)
+	ReleaseTreasuryLock(ctx context.Context)
+	GetTreasuryAvailableBalance(ctx context.Context) (int64, error)
+	InvalidateTreasuryCache(ctx context.Context)
 }
 
 // messageHandler holds the dependencies needed by processMessage.
 type messageHandler struct {
-	cardRepo *database.CardRepository
-	txRepo   *database.TransactionRepository
-	provider exchange.PriceProvider
+	cardRepo       *database.CardRepository
+	txRepo         *database.TransactionRepository
+	providerName   string // Checkout quotes are only honored if priced by the same provider
+	provider       exchange.PriceProvider
+	treasury       treasuryReserver
+	queue          *streams.StreamQueue
+	analytics      *analytics.Publisher // nil disables analytics events
+	minTrancheSats int64                // Smallest partial tranche worth reserving
 }
 
 func newMessageHandler(
 	cardRepo *database.CardRepository,
 	txRepo *database.TransactionRepository,
+	providerName string,
 	provider exchange.PriceProvider,
+	treasury treasuryReserver,
+	queue *streams.StreamQueue,
+	events *analytics.Publisher,
+	minTrancheSats int64,
 ) *messageHandler {
 	return &messageHandler{
-		cardRepo: cardRepo,
-		txRepo:   txRepo,
-		provider: provider,
+		cardRepo:       cardRepo,
+		txRepo:         txRepo,
+		providerName:   providerName,
+		provider:       provider,
+		treasury:       treasury,
+		queue:          queue,
+		analytics:      events,
+		minTrancheSats: minTrancheSats,
 	}
 }
 
@@ -216,11 +169,17 @@ func newMessageHandler(
 //  3. FundCardMessage published to "fund_card" queue
 //  4. THIS WORKER processes message:
 //     → Fetch BTC price from OTC provider (our cost basis)
+//     → Honor the checkout rate quote instead, if valid and within its guard
 //     → Calculate satoshis (e.g., €95 after fee / €67,000 = 141,791 sats)
-//     → Check treasury available balance ≥ satoshis needed
-//     → Update card: BTCAmountSats=141791, Status=Active, FundedAt=now
-//     → Create Transaction record (Type=Fund, no tx_hash — just accounting)
-//  5. Card is now active — user can spend (Lightning or on-chain)
+//     → Under the treasury lock, reserve min(needed, treasury available)
+//     → Fully covered: card Status=Active, FundedAt=now
+//     → Partially covered: card Status=PartiallyFunded (not spendable yet)
+//     → Create Transaction record per tranche (Type=Fund, no tx_hash)
+//     → Notify purchaser (FundingProgressMessage on "card_notifications")
+//     → Fully funded: card_funded analytics event (if [analytics] enabled)
+//  5. Partially funded cards are re-queued by cmd/job/resume_partial_funding
+//     once treasury is replenished; the card keeps the sats target priced
+//     at its first tranche, so later tranches don't re-price.
 //
 // ⚠️  No MonitorTransactionMessage needed — no on-chain tx to monitor
 // ========================================================================
@@ -239,93 +198,299 @@ func (h *messageHandler) processMessage(ctx context.Context, messageID string, d
 	if err != nil {
 		return fmt.Errorf("error fetching card: %w", err)
 	}
-	if card.Status != database.Created {
+
+	var targetSats int64
+	switch card.Status {
+	case database.Created, database.Funding:
+		// Set card status to Funding. A card already in Funding is a redelivery
+		// after a failure before its first tranche was reserved: price it again.
+		if card.Status == database.Created {
+			err = h.cardRepo.Update(ctx, card.ID, database.Funding, nil, nil, nil)
+			if err != nil {
+				return fmt.Errorf("failed to set funding status: %w", err)
+			}
+		}
+
+		var price float64
+		targetSats, price, err = h.priceCard(ctx, msg)
+		if err != nil {
+			return err
+		}
+		if targetSats <= 0 {
+			logger.Error("Calculated 0 sats — price too high or amount too low")
+			return nil // Permanent failure, don't retry
+		}
+		// For the conversion accuracy report; a redelivery overwrites it with
+		// the price the card is actually funded at
+		if err := h.cardRepo.SetFundingPrice(ctx, card.ID, price); err != nil {
+			return err
+		}
+
+	case database.PartiallyFunded:
+		// Resume: keep the target priced at the first tranche
+		if card.FundingTargetSats == nil {
+			logger.Error("Partially funded card has no funding target", zap.String("card_id", card.ID))
+			return nil // Permanent failure, needs manual fix
+		}
+		targetSats = *card.FundingTargetSats
+
+	case database.Active:
+		// Redelivery after the final tranche: its Fund transaction may be missing
+		if err := h.reconcileFunding(ctx, card.ID); err != nil {
+			return err
+		}
+		logger.Warn("Card already funded, skipping", zap.String("card_id", card.ID))
+		return nil
+
+	default:
 		logger.Warn("Card already processed, skipping", zap.String("card_id", card.ID), zap.String("status", string(card.Status)))
 		return nil // Idempotent: skip already-funded cards
 	}
 
-	// Set card status to Funding (prevents duplicate processing)
-	err = h.cardRepo.Update(ctx, card.ID, database.Funding, nil, nil, nil)
-	if err != nil {
-		return fmt.Errorf("failed to set funding status: %w", err)
+	if err := h.fundTranche(ctx, card, targetSats); err != nil {
+		return err
 	}
 
+	logger.Info("Message processed successfully", zap.String("messageID", messageID))
+	return nil
+}
+
+// priceCard converts the card's fiat value to satoshis at the OTC price, or at
+// the checkout quote's locked price if the quote is still honored. It also
+// returns the price used.
+func (h *messageHandler) priceCard(ctx context.Context, msg *messages.FundCardMessage) (int64, float64, error) {
 	// Fetch BTC price from OTC provider (TODO check if it's better to fetch crypto.com price)
 	price, err := h.provider.GetPrice(ctx, msg.FiatCurrency)
 	if err != nil {
-		return fmt.Errorf("error fetching BTC price: %w", err)
+		return 0, 0, fmt.Errorf("error fetching BTC price: %w", err)
 	}
 	logger.Info("BTC price from OTC provider", zap.Float64("price", price), zap.String("currency", msg.FiatCurrency))
 
+	if msg.QuoteID != "" {
+		price = h.quotedPrice(ctx, msg, price)
+	}
+
+	funding := new(expvar.Float)
+	funding.Set(price)
+	conversionMetrics.Set(metricFundingPrice+msg.FiatCurrency, funding)
+
 	// Calculate BTC amount in satoshis
-	fiatAmount := float64(msg.FiatAmountCents) / 100.0
-	btcAmount := fiatAmount / price
-	satoshis := int64(btcAmount * 100_000_000)
-	if satoshis <= 0 {
-		logger.Error("Calculated 0 sats — price too high or amount too low")
-		return nil // Permanent failure, don't retry
+	return card.SatsForFiat(msg.FiatAmountCents, price), price, nil
+}
+
+// quotedPrice returns the checkout quote's locked price if it's still valid,
+// was bound to this card by CreateCard, and the market hasn't drifted past its
+// guard; otherwise it falls back to currentPrice.
+func (h *messageHandler) quotedPrice(ctx context.Context, msg *messages.FundCardMessage, currentPrice float64) float64 {
+	quote, err := card.GetQuote(ctx, msg.QuoteID)
+	if err != nil {
+		conversionMetrics.Add(metricQuotesNotHonored, 1)
+		logger.Warn("Rate quote unavailable, using current price", zap.String("quote_id", msg.QuoteID), zap.Error(err))
+		return currentPrice
 	}
 
-	// Check treasury has enough available balance
-	// IMPLEMENT using LND client (passed via messageHandler):
-	//
-	//   1. Get Lightning channel balance:
-	//      channelBal, err := h.lndClient.GetChannelBalance(ctx)
-	//      lightningAvailable := channelBal.LocalSats
-	//
-	//   2. Get on-chain wallet balance:
-	//      walletBal, err := h.lndClient.GetWalletBalance(ctx)
-	//      onChainAvailable := walletBal.ConfirmedSats
-	//
-	//   3. Calculate total treasury:
-	//      totalTreasury := lightningAvailable + onChainAvailable
-	//
-	//   4. Query total reserved balance (sum of active + funding cards):
-	//      SELECT COALESCE(SUM(btc_amount_sats), 0) FROM cards WHERE status IN ('active','funding')
-	//      → totalReserved
-	//      (TODO: add a GetTotalReservedBalance method to CardRepository)
-	//
-	//   5. available := totalTreasury - totalReserved
-	//      if available < satoshis {
-	//          logger.Error("Treasury insufficient",
-	//              zap.Int64("needed", satoshis),
-	//              zap.Int64("available", available),
-	//          )
-	//          // Revert card to Created so it can be retried later
-	//          h.cardRepo.Update(ctx, card.ID, database.Created, nil, nil, nil)
-	//          return fmt.Errorf("treasury insufficient: need %d sats, have %d available", satoshis, available)
-	//      }
-	//
-	//   CONCURRENCY: Use Redis distributed lock to prevent race conditions
-	//      lockKey := "treasury:reserve_lock"
-	//      acquired, err := cache.Client.SetNX(ctx, lockKey, consumerID, 5*time.Second).Result()
-	//      if !acquired { return retry }
-	//      defer cache.Client.Del(ctx, lockKey)
-	//      // ... check balance and reserve inside the lock ...
+	if quote.CardID != msg.CardID {
+		conversionMetrics.Add(metricQuotesNotHonored, 1)
+		logger.Warn("Rate quote bound to another card, using current price",
+			zap.String("quote_id", quote.ID),
+			zap.String("card_id", msg.CardID),
+			zap.String("quote_card_id", quote.CardID),
+		)
+		return currentPrice
+	}
+	if currentPrice > 0 {
+		drift := new(expvar.Float)
+		drift.Set((quote.Price - currentPrice) / currentPrice * 10_000)
+		conversionMetrics.Set(metricQuoteDriftBps+msg.FiatCurrency, drift)
+	}
+
+	if !quote.Matches(msg.FiatAmountCents, msg.FiatCurrency) || !quote.Honors(h.providerName, currentPrice, time.Now()) {
+		conversionMetrics.Add(metricQuotesNotHonored, 1)
+		logger.Warn("Rate quote not honored, using current price",
+			zap.String("quote_id", quote.ID),
+			zap.Float64("quoted_price", quote.Price),
+			zap.Float64("current_price", currentPrice),
+			zap.Int64("guard_bps", quote.GuardBps),
+		)
+		return currentPrice
+	}
+
+	conversionMetrics.Add(metricQuotesHonored, 1)
+	logger.Info("Honoring rate quote", zap.String("quote_id", quote.ID), zap.Float64("quoted_price", quote.Price))
+	return quote.Price
+}
+
+// fundTranche reserves as much of the remaining target as treasury allows and
+// records it. Runs under the treasury lock so concurrent workers can't reserve
+// the same available balance twice.
+func (h *messageHandler) fundTranche(ctx context.Context, card *database.Card, targetSats int64) error {
+	if _, err := h.treasury.AcquireTreasuryLock(ctx); err != nil {
+		return err // Lock busy or Redis error — message is retried
+	}
+	defer h.treasury.ReleaseTreasuryLock(ctx)
+
+	// Re-read under the lock: the card may have been funded since it was fetched,
+	// and a previous attempt may have reserved a tranche without recording it
+	card, err := h.cardRepo.GetByID(ctx, card.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching card: %w", err)
+	}
+	if err := h.recordMissingFunding(ctx, card); err != nil {
+		return err
+	}
+	if card.Status != database.Funding && card.Status != database.PartiallyFunded {
+		return nil // Funded by a concurrent delivery
+	}
+
+	available, err := h.treasury.GetTreasuryAvailableBalance(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get treasury balance: %w", err)
+	}
+
+	tranche := planTranche(targetSats-card.FundedSats, available, h.minTrancheSats)
+	funded := card.FundedSats + tranche
 
 	// Update card — reserve the balance (this IS the funding)
-	now := time.Now().UTC()
-	if err := h.cardRepo.Update(ctx, card.ID, database.Active, &satoshis, &now, nil); err != nil {
-		return fmt.Errorf("failed to activate card: %w", err)
+	status := database.PartiallyFunded
+	var fundedAt *time.Time
+	if funded == targetSats {
+		status = database.Active
+		now := time.Now().UTC()
+		fundedAt = &now
+	}
+	if err := h.cardRepo.UpdateFunding(ctx, card.ID, status, funded, &targetSats, fundedAt); err != nil {
+		return fmt.Errorf("failed to update card funding: %w", err)
+	}
+	h.treasury.InvalidateTreasuryCache(ctx)
+
+	logger.Info("Card funding tranche reserved",
+		zap.String("card_id", card.ID),
+		zap.Int64("tranche_sats", tranche),
+		zap.Int64("funded_sats", funded),
+		zap.Int64("target_sats", targetSats),
+		zap.Int64("treasury_available_sats", available),
+		zap.String("status", string(status)),
+	)
+
+	if tranche == 0 && card.Status == database.PartiallyFunded {
+		return nil // Nothing changed — don't notify again
+	}
+
+	// Create Fund transaction record (accounting only — no blockchain tx).
+	// If this fails the message is retried and recordMissingFunding records it.
+	if tranche > 0 {
+		if err := chaos.Fail(chaos.FundAfterCardUpdate); err != nil {
+			return err
+		}
+		if err := h.createFundTransaction(ctx, card.ID, tranche); err != nil {
+			return err
+		}
+	}
+
+	h.notifyFundingProgress(ctx, card, funded, targetSats)
+	if status == database.Active {
+		h.analytics.Emit(ctx, analytics.NewEvent(analytics.CardFunded, card.ID, map[string]any{
+			"fiat_amount_cents": card.FiatAmountCents,
+			"fiat_currency":     card.FiatCurrency,
+			"funded_sats":       funded,
+			"seconds_to_fund":   int64(fundedAt.Sub(card.CreatedAt).Seconds()),
+		}))
+	}
+	return nil
+}
+
+// reconcileFunding records a funded card's missing Fund transaction under the
+// treasury lock (see recordMissingFunding).
+func (h *messageHandler) reconcileFunding(ctx context.Context, cardID string) error {
+	if _, err := h.treasury.AcquireTreasuryLock(ctx); err != nil {
+		return err
+	}
+	defer h.treasury.ReleaseTreasuryLock(ctx)
+
+	card, err := h.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		return fmt.Errorf("error fetching card: %w", err)
+	}
+	return h.recordMissingFunding(ctx, card)
+}
+
+// recordMissingFunding heals a tranche that was reserved on the card but whose
+// Fund transaction was never recorded (the worker failed between the two
+// writes): the difference between funded_sats and the recorded Fund
+// transactions is recorded as one Fund transaction. Must run under the
+// treasury lock, which every tranche reservation holds.
+func (h *messageHandler) recordMissingFunding(ctx context.Context, card *database.Card) error {
+	recorded, err := h.txRepo.FundedSats(ctx, card.ID)
+	if err != nil {
+		return err
+	}
+
+	missing := card.FundedSats - recorded
+	if missing <= 0 {
+		return nil
 	}
-	logger.Info("Card funded (balance reserved)", zap.String("card_id", card.ID), zap.Int64("satoshis", satoshis))
 
-	// Step 8: Create Fund transaction record (accounting only — no blockchain tx)
-	now = time.Now().UTC()
+	logger.Warn("Recording missing fund transaction",
+		zap.String("card_id", card.ID),
+		zap.Int64("funded_sats", card.FundedSats),
+		zap.Int64("recorded_sats", recorded),
+	)
+	return h.createFundTransaction(ctx, card.ID, missing)
+}
+
+// createFundTransaction records a funding tranche in the ledger.
+func (h *messageHandler) createFundTransaction(ctx context.Context, cardID string, amountSats int64) error {
+	now := time.Now().UTC()
 	tx := &database.Transaction{
 		ID:            uuid.New().String(),
-		CardID:        card.ID,
+		CardID:        cardID,
 		Type:          database.Fund,
-		BTCAmountSats: satoshis,
+		BTCAmountSats: amountSats,
 		Status:        database.Confirmed,
 		Confirmations: 0,
 		CreatedAt:     now,
 		ConfirmedAt:   &now,
 	}
 	if err := h.txRepo.Create(ctx, tx); err != nil {
-		logger.Error("Failed to create fund transaction", zap.Error(err))
+		return fmt.Errorf("failed to create fund transaction: %w", err)
 	}
-
-	logger.Info("Message processed successfully", zap.String("messageID", messageID))
 	return nil
 }
+
+// planTranche returns how many of neededSats to reserve now given the
+// treasury's available balance. Partial tranches smaller than minTrancheSats
+// are skipped (0) so large cards aren't funded in dust-sized steps.
+func planTranche(neededSats, availableSats, minTrancheSats int64) int64 {
+	if neededSats <= 0 || availableSats <= 0 {
+		return 0
+	}
+	if availableSats >= neededSats {
+		return neededSats
+	}
+	if availableSats < minTrancheSats {
+		return 0
+	}
+	return availableSats
+}
+
+// notifyFundingProgress publishes a FundingProgressMessage for the purchaser
+// email worker (best-effort, funding already succeeded).
+func (h *messageHandler) notifyFundingProgress(ctx context.Context, card *database.Card, fundedSats, targetSats int64) {
+	msg := messages.FundingProgressMessage{
+		CardID:        card.ID,
+		PurchaseEmail: card.PurchaseEmail,
+		FundedSats:    fundedSats,
+		TargetSats:    targetSats,
+		Complete:      fundedSats == targetSats,
+	}
+
+	msgJSON, err := msg.ToJSON()
+	if err != nil {
+		logger.Error("Failed to serialize FundingProgressMessage", zap.String("card_id", card.ID), zap.Error(err))
+		return
+	}
+
+	if _, err := h.queue.Publish(ctx, "card_notifications", msgJSON); err != nil {
+		logger.Error("Failed to publish FundingProgressMessage", zap.String("card_id", card.ID), zap.Error(err))
+	}
+}
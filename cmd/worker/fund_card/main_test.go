@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanTranche(t *testing.T) {
+	tests := []struct {
+		name      string
+		needed    int64
+		available int64
+		min       int64
+		want      int64
+	}{
+		{"fully covered", 500_000, 1_000_000, 100_000, 500_000},
+		{"exactly covered", 500_000, 500_000, 100_000, 500_000},
+		{"partial tranche", 5_000_000, 2_000_000, 100_000, 2_000_000},
+		{"below minimum tranche", 5_000_000, 50_000, 100_000, 0},
+		{"small remainder ignores minimum", 50_000, 60_000, 100_000, 50_000},
+		{"empty treasury", 5_000_000, 0, 100_000, 0},
+		{"negative available", 5_000_000, -10, 100_000, 0},
+		{"nothing needed", 0, 1_000_000, 100_000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, planTranche(tt.needed, tt.available, tt.min))
+		})
+	}
+}
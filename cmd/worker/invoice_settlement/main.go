@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/deposit"
+	"btc-giftcard/internal/lnd"
+	"btc-giftcard/internal/worker"
+	"btc-giftcard/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	// Resolve config path relative to this file (project root)
+	_, filename, _, _ := runtime.Caller(0)
+	root := filepath.Dir(filename)
+	configPath := config.Path(root).Join("config.toml", "..", "..", "..")
+
+	// ========================================================================
+	// INVOICE SETTLEMENT WORKER
+	// ========================================================================
+	//
+	// Subscribes to LND invoice updates (lnrpc.SubscribeInvoices) and, for
+	// every settled invoice, credits the matching deposit via
+	// deposit.Service.HandleSettled:
+	//
+	//   1. Match by payment hash (invoices issued by deposit.CreateInvoice)
+	//   2. Fall back to the "deposit:<id>" correlation ID in the memo
+	//   3. No match → recorded anyway and credited to the house treasury
+	//
+	// The subscription resumes from the highest settle index recorded in
+	// `deposits`, so settlements that happen while the worker is down are
+	// replayed by LND on reconnect. A replay never credits twice: settling
+	// records the invoice's payment hash on the deposit (unique in
+	// `deposits`), the deposit only moves from pending to settled once, and
+	// unmatched invoices are inserted ON CONFLICT (payment_hash) DO NOTHING.
+	//
+	// Run a single instance: every subscriber receives every settlement, so
+	// a second instance only does redundant work (the same guarantees keep
+	// it from crediting twice).
+	// ========================================================================
+
+	return worker.Run(context.Background(), worker.Options{
+		Name:         "invoice-settlement-worker",
+		ConfigPath:   configPath,
+		Dependencies: []worker.Dependency{worker.Database, worker.LND},
+		Loop: func(deps *worker.Deps) (worker.LoopFunc, error) {
+			svc := deposit.NewService(deps.DepositRepo, deps.LND, time.Duration(deps.Config.Deposits.InvoiceExpirySeconds)*time.Second)
+			reconnectDelay := time.Duration(deps.Config.Deposits.ReconnectDelaySeconds) * time.Second
+
+			return func(ctx context.Context) error {
+				return subscribe(ctx, deps.DepositRepo, deps.LND, svc, reconnectDelay)
+			}, nil
+		},
+	})
+}
+
+// subscribe keeps an invoice subscription open until ctx is cancelled,
+// resubscribing from the last recorded settle index whenever it drops.
+func subscribe(ctx context.Context, repo *database.DepositRepository, client lnd.LightningClient, svc *deposit.Service, reconnectDelay time.Duration) error {
+	for {
+		settleIndex, err := repo.LastSettleIndex(ctx)
+		if err == nil {
+			logger.Info("Subscribing to settled invoices", zap.Uint64("settle_index", settleIndex))
+			err = client.SubscribeSettledInvoices(ctx, settleIndex, func(inv *lnd.SettledInvoice) error {
+				return svc.HandleSettled(ctx, inv)
+			})
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		logger.Error("Invoice subscription stopped, reconnecting",
+			zap.Error(err),
+			zap.Duration("delay", reconnectDelay),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
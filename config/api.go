@@ -21,6 +21,29 @@ type ApiConfig struct {
 		DB       int    `toml:"db" env:"BTC_GIFTCARD_REDIS_DB" env-default:"0"`
 	} `toml:"redis"`
 
+	// Logging sinks and sampling (pkg/logger). ENVIRONMENT picks the defaults.
+	Logging struct {
+		// Encoding is "json" or "console" (empty = json in production, console otherwise)
+		Encoding string `toml:"encoding" env:"BTC_GIFTCARD_LOG_ENCODING"`
+
+		// Level is the initial level (empty = info in production, debug otherwise).
+		// Workers can change it at runtime: PUT {"level":"debug"} to /debug/loglevel on [worker] debug_addr.
+		Level string `toml:"level" env:"BTC_GIFTCARD_LOG_LEVEL"`
+
+		// File additionally appends logs to this path (on-prem deployments)
+		File string `toml:"file" env:"BTC_GIFTCARD_LOG_FILE"`
+
+		// Syslog additionally sends logs to syslog: "local", "udp://host:514" or "tcp://host:514"
+		Syslog    string `toml:"syslog" env:"BTC_GIFTCARD_LOG_SYSLOG"`
+		SyslogTag string `toml:"syslog_tag" env:"BTC_GIFTCARD_LOG_SYSLOG_TAG" env-default:"btc-giftcard"`
+
+		// Sampling of debug/info logs per message and second: the first SampleInitial
+		// are kept, then every SampleThereafter-th (0 = no sampling). Warnings and
+		// errors are never sampled. Meant for busy workers that log every message.
+		SampleInitial    int `toml:"sample_initial" env:"BTC_GIFTCARD_LOG_SAMPLE_INITIAL" env-default:"0"`
+		SampleThereafter int `toml:"sample_thereafter" env:"BTC_GIFTCARD_LOG_SAMPLE_THEREAFTER" env-default:"100"`
+	} `toml:"logging"`
+
 	// LND gRPC connection configuration
 	// Used by both the API (for redemptions) and workers (for treasury balance checks)
 	LND struct {
@@ -49,5 +72,231 @@ type ApiConfig struct {
 		// MaxPaymentFeeSats is the maximum fee (in sats) we're willing to pay for routing a Lightning payment
 		// Set to 0 for no limit (not recommended)
 		MaxPaymentFeeSats int64 `toml:"max_payment_fee_sats" env:"BTC_GIFTCARD_LND_MAX_FEE_SATS" env-default:"100"`
+
+		// MaxParts is the maximum number of HTLC shards for a multi-path payment
+		// Large redemptions often need several shards to find a route (1 disables MPP)
+		MaxParts uint32 `toml:"max_parts" env:"BTC_GIFTCARD_LND_MAX_PARTS" env-default:"16"`
+
+		// MaxShardSizeSats caps the size of a single shard (0 = no limit)
+		MaxShardSizeSats int64 `toml:"max_shard_size_sats" env:"BTC_GIFTCARD_LND_MAX_SHARD_SIZE_SATS" env-default:"0"`
+
+		// AllowAMP enables paying AMP (atomic multi-path) invoices
+		AllowAMP bool `toml:"allow_amp" env:"BTC_GIFTCARD_LND_ALLOW_AMP" env-default:"true"`
+
+		// CltvLimit is the maximum total timelock (in blocks) a route may lock funds for
+		// (0 = LND default). The final CLTV delta always comes from the invoice.
+		CltvLimit int32 `toml:"cltv_limit" env:"BTC_GIFTCARD_LND_CLTV_LIMIT" env-default:"0"`
+
+		// ProbeTimeoutSeconds bounds a pre-flight route probe (EstimateRouteFee)
+		ProbeTimeoutSeconds uint32 `toml:"probe_timeout_seconds" env:"BTC_GIFTCARD_LND_PROBE_TIMEOUT" env-default:"10"`
 	} `toml:"lnd"`
+
+	// Startup guardrails (internal/guardrail), checked by the API and every worker
+	Guardrails struct {
+		// AllowMainnet must be set explicitly for [lnd] network = "mainnet"
+		AllowMainnet bool `toml:"allow_mainnet" env:"BTC_GIFTCARD_GUARDRAILS_ALLOW_MAINNET" env-default:"false"`
+
+		// AlertWebhookURL receives operational alerts (required on mainnet)
+		AlertWebhookURL string `toml:"alert_webhook_url" env:"BTC_GIFTCARD_GUARDRAILS_ALERT_WEBHOOK_URL"`
+
+		// ColdStorageAddress is where excess treasury funds are swept (required on mainnet)
+		ColdStorageAddress string `toml:"cold_storage_address" env:"BTC_GIFTCARD_GUARDRAILS_COLD_STORAGE_ADDRESS"`
+	} `toml:"guardrails"`
+
+	// Worker runtime configuration shared by all queue workers (internal/worker)
+	Worker struct {
+		// HealthAddr is the listen address for the /healthz and /debug/vars endpoints
+		HealthAddr string `toml:"health_addr" env:"BTC_GIFTCARD_WORKER_HEALTH_ADDR" env-default:":8081"`
+
+		// DebugAddr is the listen address for /debug/loglevel. It must be a
+		// loopback address since the endpoint is unauthenticated (empty disables it)
+		DebugAddr string `toml:"debug_addr" env:"BTC_GIFTCARD_WORKER_DEBUG_ADDR" env-default:"127.0.0.1:8082"`
+
+		// MaxDeliveries is how many times a message may fail before it is moved
+		// to the dead-letter stream ("<stream>:dlq") and ACKed
+		MaxDeliveries int64 `toml:"max_deliveries" env:"BTC_GIFTCARD_WORKER_MAX_DELIVERIES" env-default:"5"`
+
+		// ShutdownTimeoutSeconds is how long to wait for the in-flight message on shutdown
+		ShutdownTimeoutSeconds int `toml:"shutdown_timeout_seconds" env:"BTC_GIFTCARD_WORKER_SHUTDOWN_TIMEOUT" env-default:"10"`
+	} `toml:"worker"`
+
+	// Card redemption settings (internal/card)
+	Redemption struct {
+		// InvoiceToleranceSats is the absolute amount (sats) a Lightning invoice may differ
+		// from the requested redemption amount (wallets sometimes round msats)
+		InvoiceToleranceSats int64 `toml:"invoice_tolerance_sats" env:"BTC_GIFTCARD_REDEMPTION_INVOICE_TOLERANCE_SATS" env-default:"1"`
+
+		// InvoiceToleranceBps is the relative tolerance in basis points (10 = 0.1%).
+		// The larger of the two tolerances applies.
+		InvoiceToleranceBps int64 `toml:"invoice_tolerance_bps" env:"BTC_GIFTCARD_REDEMPTION_INVOICE_TOLERANCE_BPS" env-default:"10"`
+
+		// ProbeThresholdSats: Lightning redemptions at or above this amount are route-probed
+		// before paying, failing fast on missing outbound liquidity (0 = never probe)
+		ProbeThresholdSats int64 `toml:"probe_threshold_sats" env:"BTC_GIFTCARD_REDEMPTION_PROBE_THRESHOLD_SATS" env-default:"100000"`
+
+		// InFlightHoldSeconds: an in-flight Lightning payment is held against channel
+		// liquidity until it settles or fails; a hold left by a crash expires after this.
+		// Must exceed lnd.payment_timeout_seconds.
+		InFlightHoldSeconds int `toml:"inflight_hold_seconds" env:"BTC_GIFTCARD_REDEMPTION_INFLIGHT_HOLD_SECONDS" env-default:"600"`
+	} `toml:"redemption"`
+
+	// Checkout rate quotes (internal/card QuoteRate, honored by cmd/worker/fund_card)
+	Quotes struct {
+		// Provider is the price provider used for quotes and card funding ("coinbase", "coingecko", "bitstamp")
+		Provider string `toml:"provider" env:"BTC_GIFTCARD_QUOTES_PROVIDER" env-default:"coinbase"`
+
+		// ValiditySeconds is how long a quoted rate stays locked (0 disables quotes)
+		ValiditySeconds int `toml:"validity_seconds" env:"BTC_GIFTCARD_QUOTES_VALIDITY_SECONDS" env-default:"600"`
+
+		// GuardBps: the fund worker only honors a quote if the market price is within
+		// this many basis points of the quoted price (100 = 1%)
+		GuardBps int64 `toml:"guard_bps" env:"BTC_GIFTCARD_QUOTES_GUARD_BPS" env-default:"100"`
+	} `toml:"quotes"`
+
+	// Card funding (cmd/worker/fund_card, cmd/job/resume_partial_funding)
+	Funding struct {
+		// MinTrancheSats: when treasury can't cover a whole card, a partial tranche is
+		// only reserved if at least this many sats are available
+		MinTrancheSats int64 `toml:"min_tranche_sats" env:"BTC_GIFTCARD_FUNDING_MIN_TRANCHE_SATS" env-default:"1000000"`
+
+		// ResumeBatchSize is how many partially funded cards are re-queued per resume job run
+		ResumeBatchSize int `toml:"resume_batch_size" env:"BTC_GIFTCARD_FUNDING_RESUME_BATCH_SIZE" env-default:"100"`
+	} `toml:"funding"`
+
+	// Incoming Lightning deposits (internal/deposit, cmd/worker/invoice_settlement)
+	Deposits struct {
+		// InvoiceExpirySeconds is how long a deposit invoice can be paid
+		InvoiceExpirySeconds int `toml:"invoice_expiry_seconds" env:"BTC_GIFTCARD_DEPOSITS_INVOICE_EXPIRY_SECONDS" env-default:"86400"`
+
+		// ReconnectDelaySeconds is the wait before resubscribing after the LND invoice stream drops
+		ReconnectDelaySeconds int `toml:"reconnect_delay_seconds" env:"BTC_GIFTCARD_DEPOSITS_RECONNECT_DELAY_SECONDS" env-default:"5"`
+	} `toml:"deposits"`
+
+	// Card balance refunds to the original payment method (internal/card)
+	Refunds struct {
+		// FeeBps is the percentage refund fee in basis points (100 = 1%)
+		FeeBps int64 `toml:"fee_bps" env:"BTC_GIFTCARD_REFUNDS_FEE_BPS" env-default:"200"`
+
+		// FeeFixedCents is a flat fee per refund (covers the provider's refund fee)
+		FeeFixedCents int64 `toml:"fee_fixed_cents" env:"BTC_GIFTCARD_REFUNDS_FEE_FIXED_CENTS" env-default:"50"`
+
+		// MinCents: balances worth less than this after fees cannot be refunded
+		MinCents int64 `toml:"min_cents" env:"BTC_GIFTCARD_REFUNDS_MIN_CENTS" env-default:"500"`
+
+		// MaxCents caps a single refund for compliance review (0 = no limit)
+		MaxCents int64 `toml:"max_cents" env:"BTC_GIFTCARD_REFUNDS_MAX_CENTS" env-default:"100000"`
+	} `toml:"refunds"`
+
+	// Card processor purchases are charged through, used for refunds and retail
+	// activations (internal/payment)
+	Payments struct {
+		// BaseURL is the processor API endpoint (empty disables refunds and retail activations)
+		BaseURL string `toml:"base_url" env:"BTC_GIFTCARD_PAYMENTS_BASE_URL"`
+
+		// APIKey authenticates processor requests
+		APIKey string `toml:"api_key" env:"BTC_GIFTCARD_PAYMENTS_API_KEY"`
+	} `toml:"payments"`
+
+	// SEPA Instant bank payouts through an Open Banking PSP (internal/payout)
+	Payouts struct {
+		// BaseURL is the PSP API endpoint (empty disables bank payouts)
+		BaseURL string `toml:"base_url" env:"BTC_GIFTCARD_PAYOUTS_BASE_URL"`
+
+		// APIKey authenticates payout requests
+		APIKey string `toml:"api_key" env:"BTC_GIFTCARD_PAYOUTS_API_KEY"`
+
+		// WebhookSecret verifies payout status webhooks (X-Webhook-Signature)
+		WebhookSecret string `toml:"webhook_secret" env:"BTC_GIFTCARD_PAYOUTS_WEBHOOK_SECRET"`
+
+		// QuoteValiditySeconds is how long a quoted sats→EUR payout rate stays locked
+		QuoteValiditySeconds int `toml:"quote_validity_seconds" env:"BTC_GIFTCARD_PAYOUTS_QUOTE_VALIDITY_SECONDS" env-default:"120"`
+
+		// MinCents / MaxCents bound a single payout (MaxCents 0 = no limit)
+		MinCents int64 `toml:"min_cents" env:"BTC_GIFTCARD_PAYOUTS_MIN_CENTS" env-default:"100"`
+		MaxCents int64 `toml:"max_cents" env:"BTC_GIFTCARD_PAYOUTS_MAX_CENTS" env-default:"100000"`
+	} `toml:"payouts"`
+
+	// Owner withdrawals from the treasury (internal/withdrawal, cmd/admin withdrawals)
+	Withdrawals struct {
+		// AllowedDestinations are the only on-chain addresses owners may withdraw to
+		AllowedDestinations []string `toml:"allowed_destinations" env:"BTC_GIFTCARD_WITHDRAWALS_ALLOWED_DESTINATIONS" env-separator:","`
+
+		// After a withdrawal the treasury must still hold card liabilities plus
+		// max(liabilities * ReserveBufferBps / 10000, MinReserveBufferSats)
+		ReserveBufferBps     int64 `toml:"reserve_buffer_bps" env:"BTC_GIFTCARD_WITHDRAWALS_RESERVE_BUFFER_BPS" env-default:"1000"`
+		MinReserveBufferSats int64 `toml:"min_reserve_buffer_sats" env:"BTC_GIFTCARD_WITHDRAWALS_MIN_RESERVE_BUFFER_SATS" env-default:"1000000"`
+
+		// TargetConf is the confirmation target of the on-chain send
+		TargetConf int32 `toml:"target_conf" env:"BTC_GIFTCARD_WITHDRAWALS_TARGET_CONF" env-default:"6"`
+	} `toml:"withdrawals"`
+
+	// Support-initiated redemptions (internal/card StartSupportRedemption)
+	Support struct {
+		// RedemptionSecret signs the single-use links emailed to card owners
+		// (empty disables support redemptions)
+		RedemptionSecret string `toml:"redemption_secret" env:"BTC_GIFTCARD_SUPPORT_REDEMPTION_SECRET"`
+
+		// RedemptionLinkTTLSeconds is how long the owner has to confirm a link
+		RedemptionLinkTTLSeconds int `toml:"redemption_link_ttl_seconds" env:"BTC_GIFTCARD_SUPPORT_REDEMPTION_LINK_TTL_SECONDS" env-default:"900"`
+	} `toml:"support"`
+
+	// Transaction retention and invoice redaction (cmd/job/archive_transactions)
+	Retention struct {
+		// ArchiveAfterMonths: settled transactions of redeemed/expired cards older than
+		// this are moved to transactions_archive
+		ArchiveAfterMonths int `toml:"archive_after_months" env:"BTC_GIFTCARD_RETENTION_ARCHIVE_AFTER_MONTHS" env-default:"12"`
+
+		// ArchiveBatchSize is how many rows are moved per statement (keeps locks short)
+		ArchiveBatchSize int `toml:"archive_batch_size" env:"BTC_GIFTCARD_RETENTION_ARCHIVE_BATCH_SIZE" env-default:"1000"`
+
+		// InvoiceMemoPolicy is how paid Lightning invoices, whose memo may hold
+		// personal data, are stored: "keep", "hash" or "strip" (card.MemoPolicy)
+		InvoiceMemoPolicy string `toml:"invoice_memo_policy" env:"BTC_GIFTCARD_RETENTION_INVOICE_MEMO_POLICY" env-default:"hash"`
+
+		// InvoiceRetentionDays: invoices stored in full are hashed once older than this (0 = never)
+		InvoiceRetentionDays int `toml:"invoice_retention_days" env:"BTC_GIFTCARD_RETENTION_INVOICE_RETENTION_DAYS" env-default:"30"`
+	} `toml:"retention"`
+
+	// Card lifecycle analytics events (internal/analytics, cmd/worker/analytics)
+	Analytics struct {
+		// Enabled publishes events to the "analytics_events" stream; leave it
+		// off unless cmd/worker/analytics runs, or the stream grows unread
+		Enabled bool `toml:"enabled" env:"BTC_GIFTCARD_ANALYTICS_ENABLED" env-default:"false"`
+
+		// Sink is where the worker forwards events: "file", "segment" or "kafka"
+		Sink string `toml:"sink" env:"BTC_GIFTCARD_ANALYTICS_SINK" env-default:"file"`
+
+		// FilePath is the JSON-lines file of the file sink
+		FilePath string `toml:"file_path" env:"BTC_GIFTCARD_ANALYTICS_FILE_PATH" env-default:"analytics_events.jsonl"`
+
+		// SegmentWriteKey / SegmentEndpoint configure the Segment sink
+		SegmentWriteKey string `toml:"segment_write_key" env:"BTC_GIFTCARD_ANALYTICS_SEGMENT_WRITE_KEY"`
+		SegmentEndpoint string `toml:"segment_endpoint" env:"BTC_GIFTCARD_ANALYTICS_SEGMENT_ENDPOINT" env-default:"https://api.segment.io"`
+
+		// KafkaRESTURL / KafkaTopic configure the Kafka sink (through a Kafka REST Proxy)
+		KafkaRESTURL string `toml:"kafka_rest_url" env:"BTC_GIFTCARD_ANALYTICS_KAFKA_REST_URL"`
+		KafkaTopic   string `toml:"kafka_topic" env:"BTC_GIFTCARD_ANALYTICS_KAFKA_TOPIC" env-default:"card_analytics"`
+	} `toml:"analytics"`
+
+	// Merchant request signing (internal/signing)
+	RequestSigning struct {
+		// MaxSkewSeconds is how far a request timestamp may be from server time.
+		// Nonces are remembered for twice this window.
+		MaxSkewSeconds int `toml:"max_skew_seconds" env:"BTC_GIFTCARD_REQUEST_SIGNING_MAX_SKEW_SECONDS" env-default:"300"`
+
+		// Secrets maps merchant ID to API secret
+		// TODO: Move to a merchants table once merchant onboarding exists
+		Secrets map[string]string `toml:"secrets"`
+	} `toml:"request_signing"`
+
+	// Feature flag defaults, keyed by flag name (internal/featureflag).
+	// Runtime overrides live in Redis and take precedence.
+	Features map[string]FeatureFlag `toml:"features"`
+}
+
+// FeatureFlag is the config.toml default rule for one feature flag.
+type FeatureFlag struct {
+	Enabled    bool     `toml:"enabled"`    // Kill switch
+	Percentage int      `toml:"percentage"` // 0-100 rollout by subject key
+	Merchants  []string `toml:"merchants"`  // Merchants always enabled
 }
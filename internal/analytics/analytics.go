@@ -0,0 +1,243 @@
+// Package analytics emits card lifecycle events for cohort and funnel
+// analysis, so growth doesn't have to query the production database.
+//
+// Events are published to the "analytics_events" Redis stream by the code
+// that performs the domain change (card.Service, the fund worker) and
+// forwarded by cmd/worker/analytics to the configured Sink (a JSON-lines
+// file, Segment or Kafka). Every event is validated against Registry, the
+// schema of each event's payload, both when it's published and when it's
+// forwarded; a payload change must bump the event's version there.
+//
+// Events carry the card ID and amounts, never emails or codes.
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"btc-giftcard/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Stream is the Redis stream analytics events are published to.
+const Stream = "analytics_events"
+
+// Event names.
+const (
+	CardCreated         = "card_created"
+	CardFunded          = "card_funded"
+	CardFirstRedemption = "card_first_redemption"
+	CardFullyRedeemed   = "card_fully_redeemed"
+)
+
+var (
+	ErrUnknownEvent  = errors.New("unknown analytics event")
+	ErrSchemaVersion = errors.New("analytics event version does not match its schema")
+	ErrInvalidEvent  = errors.New("analytics event does not match its schema")
+)
+
+// PropertyType is the JSON type of an event property.
+type PropertyType string
+
+const (
+	String  PropertyType = "string"
+	Integer PropertyType = "integer"
+	Boolean PropertyType = "boolean"
+)
+
+// Property describes one property of an event payload.
+type Property struct {
+	Type     PropertyType
+	Optional bool
+}
+
+// Schema is the payload of one version of an event.
+type Schema struct {
+	Version    int
+	Properties map[string]Property
+}
+
+// Registry holds the current schema of every event. Properties not listed
+// are rejected, so the payload can't drift without a version bump.
+var Registry = map[string]Schema{
+	CardCreated: {Version: 1, Properties: map[string]Property{
+		"fiat_amount_cents": {Type: Integer},
+		"fiat_currency":     {Type: String},
+		"quoted":            {Type: Boolean}, // Bought at a checkout rate quote
+		"product_id":        {Type: String, Optional: true},
+		"merchant_id":       {Type: String, Optional: true},
+	}},
+	CardFunded: {Version: 1, Properties: map[string]Property{
+		"fiat_amount_cents": {Type: Integer},
+		"fiat_currency":     {Type: String},
+		"funded_sats":       {Type: Integer},
+		"seconds_to_fund":   {Type: Integer}, // Card creation to last tranche
+	}},
+	CardFirstRedemption: {Version: 1, Properties: map[string]Property{
+		"method":               {Type: String},
+		"amount_sats":          {Type: Integer},
+		"remaining_sats":       {Type: Integer},
+		"seconds_since_funded": {Type: Integer, Optional: true},
+	}},
+	CardFullyRedeemed: {Version: 1, Properties: map[string]Property{
+		"method":               {Type: String}, // Method of the last spend
+		"funded_sats":          {Type: Integer},
+		"seconds_since_funded": {Type: Integer, Optional: true},
+	}},
+}
+
+// Event is one analytics event.
+type Event struct {
+	ID         string         `json:"id"` // Sinks use it to deduplicate redeliveries
+	Name       string         `json:"name"`
+	Version    int            `json:"version"`
+	CardID     string         `json:"card_id"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Properties map[string]any `json:"properties"`
+}
+
+// NewEvent creates an event at the current schema version of name.
+func NewEvent(name, cardID string, properties map[string]any) *Event {
+	return &Event{
+		ID:         uuid.New().String(),
+		Name:       name,
+		Version:    Registry[name].Version,
+		CardID:     cardID,
+		OccurredAt: time.Now().UTC(),
+		Properties: properties,
+	}
+}
+
+// Validate checks the event against its schema in Registry.
+func (e *Event) Validate() error {
+	schema, ok := Registry[e.Name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownEvent, e.Name)
+	}
+	if e.Version != schema.Version {
+		return fmt.Errorf("%w: %s v%d, registry has v%d", ErrSchemaVersion, e.Name, e.Version, schema.Version)
+	}
+	if e.ID == "" || e.CardID == "" || e.OccurredAt.IsZero() {
+		return fmt.Errorf("%w: id, card_id and occurred_at are required", ErrInvalidEvent)
+	}
+
+	for name, prop := range schema.Properties {
+		value, ok := e.Properties[name]
+		if !ok {
+			if prop.Optional {
+				continue
+			}
+			return fmt.Errorf("%w: %s is missing %s", ErrInvalidEvent, e.Name, name)
+		}
+		if !hasType(value, prop.Type) {
+			return fmt.Errorf("%w: %s.%s must be a %s", ErrInvalidEvent, e.Name, name, prop.Type)
+		}
+	}
+
+	var unknown []string
+	for name := range e.Properties {
+		if _, ok := schema.Properties[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w: %s has unknown properties %v", ErrInvalidEvent, e.Name, unknown)
+	}
+	return nil
+}
+
+func hasType(value any, t PropertyType) bool {
+	switch t {
+	case String:
+		_, ok := value.(string)
+		return ok
+	case Boolean:
+		_, ok := value.(bool)
+		return ok
+	case Integer:
+		switch v := value.(type) {
+		case int, int32, int64:
+			return true
+		case json.Number:
+			_, err := v.Int64()
+			return err == nil
+		}
+	}
+	return false
+}
+
+// ToJSON serializes the event to JSON bytes.
+func (e *Event) ToJSON() ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal analytics event: %w", err)
+	}
+	return data, nil
+}
+
+// FromJSON deserializes JSON bytes into an Event and validates it. Numbers
+// are kept as json.Number so integers survive the round trip exactly.
+func FromJSON(data []byte) (*Event, error) {
+	e := &Event{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(e); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal analytics event: %w", err)
+	}
+
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// StreamPublisher is the part of queue.StreamQueue the publisher needs.
+type StreamPublisher interface {
+	Publish(ctx context.Context, stream string, data []byte) (string, error)
+}
+
+// Publisher publishes analytics events to Stream.
+type Publisher struct {
+	queue StreamPublisher
+}
+
+// NewPublisher creates a publisher on the given stream queue.
+func NewPublisher(queue StreamPublisher) *Publisher {
+	return &Publisher{queue: queue}
+}
+
+// Emit validates and publishes an event. Failures are logged, not returned:
+// analytics never fails the card operation that produced the event. A nil
+// Publisher (analytics disabled) discards events.
+func (p *Publisher) Emit(ctx context.Context, e *Event) {
+	if p == nil {
+		return
+	}
+
+	if err := e.Validate(); err != nil {
+		logger.Error("Invalid analytics event", zap.String("event", e.Name), zap.String("card_id", e.CardID), zap.Error(err))
+		return
+	}
+	data, err := e.ToJSON()
+	if err != nil {
+		logger.Error("Failed to serialize analytics event", zap.String("event", e.Name), zap.Error(err))
+		return
+	}
+
+	if _, err := p.queue.Publish(ctx, Stream, data); err != nil {
+		logger.Warn("Failed to publish analytics event",
+			zap.String("event", e.Name),
+			zap.String("card_id", e.CardID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,100 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"btc-giftcard/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Initialize logger for tests
+	_ = logger.Init("development")
+}
+
+func createdEvent() *Event {
+	return NewEvent(CardCreated, "card-1", map[string]any{
+		"fiat_amount_cents": int64(5000),
+		"fiat_currency":     "EUR",
+		"quoted":            true,
+	})
+}
+
+func TestEvent_Validate(t *testing.T) {
+	assert.NoError(t, createdEvent().Validate())
+
+	e := createdEvent()
+	e.Properties["product_id"] = "prod-1"
+	assert.NoError(t, e.Validate(), "optional property")
+
+	e = createdEvent()
+	delete(e.Properties, "fiat_currency")
+	assert.ErrorIs(t, e.Validate(), ErrInvalidEvent, "missing property")
+
+	e = createdEvent()
+	e.Properties["fiat_amount_cents"] = "5000"
+	assert.ErrorIs(t, e.Validate(), ErrInvalidEvent, "wrong type")
+
+	e = createdEvent()
+	e.Properties["purchase_email"] = "buyer@example.com"
+	assert.ErrorIs(t, e.Validate(), ErrInvalidEvent, "unknown property")
+
+	e = createdEvent()
+	e.Version = 2
+	assert.ErrorIs(t, e.Validate(), ErrSchemaVersion)
+
+	e = NewEvent("card_lost", "card-1", nil)
+	assert.ErrorIs(t, e.Validate(), ErrUnknownEvent)
+}
+
+func TestEvent_JSONRoundTrip(t *testing.T) {
+	e := createdEvent()
+	data, err := e.ToJSON()
+	require.NoError(t, err)
+
+	got, err := FromJSON(data)
+	require.NoError(t, err, "integers must still validate after decoding")
+	assert.Equal(t, e.ID, got.ID)
+	assert.Equal(t, json.Number("5000"), got.Properties["fiat_amount_cents"])
+
+	_, err = FromJSON([]byte(`{"name":"card_created","version":1}`))
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+}
+
+func TestRegistry_SchemasAreVersioned(t *testing.T) {
+	for name, schema := range Registry {
+		assert.Positive(t, schema.Version, name)
+		assert.NotEmpty(t, schema.Properties, name)
+	}
+}
+
+type fakeStream struct {
+	published map[string][][]byte
+}
+
+func (f *fakeStream) Publish(ctx context.Context, stream string, data []byte) (string, error) {
+	if f.published == nil {
+		f.published = map[string][][]byte{}
+	}
+	f.published[stream] = append(f.published[stream], data)
+	return "1-0", nil
+}
+
+func TestPublisher_Emit(t *testing.T) {
+	stream := &fakeStream{}
+	p := NewPublisher(stream)
+
+	p.Emit(context.Background(), createdEvent())
+	invalid := createdEvent()
+	delete(invalid.Properties, "quoted")
+	p.Emit(context.Background(), invalid)
+
+	assert.Len(t, stream.published[Stream], 1, "invalid events are not published")
+
+	var disabled *Publisher
+	assert.NotPanics(t, func() { disabled.Emit(context.Background(), createdEvent()) })
+}
@@ -0,0 +1,196 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink receives validated analytics events (cmd/worker/analytics).
+type Sink interface {
+	Write(ctx context.Context, e *Event) error
+	Close() error
+}
+
+// SinkConfig selects and configures the sink (populated from config.toml
+// [analytics] section).
+type SinkConfig struct {
+	Sink            string // "file", "segment" or "kafka"
+	FilePath        string // file: JSON-lines file events are appended to
+	SegmentWriteKey string // segment: source write key
+	SegmentEndpoint string // segment: API base URL
+	KafkaRESTURL    string // kafka: Kafka REST Proxy base URL
+	KafkaTopic      string // kafka: topic events are produced to
+}
+
+// NewSink creates the sink named by cfg.Sink.
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Sink {
+	case "file":
+		return NewFileSink(cfg.FilePath)
+	case "segment":
+		if cfg.SegmentWriteKey == "" {
+			return nil, fmt.Errorf("segment sink requires a write key")
+		}
+		return NewSegmentSink(cfg.SegmentEndpoint, cfg.SegmentWriteKey, nil), nil
+	case "kafka":
+		if cfg.KafkaRESTURL == "" || cfg.KafkaTopic == "" {
+			return nil, fmt.Errorf("kafka sink requires a REST proxy URL and a topic")
+		}
+		return NewKafkaSink(cfg.KafkaRESTURL, cfg.KafkaTopic, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown analytics sink %q (use file, segment or kafka)", cfg.Sink)
+	}
+}
+
+// FileSink appends events to a file, one JSON object per line.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens (or creates) path for appending.
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file sink requires a path")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analytics file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write implements Sink.
+func (s *FileSink) Write(ctx context.Context, e *Event) error {
+	data, err := e.ToJSON()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write analytics event: %w", err)
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// SegmentSink sends events to the Segment HTTP tracking API as track calls,
+// with the card ID as anonymous ID. Segment deduplicates on messageId, so a
+// redelivered event is only counted once.
+type SegmentSink struct {
+	httpClient *http.Client
+	endpoint   string
+	writeKey   string
+}
+
+// NewSegmentSink creates a Segment sink (nil httpClient uses a 10s timeout).
+func NewSegmentSink(endpoint, writeKey string, httpClient *http.Client) *SegmentSink {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if endpoint == "" {
+		endpoint = "https://api.segment.io"
+	}
+	return &SegmentSink{httpClient: httpClient, endpoint: strings.TrimRight(endpoint, "/"), writeKey: writeKey}
+}
+
+type segmentTrack struct {
+	MessageID   string         `json:"messageId"`
+	AnonymousID string         `json:"anonymousId"`
+	Event       string         `json:"event"`
+	Timestamp   time.Time      `json:"timestamp"`
+	Properties  map[string]any `json:"properties"`
+}
+
+// Write implements Sink.
+func (s *SegmentSink) Write(ctx context.Context, e *Event) error {
+	properties := make(map[string]any, len(e.Properties)+1)
+	for k, v := range e.Properties {
+		properties[k] = v
+	}
+	properties["schema_version"] = e.Version
+
+	return postJSON(ctx, s.httpClient, s.endpoint+"/v1/track", "application/json", segmentTrack{
+		MessageID:   e.ID,
+		AnonymousID: e.CardID,
+		Event:       e.Name,
+		Timestamp:   e.OccurredAt,
+		Properties:  properties,
+	}, func(req *http.Request) { req.SetBasicAuth(s.writeKey, "") })
+}
+
+// Close implements Sink.
+func (s *SegmentSink) Close() error { return nil }
+
+// KafkaSink produces events to a Kafka topic through a Kafka REST Proxy (v2
+// API), keyed by card ID so each card's events stay ordered in a partition.
+type KafkaSink struct {
+	httpClient *http.Client
+	url        string
+}
+
+// NewKafkaSink creates a Kafka sink (nil httpClient uses a 10s timeout).
+func NewKafkaSink(restURL, topic string, httpClient *http.Client) *KafkaSink {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &KafkaSink{httpClient: httpClient, url: strings.TrimRight(restURL, "/") + "/topics/" + topic}
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value *Event `json:"value"`
+}
+
+// Write implements Sink.
+func (s *KafkaSink) Write(ctx context.Context, e *Event) error {
+	body := struct {
+		Records []kafkaRecord `json:"records"`
+	}{Records: []kafkaRecord{{Key: e.CardID, Value: e}}}
+
+	return postJSON(ctx, s.httpClient, s.url, "application/vnd.kafka.json.v2+json", body, nil)
+}
+
+// Close implements Sink.
+func (s *KafkaSink) Close() error { return nil }
+
+// postJSON posts body as JSON and fails on a non-2xx response.
+func postJSON(ctx context.Context, client *http.Client, url, contentType string, body any, prepare func(*http.Request)) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analytics request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if prepare != nil {
+		prepare(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send analytics event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("analytics sink error: status %d", resp.StatusCode)
+	}
+	return nil
+}
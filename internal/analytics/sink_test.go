@@ -0,0 +1,81 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(context.Background(), createdEvent()))
+	require.NoError(t, sink.Write(context.Background(), createdEvent()))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	_, err = FromJSON([]byte(lines[0]))
+	assert.NoError(t, err)
+}
+
+func TestSegmentSink(t *testing.T) {
+	var got segmentTrack
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "write-key", user)
+		assert.Equal(t, "/v1/track", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer server.Close()
+
+	e := createdEvent()
+	require.NoError(t, NewSegmentSink(server.URL, "write-key", nil).Write(context.Background(), e))
+	assert.Equal(t, e.ID, got.MessageID)
+	assert.Equal(t, "card-1", got.AnonymousID)
+	assert.Equal(t, CardCreated, got.Event)
+	assert.EqualValues(t, 1, got.Properties["schema_version"])
+}
+
+func TestKafkaSink(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/card_analytics", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewKafkaSink(server.URL, "card_analytics", nil).Write(context.Background(), createdEvent()))
+	assert.Contains(t, body, `"key":"card-1"`)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	assert.Error(t, NewKafkaSink(failing.URL, "card_analytics", nil).Write(context.Background(), createdEvent()))
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := NewSink(SinkConfig{Sink: "segment"})
+	assert.Error(t, err, "segment needs a write key")
+	_, err = NewSink(SinkConfig{Sink: "kafka", KafkaRESTURL: "http://proxy"})
+	assert.Error(t, err, "kafka needs a topic")
+	_, err = NewSink(SinkConfig{Sink: "bigquery"})
+	assert.Error(t, err)
+}
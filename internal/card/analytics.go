@@ -0,0 +1,53 @@
+package card
+
+import (
+	"context"
+	"time"
+
+	"btc-giftcard/internal/analytics"
+	"btc-giftcard/internal/database"
+)
+
+// emitCardCreated emits card_created for a newly created card.
+func (s *Service) emitCardCreated(ctx context.Context, card *database.Card, quoted bool) {
+	props := map[string]any{
+		"fiat_amount_cents": card.FiatAmountCents,
+		"fiat_currency":     card.FiatCurrency,
+		"quoted":            quoted,
+	}
+	if card.ProductID != nil {
+		props["product_id"] = *card.ProductID
+	}
+	if card.MerchantID != nil {
+		props["merchant_id"] = *card.MerchantID
+	}
+	s.analytics.Emit(ctx, analytics.NewEvent(analytics.CardCreated, card.ID, props))
+}
+
+// emitRedemption emits card_first_redemption when a spend is the card's first
+// (the balance before it was still the funded amount) and card_fully_redeemed
+// when it empties the card. A spend can be both.
+func (s *Service) emitRedemption(ctx context.Context, card *database.Card, method string, balanceBefore, amountSats, remainingSats int64) {
+	if balanceBefore == card.FundedSats {
+		props := map[string]any{
+			"method":         method,
+			"amount_sats":    amountSats,
+			"remaining_sats": remainingSats,
+		}
+		if card.FundedAt != nil {
+			props["seconds_since_funded"] = int64(time.Since(*card.FundedAt).Seconds())
+		}
+		s.analytics.Emit(ctx, analytics.NewEvent(analytics.CardFirstRedemption, card.ID, props))
+	}
+
+	if remainingSats == 0 {
+		props := map[string]any{
+			"method":      method,
+			"funded_sats": card.FundedSats,
+		}
+		if card.FundedAt != nil {
+			props["seconds_since_funded"] = int64(time.Since(*card.FundedAt).Seconds())
+		}
+		s.analytics.Emit(ctx, analytics.NewEvent(analytics.CardFullyRedeemed, card.ID, props))
+	}
+}
@@ -0,0 +1,59 @@
+package card
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/analytics"
+	"btc-giftcard/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingStream struct {
+	events []*analytics.Event
+}
+
+func (r *recordingStream) Publish(ctx context.Context, stream string, data []byte) (string, error) {
+	e, err := analytics.FromJSON(data)
+	if err != nil {
+		return "", err
+	}
+	r.events = append(r.events, e)
+	return "1-0", nil
+}
+
+func (r *recordingStream) names() []string {
+	var names []string
+	for _, e := range r.events {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+func TestEmitRedemption(t *testing.T) {
+	fundedAt := time.Now().Add(-time.Hour)
+	card := &database.Card{ID: "card-1", FundedSats: 100_000, BTCAmountSats: 100_000, FundedAt: &fundedAt}
+
+	stream := &recordingStream{}
+	s := &Service{analytics: analytics.NewPublisher(stream)}
+
+	s.emitRedemption(context.Background(), card, "lightning", 100_000, 40_000, 60_000)
+	assert.Equal(t, []string{analytics.CardFirstRedemption}, stream.names())
+
+	s.emitRedemption(context.Background(), card, "onchain", 60_000, 60_000, 0)
+	assert.Equal(t, []string{analytics.CardFirstRedemption, analytics.CardFullyRedeemed}, stream.names())
+
+	// A single spend of the whole balance is both
+	stream.events = nil
+	s.emitRedemption(context.Background(), card, "lightning", 100_000, 100_000, 0)
+	require.Equal(t, []string{analytics.CardFirstRedemption, analytics.CardFullyRedeemed}, stream.names())
+	assert.NotNil(t, stream.events[1].Properties["seconds_since_funded"])
+
+	// Analytics disabled
+	assert.NotPanics(t, func() {
+		(&Service{}).emitRedemption(context.Background(), card, "lightning", 100_000, 100_000, 0)
+	})
+}
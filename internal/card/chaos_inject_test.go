@@ -0,0 +1,163 @@
+//go:build integration && chaos
+
+package card
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/chaos"
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/treasury"
+	"btc-giftcard/pkg/cache"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A dropped LND connection fails the redemption cleanly: nothing is paid or
+// charged, the in-flight hold is released, and a retry succeeds.
+func TestChaos_LNDDisconnect_RedeemHeals(t *testing.T) {
+	db := database.SetupTestDB(t)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+	defer chaos.Configure("")
+
+	require.NoError(t, cache.Init(cache.Config{Host: "localhost", Port: "6379", DB: 1}))
+	defer cache.Close()
+
+	ctx := context.Background()
+	queue := streams.NewStreamQueue(cache.Client)
+	cardRepo := database.NewCardRepository(db)
+	txRepo := database.NewTransactionRepository(db)
+	node := &fakeLND{}
+	svc := NewService(Config{Network: "testnet"}, Deps{
+		Cards:        cardRepo,
+		Transactions: txRepo,
+		Queue:        queue,
+		Custodian:    treasury.NewLNDCustodian(node, 100),
+	})
+
+	// An active card holding 100,000 sats
+	now := time.Now().UTC()
+	target := int64(100_000)
+	card := &database.Card{
+		ID:                 uuid.New().String(),
+		PurchaseEmail:      "chaos@example.com",
+		OwnerEmail:         "chaos@example.com",
+		Code:               "GIFT-CHAO-" + uuid.New().String()[:9],
+		FiatAmountCents:    5_000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5_000,
+		Status:             database.Created,
+		CreatedAt:          now,
+	}
+	require.NoError(t, cardRepo.Create(ctx, card))
+	require.NoError(t, cardRepo.UpdateFunding(ctx, card.ID, database.Active, target, &target, &now))
+	require.NoError(t, txRepo.Create(ctx, &database.Transaction{
+		ID: uuid.New().String(), CardID: card.ID, Type: database.Fund,
+		BTCAmountSats: target, Status: database.Confirmed, CreatedAt: now, ConfirmedAt: &now,
+	}))
+
+	lightning := RedeemCardRequest{Code: card.Code, Method: Lightning, AmountSats: 30_000, LightningInvoice: "lnfake:30000:" + randomHash()}
+	onChain := RedeemCardRequest{Code: card.Code, Method: OnChain, AmountSats: 20_000, DestinationAddress: ledgerTestAddress}
+
+	require.NoError(t, chaos.Configure("lnd.disconnect=1"))
+	for _, req := range []RedeemCardRequest{lightning, onChain} {
+		_, err := svc.RedeemCard(ctx, req)
+		require.ErrorIs(t, err, chaos.ErrInjected, req.Method)
+	}
+
+	assert.Zero(t, node.payments, "nothing reached LND")
+	got, err := cardRepo.GetByID(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target, got.BTCAmountSats, "balance untouched")
+	inFlight, err := svc.LightningInFlightSats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, inFlight, "in-flight hold released")
+
+	// Connection back: retries succeed
+	require.NoError(t, chaos.Configure(""))
+	for _, req := range []RedeemCardRequest{lightning, onChain} {
+		_, err := svc.RedeemCard(ctx, req)
+		require.NoError(t, err, req.Method)
+	}
+
+	got, err = cardRepo.GetByID(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50_000), got.BTCAmountSats)
+	discrepancies, err := txRepo.LedgerDiscrepancies(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, discrepancies)
+}
+
+// A failure after the card is charged but before the Redeem transaction is
+// recorded never leaves the paid amount spendable: the card stays charged and
+// the ledger check reports the missing transaction for manual repair.
+func TestChaos_RedeemAfterCardUpdate_Surfaces(t *testing.T) {
+	db := database.SetupTestDB(t)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+	defer chaos.Configure("")
+
+	require.NoError(t, cache.Init(cache.Config{Host: "localhost", Port: "6379", DB: 1}))
+	defer cache.Close()
+
+	ctx := context.Background()
+	cardRepo := database.NewCardRepository(db)
+	txRepo := database.NewTransactionRepository(db)
+	node := &fakeLND{}
+	svc := NewService(Config{Network: "testnet"}, Deps{
+		Cards:        cardRepo,
+		Transactions: txRepo,
+		Queue:        streams.NewStreamQueue(cache.Client),
+		Custodian:    treasury.NewLNDCustodian(node, 100),
+	})
+
+	now := time.Now().UTC()
+	target := int64(100_000)
+	card := &database.Card{
+		ID:                 uuid.New().String(),
+		PurchaseEmail:      "chaos@example.com",
+		OwnerEmail:         "chaos@example.com",
+		Code:               "GIFT-CHAO-" + uuid.New().String()[:9],
+		FiatAmountCents:    5_000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5_000,
+		Status:             database.Created,
+		CreatedAt:          now,
+	}
+	require.NoError(t, cardRepo.Create(ctx, card))
+	require.NoError(t, cardRepo.UpdateFunding(ctx, card.ID, database.Active, target, &target, &now))
+	require.NoError(t, txRepo.Create(ctx, &database.Transaction{
+		ID: uuid.New().String(), CardID: card.ID, Type: database.Fund,
+		BTCAmountSats: target, Status: database.Confirmed, CreatedAt: now, ConfirmedAt: &now,
+	}))
+
+	require.NoError(t, chaos.Configure("redeem.after_card_update=1"))
+	_, err := svc.RedeemCard(ctx, RedeemCardRequest{Code: card.Code, Method: OnChain, AmountSats: 20_000, DestinationAddress: ledgerTestAddress})
+	require.ErrorIs(t, err, chaos.ErrInjected)
+	assert.Equal(t, 1, node.payments, "payment sent before the fault")
+
+	got, err := cardRepo.GetByID(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(80_000), got.BTCAmountSats, "card charged for the payment")
+	txs, err := txRepo.ListByCardID(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Len(t, txs, 1, "fault hit before the Redeem transaction insert")
+
+	// The paid amount can't be redeemed a second time
+	require.NoError(t, chaos.Configure(""))
+	_, err = svc.RedeemCard(ctx, RedeemCardRequest{Code: card.Code, Method: OnChain, AmountSats: target, DestinationAddress: ledgerTestAddress})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	discrepancies, err := txRepo.LedgerDiscrepancies(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, discrepancies, 1)
+	assert.Equal(t, card.ID, discrepancies[0].CardID)
+	assert.Equal(t, int64(80_000), discrepancies[0].BalanceSats)
+	assert.Equal(t, target, discrepancies[0].LedgerSats)
+}
@@ -0,0 +1,127 @@
+//go:build integration
+
+package card
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/lnd"
+	"btc-giftcard/internal/treasury"
+	"btc-giftcard/pkg/cache"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLND is an lnd.LightningClient with ample balances whose payments always
+// succeed. Invoices use the ledgerCustodian "lnfake:<amount>:<hash>" format.
+// stuck makes payments time out with HTLCs in flight until resolved is closed,
+// after which TrackPayment reports them failed.
+type fakeLND struct {
+	lnd.LightningClient // embed for interface compliance
+
+	payments int
+	stuck    bool
+	resolved chan struct{}
+}
+
+func (f *fakeLND) GetChannelBalance(_ context.Context) (*lnd.ChannelBalance, error) {
+	return &lnd.ChannelBalance{LocalSats: 100_000_000}, nil
+}
+
+func (f *fakeLND) GetWalletBalance(_ context.Context) (*lnd.WalletBalance, error) {
+	return &lnd.WalletBalance{ConfirmedSats: 100_000_000, TotalSats: 100_000_000}, nil
+}
+
+func (f *fakeLND) DecodeInvoice(_ context.Context, bolt11 string) (*lnd.Invoice, error) {
+	var amount int64
+	var hash string
+	if _, err := fmt.Sscanf(bolt11, "lnfake:%d:%s", &amount, &hash); err != nil {
+		return nil, err
+	}
+	return &lnd.Invoice{AmountSats: amount, PaymentHash: hash}, nil
+}
+
+func (f *fakeLND) PayInvoice(ctx context.Context, bolt11 string, _ int64) (*lnd.PaymentResult, error) {
+	decoded, err := f.DecodeInvoice(ctx, bolt11)
+	if err != nil {
+		return nil, err
+	}
+	if f.stuck {
+		return nil, errors.New("payment stream error: context deadline exceeded")
+	}
+	f.payments++
+	return &lnd.PaymentResult{PaymentHash: decoded.PaymentHash, PaymentPreimage: randomHash(), Status: lnd.Succeeded}, nil
+}
+
+func (f *fakeLND) TrackPayment(ctx context.Context, paymentHash string) (*lnd.PaymentResult, error) {
+	select {
+	case <-f.resolved:
+		return &lnd.PaymentResult{PaymentHash: paymentHash, Status: lnd.Failed}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *fakeLND) SendOnChain(_ context.Context, _ string, _ int64, _ int32) (*lnd.OnChainResult, error) {
+	f.payments++
+	return &lnd.OnChainResult{TxHash: randomHash()}, nil
+}
+
+// A payment that times out with HTLCs still in flight keeps its liquidity hold
+// until LND reports it failed, instead of freeing it for other redemptions.
+func TestChaos_LightningTimeout_KeepsHoldUntilResolved(t *testing.T) {
+	db := database.SetupTestDB(t)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+
+	require.NoError(t, cache.Init(cache.Config{Host: "localhost", Port: "6379", DB: 1}))
+	defer cache.Close()
+
+	ctx := context.Background()
+	cardRepo := database.NewCardRepository(db)
+	txRepo := database.NewTransactionRepository(db)
+	node := &fakeLND{stuck: true, resolved: make(chan struct{})}
+	svc := NewService(Config{Network: "testnet"}, Deps{
+		Cards:        cardRepo,
+		Transactions: txRepo,
+		Queue:        streams.NewStreamQueue(cache.Client),
+		Custodian:    treasury.NewLNDCustodian(node, 100),
+	})
+
+	now := time.Now().UTC()
+	target := int64(100_000)
+	card := &database.Card{
+		ID:                 uuid.New().String(),
+		PurchaseEmail:      "chaos@example.com",
+		OwnerEmail:         "chaos@example.com",
+		Code:               "GIFT-CHAO-" + uuid.New().String()[:9],
+		FiatAmountCents:    5_000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5_000,
+		Status:             database.Created,
+		CreatedAt:          now,
+	}
+	require.NoError(t, cardRepo.Create(ctx, card))
+	require.NoError(t, cardRepo.UpdateFunding(ctx, card.ID, database.Active, target, &target, &now))
+
+	_, err := svc.RedeemCard(ctx, RedeemCardRequest{Code: card.Code, Method: Lightning, AmountSats: 30_000, LightningInvoice: "lnfake:30000:" + randomHash()})
+	require.Error(t, err)
+
+	inFlight, err := svc.LightningInFlightSats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(30_000), inFlight, "hold kept while the payment is unresolved")
+
+	close(node.resolved)
+	assert.Eventually(t, func() bool {
+		inFlight, err := svc.LightningInFlightSats(ctx)
+		return err == nil && inFlight == 0
+	}, 5*time.Second, 50*time.Millisecond, "hold released once the payment failed")
+}
@@ -0,0 +1,156 @@
+package card
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"btc-giftcard/internal/database"
+)
+
+var (
+	ErrGiftNotFound  = errors.New("card has no gift message")
+	ErrGiftDelivered = errors.New("gift already delivered, it can no longer be changed")
+	ErrInvalidGift   = errors.New("invalid gift details")
+)
+
+// Gift field limits (characters, after sanitization). Match card_gifts column sizes.
+const (
+	maxGiftNameLength    = 100
+	maxGiftMessageLength = 500
+	defaultGiftTheme     = "classic"
+)
+
+// GiftThemes lists the voucher/email designs a gift can use.
+var GiftThemes = []string{"classic", "birthday", "holiday", "thank_you", "congrats"}
+
+// htmlTagPattern matches HTML/XML tags, which are stripped from gift text.
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// GiftDetails is the optional personalization a purchaser attaches to a card.
+// It is shown to the recipient in the delivery email and on the voucher PDF,
+// so every field is plain text (HTML is stripped on input and templates
+// escape on output).
+type GiftDetails struct {
+	SenderName    string
+	RecipientName string
+	Message       string
+	Theme         string // One of GiftThemes ("" = classic)
+}
+
+// sanitizeGift strips HTML and control characters, trims whitespace and
+// enforces length and theme limits. Returns ErrInvalidGift on violation.
+func sanitizeGift(g GiftDetails) (GiftDetails, error) {
+	out := GiftDetails{
+		SenderName:    sanitizeGiftText(g.SenderName, false),
+		RecipientName: sanitizeGiftText(g.RecipientName, false),
+		Message:       sanitizeGiftText(g.Message, true),
+		Theme:         strings.ToLower(strings.TrimSpace(g.Theme)),
+	}
+
+	if out.Theme == "" {
+		out.Theme = defaultGiftTheme
+	}
+	if !slices.Contains(GiftThemes, out.Theme) {
+		return GiftDetails{}, fmt.Errorf("%w: unknown theme %q", ErrInvalidGift, g.Theme)
+	}
+
+	if utf8.RuneCountInString(out.SenderName) > maxGiftNameLength {
+		return GiftDetails{}, fmt.Errorf("%w: sender name exceeds %d characters", ErrInvalidGift, maxGiftNameLength)
+	}
+	if utf8.RuneCountInString(out.RecipientName) > maxGiftNameLength {
+		return GiftDetails{}, fmt.Errorf("%w: recipient name exceeds %d characters", ErrInvalidGift, maxGiftNameLength)
+	}
+	if utf8.RuneCountInString(out.Message) > maxGiftMessageLength {
+		return GiftDetails{}, fmt.Errorf("%w: message exceeds %d characters", ErrInvalidGift, maxGiftMessageLength)
+	}
+
+	return out, nil
+}
+
+// sanitizeGiftText removes HTML tags, decodes entities and drops control
+// characters. Newlines are kept only when multiline (the message body);
+// names are collapsed onto one line.
+func sanitizeGiftText(s string, multiline bool) string {
+	s = html.UnescapeString(htmlTagPattern.ReplaceAllString(s, ""))
+	// Unescaping may reveal tags that were entity-encoded (&lt;script&gt;)
+	s = htmlTagPattern.ReplaceAllString(s, "")
+
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' && multiline {
+			return r
+		}
+		if unicode.IsControl(r) {
+			if unicode.IsSpace(r) {
+				return ' '
+			}
+			return -1
+		}
+		return r
+	}, s)
+
+	if !multiline {
+		return strings.Join(strings.Fields(s), " ")
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// GetGift returns a card's gift personalization.
+// Returns ErrGiftNotFound if the card was bought without one.
+func (s *Service) GetGift(ctx context.Context, cardID string) (*database.CardGift, error) {
+	gift, err := s.giftRepo.GetByCardID(ctx, cardID)
+	if err != nil {
+		if errors.Is(err, database.ErrGiftNotFound) {
+			return nil, ErrGiftNotFound
+		}
+		return nil, fmt.Errorf("failed to get gift: %w", err)
+	}
+	return gift, nil
+}
+
+// UpdateGift sets or replaces a card's gift personalization. Only allowed
+// until the gift is delivered to the recipient (ErrGiftDelivered after).
+func (s *Service) UpdateGift(ctx context.Context, cardID string, details GiftDetails) error {
+	if _, err := s.cardRepo.GetByID(ctx, cardID); err != nil {
+		if errors.Is(err, database.ErrCardNotFound) {
+			return ErrCardNotFound
+		}
+		return fmt.Errorf("failed to get card: %w", err)
+	}
+
+	clean, err := sanitizeGift(details)
+	if err != nil {
+		return err
+	}
+
+	return s.saveGift(ctx, cardID, clean)
+}
+
+// saveGift stores already-sanitized gift details.
+func (s *Service) saveGift(ctx context.Context, cardID string, g GiftDetails) error {
+	err := s.giftRepo.Upsert(ctx, &database.CardGift{
+		CardID:        cardID,
+		SenderName:    g.SenderName,
+		RecipientName: g.RecipientName,
+		Message:       g.Message,
+		Theme:         g.Theme,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrGiftDelivered) {
+			return ErrGiftDelivered
+		}
+		return fmt.Errorf("failed to save gift: %w", err)
+	}
+	return nil
+}
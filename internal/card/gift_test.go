@@ -0,0 +1,55 @@
+package card
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeGift(t *testing.T) {
+	gift, err := sanitizeGift(GiftDetails{
+		SenderName:    "  <b>Alice</b>  ",
+		RecipientName: "Bob\t\nSmith",
+		Message:       "Happy <script>alert(1)</script>birthday!\r\n\n  Enjoy &lt;img src=x&gt;the sats ",
+		Theme:         " Birthday ",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Alice", gift.SenderName)
+	assert.Equal(t, "Bob Smith", gift.RecipientName, "names are collapsed onto one line")
+	assert.Equal(t, "Happy alert(1)birthday!\n\nEnjoy the sats", gift.Message, "tags (including entity-encoded) are stripped, newlines kept")
+	assert.Equal(t, "birthday", gift.Theme)
+}
+
+func TestSanitizeGift_DefaultTheme(t *testing.T) {
+	gift, err := sanitizeGift(GiftDetails{Message: "Enjoy"})
+	require.NoError(t, err)
+	assert.Equal(t, "classic", gift.Theme)
+}
+
+func TestSanitizeGift_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		gift GiftDetails
+	}{
+		{"Unknown theme", GiftDetails{Theme: "neon"}},
+		{"Sender name too long", GiftDetails{SenderName: strings.Repeat("a", maxGiftNameLength+1)}},
+		{"Recipient name too long", GiftDetails{RecipientName: strings.Repeat("a", maxGiftNameLength+1)}},
+		{"Message too long", GiftDetails{Message: strings.Repeat("é", maxGiftMessageLength+1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sanitizeGift(tt.gift)
+			assert.ErrorIs(t, err, ErrInvalidGift)
+		})
+	}
+}
+
+func TestSanitizeGift_LengthCountsCharacters(t *testing.T) {
+	// Multi-byte characters count once each
+	_, err := sanitizeGift(GiftDetails{Message: strings.Repeat("€", maxGiftMessageLength)})
+	assert.NoError(t, err)
+}
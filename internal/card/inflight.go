@@ -0,0 +1,235 @@
+package card
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"btc-giftcard/internal/treasury"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// In-flight Lightning payments are tracked in a Redis sorted set:
+//
+//	member = "<payment_hash>:<amount_sats>"
+//	score  = unix time after which the hold expires
+//
+// A hold is added (under the Lightning liquidity lock) before paying and
+// removed once the payment settles or fails. If paying returns without a
+// terminal status (e.g. it timed out with HTLCs still in flight), the hold is
+// kept and refreshed until TrackLightning resolves the payment. The expiry
+// score is a safety net: if the process crashes mid-payment, the hold stops
+// counting once it expires instead of leaking forever like a plain counter would.
+const (
+	lightningInFlightKey   = "treasury:lightning_inflight"
+	defaultInFlightHoldTTL = 10 * time.Minute
+)
+
+// The Lightning liquidity lock serializes hold reservations. It is separate
+// from treasury:lock (card funding) so redemptions don't fail whenever a card
+// is being funded, and is retried with backoff since it's only held briefly.
+const (
+	lightningLockKey      = "treasury:lightning_lock"
+	lightningLockTTL      = 5 * time.Second
+	lightningLockAttempts = 6
+	lightningLockBackoff  = 25 * time.Millisecond // Doubles per attempt (~1.5s in total)
+)
+
+// Resolving a payment left in flight
+const (
+	trackRetryBackoff    = 5 * time.Second
+	trackRetryMaxBackoff = time.Minute
+)
+
+// lightningHold is an in-flight Lightning amount reserved against channel liquidity.
+type lightningHold struct {
+	member string
+}
+
+// inFlightHoldTTL returns how long a hold counts before it expires on its own.
+func (s *Service) inFlightHoldTTL() time.Duration {
+	if s.cfg.InFlightHoldTTL > 0 {
+		return s.cfg.InFlightHoldTTL
+	}
+	return defaultInFlightHoldTTL
+}
+
+// LightningInFlightSats returns the total amount of Lightning payments
+// currently in flight (HTLCs sent but not yet settled or failed).
+func (s *Service) LightningInFlightSats(ctx context.Context) (int64, error) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	// Drop expired holds, then sum what's left
+	if err := cache.Client.ZRemRangeByScore(ctx, lightningInFlightKey, "-inf", "("+now).Err(); err != nil {
+		return 0, fmt.Errorf("failed to prune in-flight payments: %w", err)
+	}
+	return activeInFlightSats(ctx, now)
+}
+
+// activeInFlightSats sums the holds that haven't expired at now (unix
+// seconds) without pruning the expired ones, so it only reads Redis.
+func activeInFlightSats(ctx context.Context, now string) (int64, error) {
+	members, err := cache.Client.ZRangeByScore(ctx, lightningInFlightKey, &redis.ZRangeBy{Min: now, Max: "+inf"}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list in-flight payments: %w", err)
+	}
+
+	var total int64
+	for _, member := range members {
+		total += holdAmount(member)
+	}
+	return total, nil
+}
+
+// reserveLightningLiquidity checks that local channel balance minus in-flight
+// payments covers amountSats, and if so records a hold for it. The check and
+// the hold run under the Lightning liquidity lock so concurrent redemptions
+// can't both claim the same liquidity. Returns ErrNoLiquidity when it isn't covered.
+//
+// LND stops counting an HTLC in its local balance once it's committed, so a
+// payment can be subtracted twice for a short while. That errs on the side of
+// refusing a redemption, never of over-committing.
+func (s *Service) reserveLightningLiquidity(ctx context.Context, paymentHash string, amountSats int64) (*lightningHold, error) {
+	if err := acquireLightningLock(ctx); err != nil {
+		return nil, err
+	}
+	defer releaseLightningLock(ctx)
+
+	balances, err := s.custodian.GetBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury balances: %w", err)
+	}
+
+	inFlight, err := s.LightningInFlightSats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	available := balances.LightningSats - inFlight
+	if amountSats > available {
+		logger.Warn("Lightning liquidity exhausted by in-flight payments",
+			zap.Int64("amount_sats", amountSats),
+			zap.Int64("local_balance_sats", balances.LightningSats),
+			zap.Int64("in_flight_sats", inFlight),
+		)
+		return nil, fmt.Errorf("%w: %d sats available, %d sats in flight", ErrNoLiquidity, available, inFlight)
+	}
+
+	hold := &lightningHold{member: paymentHash + ":" + strconv.FormatInt(amountSats, 10)}
+	expiresAt := time.Now().Add(s.inFlightHoldTTL()).Unix()
+	if err := cache.Client.ZAdd(ctx, lightningInFlightKey, redis.Z{Score: float64(expiresAt), Member: hold.member}).Err(); err != nil {
+		return nil, fmt.Errorf("failed to record in-flight payment: %w", err)
+	}
+	return hold, nil
+}
+
+// releaseLightningHold removes a hold once its payment has settled or failed.
+// Best-effort: a hold that can't be removed still expires on its own.
+func (s *Service) releaseLightningHold(ctx context.Context, hold *lightningHold) {
+	if err := cache.Client.ZRem(ctx, lightningInFlightKey, hold.member).Err(); err != nil {
+		logger.Warn("failed to release in-flight payment hold", zap.String("hold", hold.member), zap.Error(err))
+	}
+}
+
+// keepLightningHold keeps a hold whose payment PayLightning left unresolved
+// until TrackLightning reports it succeeded or failed, refreshing its expiry
+// meanwhile so it keeps counting against liquidity. Runs in the background,
+// detached from the redemption request.
+func (s *Service) keepLightningHold(hold *lightningHold, paymentHash string) {
+	ctx := context.Background()
+	backoff := trackRetryBackoff
+	for {
+		s.refreshLightningHold(ctx, hold)
+
+		trackCtx, cancel := context.WithTimeout(ctx, s.inFlightHoldTTL()/2)
+		payment, err := s.custodian.TrackLightning(trackCtx, paymentHash)
+		cancel()
+
+		switch {
+		case err == nil:
+			// The redemption already failed and the card was not charged
+			logger.Error("CRITICAL: Lightning payment reported as failed has settled, card was not charged",
+				zap.String("payment_hash", payment.PaymentHash),
+				zap.String("payment_preimage", payment.PaymentPreimage),
+				zap.Int64("amount_sats", holdAmount(hold.member)),
+			)
+			s.releaseLightningHold(ctx, hold)
+			return
+		case errors.Is(err, treasury.ErrPaymentFailed):
+			logger.Info("In-flight Lightning payment failed, releasing hold", zap.String("payment_hash", paymentHash))
+			s.releaseLightningHold(ctx, hold)
+			return
+		}
+
+		logger.Warn("Lightning payment still unresolved, keeping hold",
+			zap.String("payment_hash", paymentHash),
+			zap.Duration("retry_in", backoff),
+			zap.Error(err),
+		)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, trackRetryMaxBackoff)
+	}
+}
+
+// refreshLightningHold pushes a hold's expiry out by the hold TTL. Does
+// nothing if the hold is gone.
+func (s *Service) refreshLightningHold(ctx context.Context, hold *lightningHold) {
+	expiresAt := time.Now().Add(s.inFlightHoldTTL()).Unix()
+	err := cache.Client.ZAddXX(ctx, lightningInFlightKey, redis.Z{Score: float64(expiresAt), Member: hold.member}).Err()
+	if err != nil {
+		logger.Warn("failed to refresh in-flight payment hold", zap.String("hold", hold.member), zap.Error(err))
+	}
+}
+
+// acquireLightningLock takes the Lightning liquidity lock, retrying with
+// backoff while another redemption holds it. Returns ErrTreasuryLockBusy if it
+// stays busy.
+func acquireLightningLock(ctx context.Context) error {
+	backoff := lightningLockBackoff
+	for attempt := 1; ; attempt++ {
+		acquired, err := cache.SetNX(ctx, lightningLockKey, "locked", lightningLockTTL)
+		if err != nil {
+			return fmt.Errorf("failed to acquire lightning liquidity lock: %w", err)
+		}
+		if acquired {
+			return nil
+		}
+		if attempt == lightningLockAttempts {
+			return ErrTreasuryLockBusy
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// releaseLightningLock releases the Lightning liquidity lock.
+func releaseLightningLock(ctx context.Context) {
+	if _, err := cache.Delete(ctx, lightningLockKey); err != nil {
+		logger.Warn("failed to release lightning liquidity lock", zap.Error(err))
+	}
+}
+
+// holdAmount parses the amount out of a "<payment_hash>:<amount_sats>" member.
+func holdAmount(member string) int64 {
+	i := strings.LastIndexByte(member, ':')
+	if i < 0 {
+		return 0
+	}
+	amount, err := strconv.ParseInt(member[i+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return amount
+}
@@ -0,0 +1,20 @@
+package card
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoldAmount(t *testing.T) {
+	assert.Equal(t, int64(150_000), holdAmount("ab12cd:150000"))
+	assert.Equal(t, int64(42), holdAmount("a:b:42"), "amount is after the last colon")
+	assert.Equal(t, int64(0), holdAmount("no-amount"))
+	assert.Equal(t, int64(0), holdAmount("hash:notanumber"))
+}
+
+func TestInFlightHoldTTL(t *testing.T) {
+	assert.Equal(t, defaultInFlightHoldTTL, (&Service{}).inFlightHoldTTL())
+	assert.Equal(t, time.Minute, (&Service{cfg: Config{InFlightHoldTTL: time.Minute}}).inFlightHoldTTL())
+}
@@ -0,0 +1,33 @@
+package card
+
+import "fmt"
+
+// invoiceTolerance returns the maximum allowed difference (in sats) between a
+// Lightning invoice amount and the requested redemption amount.
+func (s *Service) invoiceTolerance(requestedSats int64) int64 {
+	tolerance := s.cfg.InvoiceToleranceSats
+	if pct := requestedSats * s.cfg.InvoiceToleranceBps / 10_000; pct > tolerance {
+		tolerance = pct
+	}
+	return tolerance
+}
+
+// checkInvoiceAmount validates a decoded invoice amount against the requested
+// amount and the card balance. The invoice may never exceed the card balance,
+// and may only differ from the request by the configured tolerance.
+func (s *Service) checkInvoiceAmount(invoiceSats, requestedSats, balanceSats int64) error {
+	if invoiceSats > balanceSats {
+		return ErrInsufficientFunds
+	}
+
+	diff := invoiceSats - requestedSats
+	if diff < 0 {
+		diff = -diff
+	}
+	if tolerance := s.invoiceTolerance(requestedSats); diff > tolerance {
+		return fmt.Errorf("%w: invoice is %d sats, requested %d sats (tolerance %d sats)",
+			ErrInvoiceAmount, invoiceSats, requestedSats, tolerance)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package card
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"btc-giftcard/internal/treasury"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCustodian stubs treasury.Custodian for unit tests.
+type stubCustodian struct {
+	treasury.Custodian // embed for interface compliance
+
+	estimateFn func(ctx context.Context, bolt11 string) (*treasury.FeeEstimate, error)
+}
+
+func (c *stubCustodian) EstimateLightningFee(ctx context.Context, bolt11 string) (*treasury.FeeEstimate, error) {
+	return c.estimateFn(ctx, bolt11)
+}
+
+func TestInvoiceTolerance(t *testing.T) {
+	s := &Service{cfg: Config{InvoiceToleranceSats: 1, InvoiceToleranceBps: 10}}
+
+	assert.Equal(t, int64(1), s.invoiceTolerance(500), "small amounts use the absolute floor")
+	assert.Equal(t, int64(100), s.invoiceTolerance(100_000), "0.1% of 100k sats")
+}
+
+func TestCheckInvoiceAmount(t *testing.T) {
+	s := &Service{cfg: Config{InvoiceToleranceSats: 1, InvoiceToleranceBps: 10}}
+
+	tests := []struct {
+		name      string
+		invoice   int64
+		requested int64
+		balance   int64
+		expectErr error
+	}{
+		{"Exact match", 50_000, 50_000, 100_000, nil},
+		{"Rounded down by 1 sat", 49_999, 50_000, 100_000, nil},
+		{"Within 0.1%", 50_050, 50_000, 100_000, nil},
+		{"Beyond tolerance", 50_051, 50_000, 100_000, ErrInvoiceAmount},
+		{"Too low", 40_000, 50_000, 100_000, ErrInvoiceAmount},
+		{"Exceeds card balance", 50_001, 50_000, 50_000, ErrInsufficientFunds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkInvoiceAmount(tt.invoice, tt.requested, tt.balance)
+			if tt.expectErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestCheckInvoiceAmount_NoTolerance(t *testing.T) {
+	s := &Service{cfg: Config{}}
+
+	assert.NoError(t, s.checkInvoiceAmount(50_000, 50_000, 100_000))
+	assert.ErrorIs(t, s.checkInvoiceAmount(49_999, 50_000, 100_000), ErrInvoiceAmount)
+}
+
+func TestEstimateLightningFee(t *testing.T) {
+	s := &Service{custodian: &stubCustodian{
+		estimateFn: func(_ context.Context, _ string) (*treasury.FeeEstimate, error) {
+			return &treasury.FeeEstimate{FeeSats: 7, TimeLockDelay: 40}, nil
+		},
+	}}
+
+	fee, err := s.EstimateLightningFee(context.Background(), "lntb1...")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), fee)
+
+	_, err = s.EstimateLightningFee(context.Background(), "")
+	assert.ErrorIs(t, err, ErrLightningInvoice)
+}
+
+func TestEstimateLightningFee_NoLiquidity(t *testing.T) {
+	s := &Service{custodian: &stubCustodian{
+		estimateFn: func(_ context.Context, _ string) (*treasury.FeeEstimate, error) {
+			return nil, fmt.Errorf("%w: FAILURE_REASON_NO_ROUTE", treasury.ErrInsufficientLiquidity)
+		},
+	}}
+
+	_, err := s.EstimateLightningFee(context.Background(), "lntb1...")
+	assert.ErrorIs(t, err, ErrNoLiquidity)
+}
@@ -0,0 +1,291 @@
+//go:build integration
+
+package card
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/featureflag"
+	"btc-giftcard/internal/payment"
+	"btc-giftcard/internal/treasury"
+	"btc-giftcard/pkg/cache"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+// Property tests over the card lifecycle: arbitrary sequences of funding
+// tranches, partial redemptions (Lightning and on-chain, some of them failing
+// at the custodian) and refunds (some declined) must keep every card balance
+// equal to its transaction ledger and never negative.
+//
+// Runs against the docker-compose Postgres and Redis:
+//
+//	docker compose up -d postgres redis
+//	go test -tags=integration ./internal/card/ -run LedgerInvariant -rapid.checks=200
+
+const ledgerTestAddress = "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx" // BIP173 testnet example
+
+var errInjected = errors.New("injected failure")
+
+// ledgerCustodian is an in-memory treasury.Custodian with ample liquidity.
+// Invoices are "lnfake:<amount>:<hash>"; fail makes the next payment fail.
+type ledgerCustodian struct {
+	treasury.Custodian // embed for interface compliance
+
+	fail bool
+}
+
+func (c *ledgerCustodian) GetBalances(_ context.Context) (*treasury.Balances, error) {
+	return &treasury.Balances{LightningSats: 1_000_000_000_000, OnChainConfirmedSats: 1_000_000_000_000}, nil
+}
+
+func (c *ledgerCustodian) DecodeInvoice(_ context.Context, bolt11 string) (*treasury.Invoice, error) {
+	var amount int64
+	var hash string
+	if _, err := fmt.Sscanf(bolt11, "lnfake:%d:%s", &amount, &hash); err != nil {
+		return nil, err
+	}
+	return &treasury.Invoice{AmountSats: amount, PaymentHash: hash}, nil
+}
+
+func (c *ledgerCustodian) PayLightning(_ context.Context, bolt11 string) (*treasury.LightningPayment, error) {
+	if c.fail {
+		return nil, fmt.Errorf("%w: %w", treasury.ErrPaymentFailed, errInjected)
+	}
+	decoded, err := c.DecodeInvoice(context.Background(), bolt11)
+	if err != nil {
+		return nil, err
+	}
+	return &treasury.LightningPayment{PaymentHash: decoded.PaymentHash, PaymentPreimage: randomHash()}, nil
+}
+
+func (c *ledgerCustodian) SendOnChain(_ context.Context, _ string, _ int64, _ int32) (*treasury.OnChainPayment, error) {
+	if c.fail {
+		return nil, errInjected
+	}
+	return &treasury.OnChainPayment{TxHash: randomHash()}, nil
+}
+
+// ledgerPayments is a payment.Provider whose refunds are declined when decline is set.
+type ledgerPayments struct {
+	payment.Provider // embed for interface compliance
+
+	decline bool
+}
+
+func (p *ledgerPayments) Refund(_ context.Context, req payment.RefundRequest) (*payment.Refund, error) {
+	if p.decline {
+		return nil, payment.ErrRefundDeclined
+	}
+	return &payment.Refund{ID: "re_" + req.IdempotencyKey, Status: payment.RefundSucceeded}, nil
+}
+
+// fixedPrice is an exchange.PriceProvider returning a constant BTC price.
+type fixedPrice float64
+
+func (p fixedPrice) GetPrice(_ context.Context, _ string) (float64, error) {
+	return float64(p), nil
+}
+
+func randomHash() string {
+	sum := sha256.Sum256([]byte(uuid.New().String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// ledgerHarness drives one card through lifecycle operations and tracks the
+// balance it should have.
+type ledgerHarness struct {
+	svc       *Service
+	cardRepo  *database.CardRepository
+	txRepo    *database.TransactionRepository
+	custodian *ledgerCustodian
+	payments  *ledgerPayments
+
+	cardID       string
+	code         string
+	targetSats   int64
+	fundedSats   int64
+	expectedSats int64
+}
+
+// fund reserves a funding tranche the way cmd/worker/fund_card does:
+// the card's funded balance is updated, then a Fund transaction recorded.
+func (h *ledgerHarness) fund(rt *rapid.T) {
+	ctx := context.Background()
+	remaining := h.targetSats - h.fundedSats
+	if remaining <= 0 {
+		rt.Skip("card fully funded")
+	}
+	tranche := rapid.Int64Range(1, remaining).Draw(rt, "tranche")
+
+	// Redemptions may have spent part of the funded balance already
+	card, err := h.cardRepo.GetByID(ctx, h.cardID)
+	require.NoError(rt, err)
+	if card.Status != database.Created && card.Status != database.PartiallyFunded {
+		rt.Skip("card no longer funding")
+	}
+
+	funded := h.fundedSats + tranche
+	status := database.PartiallyFunded
+	var fundedAt *time.Time
+	if funded == h.targetSats {
+		status = database.Active
+		now := time.Now().UTC()
+		fundedAt = &now
+	}
+	require.NoError(rt, h.cardRepo.UpdateFunding(ctx, h.cardID, status, funded, &h.targetSats, fundedAt))
+
+	now := time.Now().UTC()
+	require.NoError(rt, h.txRepo.Create(ctx, &database.Transaction{
+		ID:            uuid.New().String(),
+		CardID:        h.cardID,
+		Type:          database.Fund,
+		BTCAmountSats: tranche,
+		Status:        database.Confirmed,
+		CreatedAt:     now,
+		ConfirmedAt:   &now,
+	}))
+
+	h.fundedSats = funded
+	h.expectedSats = funded
+}
+
+// redeem spends an arbitrary amount (possibly more than the balance) over
+// the drawn method, with the custodian failing some payments.
+func (h *ledgerHarness) redeem(rt *rapid.T, method RedeemCardMethod) {
+	amount := rapid.Int64Range(1, h.expectedSats+minOnChainAmountSats).Draw(rt, "amount")
+	h.custodian.fail = rapid.Bool().Draw(rt, "custodian_fails")
+
+	req := RedeemCardRequest{Code: h.code, Method: method, AmountSats: amount}
+	if method == Lightning {
+		req.LightningInvoice = fmt.Sprintf("lnfake:%d:%s", amount, randomHash())
+	} else {
+		req.DestinationAddress = ledgerTestAddress
+	}
+
+	resp, err := h.svc.RedeemCard(context.Background(), req)
+	if err != nil {
+		return // Rejected or failed — the balance must be untouched (checked by the invariant)
+	}
+	require.LessOrEqual(rt, resp.BTCAmountSats, h.expectedSats, "redeemed more than the balance")
+	h.expectedSats -= resp.BTCAmountSats
+}
+
+// refund cashes out the balance, with the provider declining some refunds.
+func (h *ledgerHarness) refund(rt *rapid.T) {
+	h.payments.decline = rapid.Bool().Draw(rt, "provider_declines")
+
+	resp, err := h.svc.RefundCard(context.Background(), h.code)
+	if err != nil {
+		return // Rejected or declined — the balance must be restored (checked by the invariant)
+	}
+	require.Equal(rt, h.expectedSats, resp.RefundedSats, "refund must cash out the whole balance")
+	h.expectedSats = 0
+}
+
+// check asserts the invariants after every operation.
+func (h *ledgerHarness) check(rt *rapid.T) {
+	ctx := context.Background()
+
+	card, err := h.cardRepo.GetByID(ctx, h.cardID)
+	require.NoError(rt, err)
+	require.GreaterOrEqual(rt, card.BTCAmountSats, int64(0), "negative card balance")
+	require.Equal(rt, h.expectedSats, card.BTCAmountSats, "card balance diverged from the operations applied")
+
+	txs, err := h.txRepo.ListByCardID(ctx, h.cardID)
+	require.NoError(rt, err)
+	var ledger int64
+	for _, tx := range txs {
+		if tx.Status == database.Failed {
+			continue
+		}
+		if tx.Type == database.Fund {
+			ledger += tx.BTCAmountSats
+		} else {
+			ledger -= tx.BTCAmountSats
+		}
+	}
+	require.Equal(rt, ledger, card.BTCAmountSats, "SUM(transactions) != card balance")
+
+	if card.Status == database.Redeemed {
+		require.Zero(rt, card.BTCAmountSats, "redeemed card still holds a balance")
+	}
+	if card.Status == database.Refunded {
+		require.Zero(rt, card.BTCAmountSats, "refunded card still holds a balance")
+	}
+	require.NotEqual(rt, database.Refunding, card.Status, "refund left unresolved")
+
+	discrepancies, err := h.txRepo.LedgerDiscrepancies(ctx, 10)
+	require.NoError(rt, err)
+	require.Empty(rt, discrepancies)
+}
+
+func TestCardLifecycle_LedgerInvariant(t *testing.T) {
+	db := database.SetupTestDB(t)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+
+	require.NoError(t, cache.Init(cache.Config{Host: "localhost", Port: "6379", DB: 1}))
+	defer cache.Close()
+
+	ctx := context.Background()
+	queue := streams.NewStreamQueue(cache.Client)
+	require.NoError(t, queue.DeclareStream(ctx, "fund_card", "test_workers"))
+
+	cardRepo := database.NewCardRepository(db)
+	txRepo := database.NewTransactionRepository(db)
+	custodian := &ledgerCustodian{}
+	payments := &ledgerPayments{}
+	flags := featureflag.New(map[featureflag.Flag]featureflag.Rule{
+		featureflag.InstantRefunds: {Enabled: true, Percentage: 100},
+	})
+
+	svc := NewService(Config{Network: "testnet"}, Deps{
+		Cards:        cardRepo,
+		Transactions: txRepo,
+		Queue:        queue,
+		Custodian:    custodian,
+		Flags:        flags,
+		Prices:       fixedPrice(60_000),
+		Payments:     payments,
+	})
+
+	rapid.Check(t, func(rt *rapid.T) {
+		created, err := svc.CreateCard(ctx, CreateCardRequest{
+			FiatAmountCents:    rapid.Int64Range(500, 100_000).Draw(rt, "fiat_amount_cents"),
+			FiatCurrency:       "EUR",
+			PurchasePriceCents: 100_000,
+			PurchaseEmail:      "ledger@example.com",
+		})
+		require.NoError(rt, err)
+
+		h := &ledgerHarness{
+			svc:        svc,
+			cardRepo:   cardRepo,
+			txRepo:     txRepo,
+			custodian:  custodian,
+			payments:   payments,
+			cardID:     created.CardID,
+			code:       created.Code,
+			targetSats: rapid.Int64Range(1, 5_000_000).Draw(rt, "target_sats"),
+		}
+
+		rt.Repeat(map[string]func(*rapid.T){
+			"fund":             h.fund,
+			"redeem_lightning": func(rt *rapid.T) { h.redeem(rt, Lightning) },
+			"redeem_onchain":   func(rt *rapid.T) { h.redeem(rt, OnChain) },
+			"refund":           h.refund,
+			"":                 h.check,
+		})
+	})
+}
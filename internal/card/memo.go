@@ -0,0 +1,41 @@
+package card
+
+import "btc-giftcard/internal/database"
+
+// MemoPolicy controls how a paid BOLT11 invoice is stored on its Redeem
+// transaction. The invoice carries the payee's description (memo), which
+// some destinations fill with personal data. The invoice is signed, so the
+// memo can't be cut out of it: the whole invoice is kept, hashed or dropped.
+type MemoPolicy string
+
+const (
+	MemoKeep  MemoPolicy = "keep"  // Store the invoice as is (cmd/job/archive_transactions hashes it after the retention period)
+	MemoHash  MemoPolicy = "hash"  // Store its SHA-256 (database.HashInvoice): a given invoice can still be matched
+	MemoStrip MemoPolicy = "strip" // Store nothing, the payment hash still identifies the payment
+)
+
+// ParseMemoPolicy validates a [retention] invoice_memo_policy value.
+func ParseMemoPolicy(s string) (MemoPolicy, bool) {
+	switch p := MemoPolicy(s); p {
+	case MemoKeep, MemoHash, MemoStrip:
+		return p, true
+	}
+	return "", false
+}
+
+// storedInvoice applies the memo policy to a paid invoice before it is
+// persisted. Anything but keep or strip hashes, so a typo never stores memos.
+func (s *Service) storedInvoice(invoice *string) *string {
+	if invoice == nil {
+		return nil
+	}
+	switch s.cfg.MemoPolicy {
+	case MemoKeep:
+		return invoice
+	case MemoStrip:
+		return nil
+	default:
+		hashed := database.HashInvoice(*invoice)
+		return &hashed
+	}
+}
@@ -0,0 +1,38 @@
+package card
+
+import (
+	"strings"
+	"testing"
+
+	"btc-giftcard/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoredInvoice(t *testing.T) {
+	invoice := "lntb300u1pjexample"
+
+	keep := &Service{cfg: Config{MemoPolicy: MemoKeep}}
+	assert.Equal(t, invoice, *keep.storedInvoice(&invoice))
+
+	strip := &Service{cfg: Config{MemoPolicy: MemoStrip}}
+	assert.Nil(t, strip.storedInvoice(&invoice))
+
+	for _, policy := range []MemoPolicy{MemoHash, "", "hsah"} {
+		s := &Service{cfg: Config{MemoPolicy: policy}}
+		stored := s.storedInvoice(&invoice)
+		assert.True(t, strings.HasPrefix(*stored, database.InvoiceHashPrefix), policy)
+		assert.Equal(t, database.HashInvoice(invoice), *stored, "same hash as the redaction job")
+	}
+
+	assert.Nil(t, keep.storedInvoice(nil), "on-chain redemptions have no invoice")
+}
+
+func TestParseMemoPolicy(t *testing.T) {
+	p, ok := ParseMemoPolicy("strip")
+	assert.True(t, ok)
+	assert.Equal(t, MemoStrip, p)
+
+	_, ok = ParseMemoPolicy("redact")
+	assert.False(t, ok)
+}
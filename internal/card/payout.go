@@ -0,0 +1,337 @@
+package card
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/payout"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrPayoutsDisabled     = errors.New("bank payouts are not configured")
+	ErrPayoutQuoteNotFound = errors.New("payout quote not found, expired or already used")
+	ErrPayoutTooSmall      = errors.New("payout is below the minimum amount")
+	ErrPayoutTooLarge      = errors.New("payout exceeds the maximum amount")
+)
+
+// SEPAInstant redeems a card to a bank account (see RedeemToBank).
+const SEPAInstant RedeemCardMethod = "sepa_instant"
+
+const (
+	payoutQuoteKeyPrefix = "payout_quote:"
+	payoutCurrency       = "EUR" // SEPA Instant only settles in euro
+)
+
+// PayoutConfig controls fiat payouts (populated from config.toml [payouts] section).
+type PayoutConfig struct {
+	QuoteValidity time.Duration // How long a quoted sats→EUR rate stays locked (0 disables payouts)
+	WebhookSecret string        // Verifies PSP status webhooks
+	MinCents      int64         // Minimum payout
+	MaxCents      int64         // Maximum payout per redemption (0 = no limit)
+}
+
+// PayoutQuote is a sats→EUR rate locked for one card. RedeemToBank pays out
+// FiatAmountCents for AmountSats whatever the market does in between.
+type PayoutQuote struct {
+	ID              string    `json:"id"`
+	CardID          string    `json:"card_id"`
+	AmountSats      int64     `json:"amount_sats"`
+	Price           float64   `json:"price"` // EUR per BTC
+	FiatAmountCents int64     `json:"fiat_amount_cents"`
+	Currency        string    `json:"currency"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// BankRedeemRequest contains the parameters for a SEPA Instant redemption.
+type BankRedeemRequest struct {
+	Code          string
+	QuoteID       string // From QuotePayout, fixes the amount and rate
+	IBAN          string
+	AccountHolder string // Must match the account, the receiving bank may check it
+}
+
+// BankRedeemResponse contains the payout details. The payout settles
+// asynchronously: HandlePayoutWebhook confirms or reverses it.
+type BankRedeemResponse struct {
+	TransactionID    string
+	PayoutID         string // PSP payout ID
+	BTCAmountSats    int64
+	FiatAmountCents  int64
+	Currency         string
+	RemainingBalance int64
+	Status           database.TransactionStatus
+}
+
+// QuotePayout locks the EUR value of amountSats from a card for
+// PayoutConfig.QuoteValidity. The quote is single-use.
+func (s *Service) QuotePayout(ctx context.Context, code string, amountSats int64) (*PayoutQuote, error) {
+	if s.payouts == nil || s.prices == nil || s.cfg.Payouts.QuoteValidity <= 0 {
+		return nil, ErrPayoutsDisabled
+	}
+	if amountSats <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	card, err := s.validateCardForRedemption(ctx, code, amountSats)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := s.prices.GetPrice(ctx, payoutCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BTC price: %w", err)
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("invalid BTC price: %v", price)
+	}
+
+	cents := fiatForSats(amountSats, price)
+	if cents < s.cfg.Payouts.MinCents || cents <= 0 {
+		return nil, ErrPayoutTooSmall
+	}
+	if s.cfg.Payouts.MaxCents > 0 && cents > s.cfg.Payouts.MaxCents {
+		return nil, ErrPayoutTooLarge
+	}
+
+	quote := &PayoutQuote{
+		ID:              uuid.New().String(),
+		CardID:          card.ID,
+		AmountSats:      amountSats,
+		Price:           price,
+		FiatAmountCents: cents,
+		Currency:        payoutCurrency,
+		ExpiresAt:       time.Now().UTC().Add(s.cfg.Payouts.QuoteValidity),
+	}
+
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payout quote: %w", err)
+	}
+	if err := cache.Set(ctx, payoutQuoteKeyPrefix+quote.ID, data, s.cfg.Payouts.QuoteValidity); err != nil {
+		return nil, fmt.Errorf("failed to store payout quote: %w", err)
+	}
+
+	return quote, nil
+}
+
+// fiatForSats converts sats to cents at price (fiat per BTC), rounding down.
+func fiatForSats(sats int64, price float64) int64 {
+	return int64(math.Floor(float64(sats) * price / 1_000_000)) // sats/1e8 BTC * price * 100 cents
+}
+
+// RedeemToBank pays part of a card out to a bank account over SEPA Instant at
+// the rate locked by a payout quote.
+//
+// Like RefundCard, the balance is deducted and a pending fiat_payout
+// transaction recorded BEFORE the PSP is called; if the PSP refuses the
+// payout, the balance is restored and the transaction marked failed.
+func (s *Service) RedeemToBank(ctx context.Context, req BankRedeemRequest) (*BankRedeemResponse, error) {
+	if s.payouts == nil {
+		return nil, ErrPayoutsDisabled
+	}
+
+	iban, err := payout.NormalizeIBAN(req.IBAN)
+	if err != nil {
+		return nil, err
+	}
+	if req.AccountHolder == "" {
+		return nil, errors.New("account holder is required")
+	}
+
+	// Step 1: Acquire per-card lock (shared with RedeemCard)
+	release, err := s.acquireCardLock(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Step 2: Use up the quote and validate the card
+	quote, err := s.claimPayoutQuote(ctx, req.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	card, err := s.validateCardForRedemption(ctx, req.Code, quote.AmountSats)
+	if err != nil {
+		return nil, err
+	}
+	if card.ID != quote.CardID {
+		return nil, ErrPayoutQuoteNotFound
+	}
+
+	// Step 3: Record the pending payout and deduct the card balance
+	now := time.Now().UTC()
+	method := string(SEPAInstant)
+	tx := &database.Transaction{
+		ID:               uuid.New().String(),
+		CardID:           card.ID,
+		Type:             database.FiatPayout,
+		RedemptionMethod: &method,
+		BTCAmountSats:    quote.AmountSats,
+		Status:           database.Pending,
+		CreatedAt:        now,
+		FiatAmountCents:  &quote.FiatAmountCents,
+	}
+	if err := s.txRepo.Create(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	remaining, err := s.updateCardBalance(ctx, card.ID, card.BTCAmountSats, quote.AmountSats)
+	if err != nil {
+		s.failTransaction(ctx, tx.ID)
+		return nil, err
+	}
+
+	// Step 4: Send the payout (tx ID as reference and idempotency key)
+	result, err := s.payouts.Send(ctx, payout.Request{
+		IBAN:           iban,
+		AccountHolder:  req.AccountHolder,
+		AmountCents:    quote.FiatAmountCents,
+		Currency:       quote.Currency,
+		Reference:      tx.ID,
+		IdempotencyKey: tx.ID,
+		Description:    "Gift card redemption",
+	})
+	if err != nil {
+		s.restoreCardBalance(ctx, card.ID, card.BTCAmountSats)
+		s.failTransaction(ctx, tx.ID)
+		return nil, fmt.Errorf("payout failed: %w", err)
+	}
+
+	if err := s.txRepo.SetExternalReference(ctx, tx.ID, result.ID); err != nil {
+		logger.Error("Failed to record payout ID",
+			zap.String("tx_id", tx.ID),
+			zap.String("payout_id", result.ID),
+			zap.Error(err),
+		)
+	}
+
+	// Step 5: Invalidate treasury cache (reserved balance changed)
+	s.InvalidateTreasuryCache(ctx)
+	s.emitRedemption(ctx, card, method, card.BTCAmountSats, quote.AmountSats, remaining)
+
+	logger.Info("Card redeemed to bank account",
+		zap.String("card_id", card.ID),
+		zap.String("tx_id", tx.ID),
+		zap.String("payout_id", result.ID),
+		zap.String("iban", payout.MaskIBAN(iban)),
+		zap.Int64("sats", quote.AmountSats),
+		zap.Int64("fiat_cents", quote.FiatAmountCents),
+	)
+
+	return &BankRedeemResponse{
+		TransactionID:    tx.ID,
+		PayoutID:         result.ID,
+		BTCAmountSats:    quote.AmountSats,
+		FiatAmountCents:  quote.FiatAmountCents,
+		Currency:         quote.Currency,
+		RemainingBalance: remaining,
+		Status:           tx.Status,
+	}, nil
+}
+
+// claimPayoutQuote loads a payout quote and deletes it so it can't be reused.
+func (s *Service) claimPayoutQuote(ctx context.Context, id string) (*PayoutQuote, error) {
+	if id == "" {
+		return nil, ErrPayoutQuoteNotFound
+	}
+
+	raw, err := cache.Get(ctx, payoutQuoteKeyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payout quote: %w", err)
+	}
+	if raw == "" {
+		return nil, ErrPayoutQuoteNotFound
+	}
+	var quote PayoutQuote
+	if err := json.Unmarshal([]byte(raw), &quote); err != nil {
+		return nil, fmt.Errorf("invalid payout quote %s: %w", id, err)
+	}
+	if time.Now().After(quote.ExpiresAt) {
+		return nil, ErrPayoutQuoteNotFound
+	}
+
+	// Deleting is the claim: only one caller removes the key
+	deleted, err := cache.Delete(ctx, payoutQuoteKeyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim payout quote: %w", err)
+	}
+	if deleted == 0 {
+		return nil, ErrPayoutQuoteNotFound
+	}
+	return &quote, nil
+}
+
+// HandlePayoutWebhook applies a PSP payout status webhook: a settled payout
+// confirms its transaction, a failed one marks it failed and credits the sats
+// back to the card. Webhooks for payouts that are no longer pending are
+// ignored, so redeliveries are harmless.
+func (s *Service) HandlePayoutWebhook(ctx context.Context, body []byte, signature string) error {
+	event, err := payout.ParseWebhook(s.cfg.Payouts.WebhookSecret, body, signature)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.txRepo.GetByID(ctx, event.Reference)
+	if err != nil {
+		return fmt.Errorf("failed to get payout transaction: %w", err)
+	}
+	if tx.Type != database.FiatPayout {
+		return fmt.Errorf("%w: transaction %s is not a fiat payout", payout.ErrInvalidWebhook, tx.ID)
+	}
+	if tx.Status != database.Pending {
+		return nil
+	}
+
+	switch event.Status {
+	case payout.StatusSucceeded:
+		confirmedAt := time.Now().UTC()
+		if err := s.txRepo.Update(ctx, tx.ID, database.Confirmed, 0, nil, &confirmedAt); err != nil {
+			return fmt.Errorf("failed to confirm payout transaction: %w", err)
+		}
+		logger.Info("Payout settled", zap.String("tx_id", tx.ID), zap.String("payout_id", event.PayoutID))
+
+	case payout.StatusFailed:
+		if err := s.txRepo.Update(ctx, tx.ID, database.Failed, 0, nil, nil); err != nil {
+			return fmt.Errorf("failed to mark payout transaction failed: %w", err)
+		}
+		if err := s.cardRepo.Credit(ctx, tx.CardID, tx.BTCAmountSats); err != nil {
+			// Transaction is failed but the sats are not back on the card — needs manual fix
+			logger.Error("CRITICAL: failed to credit card after payout failure",
+				zap.String("card_id", tx.CardID),
+				zap.String("tx_id", tx.ID),
+				zap.Int64("sats", tx.BTCAmountSats),
+				zap.Error(err),
+			)
+			return fmt.Errorf("failed to credit card: %w", err)
+		}
+		s.InvalidateTreasuryCache(ctx)
+		logger.Warn("Payout failed, balance credited back",
+			zap.String("tx_id", tx.ID),
+			zap.String("payout_id", event.PayoutID),
+			zap.String("reason", event.FailureReason),
+		)
+	}
+
+	return nil
+}
+
+// restoreCardBalance puts the balance back on an active card after a failed payout.
+func (s *Service) restoreCardBalance(ctx context.Context, cardID string, balanceSats int64) {
+	if err := s.cardRepo.Update(ctx, cardID, database.Active, &balanceSats, nil, nil); err != nil {
+		// Card now shows 0 sats although nothing was paid out — needs manual fix
+		logger.Error("CRITICAL: failed to restore card balance after payout failure",
+			zap.String("card_id", cardID),
+			zap.Int64("balance_sats", balanceSats),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,33 @@
+package card
+
+import (
+	"context"
+	"testing"
+
+	"btc-giftcard/internal/payout"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFiatForSats(t *testing.T) {
+	// 100,000 sats at €60,000/BTC = €60.00
+	assert.Equal(t, int64(6000), fiatForSats(100_000, 60_000))
+	// 1,234 sats at €61,234/BTC = 75.56 cents, rounded down
+	assert.Equal(t, int64(75), fiatForSats(1_234, 61_234))
+}
+
+func TestQuotePayout_Disabled(t *testing.T) {
+	s := &Service{cfg: Config{}}
+
+	_, err := s.QuotePayout(context.Background(), "GIFT-AAAA-BBBB-CCCC", 10_000)
+	assert.ErrorIs(t, err, ErrPayoutsDisabled)
+	_, err = s.RedeemToBank(context.Background(), BankRedeemRequest{Code: "GIFT-AAAA-BBBB-CCCC"})
+	assert.ErrorIs(t, err, ErrPayoutsDisabled)
+}
+
+func TestRedeemToBank_InvalidIBAN(t *testing.T) {
+	s := &Service{payouts: payout.NewPSPClient("http://psp.invalid", "key", nil)}
+
+	_, err := s.RedeemToBank(context.Background(), BankRedeemRequest{Code: "GIFT-AAAA-BBBB-CCCC", IBAN: "DE00 0000"})
+	assert.ErrorIs(t, err, payout.ErrInvalidIBAN)
+}
@@ -0,0 +1,74 @@
+package card
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"btc-giftcard/internal/database"
+)
+
+var (
+	ErrProductNotFound    = errors.New("product not found")
+	ErrProductUnavailable = errors.New("product is not available")
+	ErrProductMismatch    = errors.New("amount or currency does not match the product")
+)
+
+// ListProducts returns the catalog products customers can currently buy.
+func (s *Service) ListProducts(ctx context.Context) ([]*database.CardProduct, error) {
+	if s.products == nil {
+		return nil, nil
+	}
+	return s.products.List(ctx, true)
+}
+
+// applyProduct loads the request's catalog product and fills in its face value,
+// currency and price. Amounts the caller already set must agree with the
+// product, so a stale checkout page can't buy a product at an old price.
+func (s *Service) applyProduct(ctx context.Context, req *CreateCardRequest) (*database.CardProduct, error) {
+	product, err := s.getProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsAvailable {
+		return nil, ErrProductUnavailable
+	}
+
+	if err := applyProductPricing(req, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// getProduct loads a catalog product. Returns ErrProductNotFound if missing.
+func (s *Service) getProduct(ctx context.Context, id string) (*database.CardProduct, error) {
+	if s.products == nil {
+		return nil, ErrProductNotFound
+	}
+
+	product, err := s.products.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrProductNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to load product: %w", err)
+	}
+	return product, nil
+}
+
+// applyProductPricing sets the request's amounts from product, rejecting any
+// that were set to something else.
+func applyProductPricing(req *CreateCardRequest, product *database.CardProduct) error {
+	price := product.PurchasePriceCents()
+	if (req.FiatAmountCents != 0 && req.FiatAmountCents != product.FiatAmountCents) ||
+		(req.FiatCurrency != "" && !strings.EqualFold(req.FiatCurrency, product.FiatCurrency)) ||
+		(req.PurchasePriceCents != 0 && req.PurchasePriceCents != price) {
+		return ErrProductMismatch
+	}
+
+	req.FiatAmountCents = product.FiatAmountCents
+	req.FiatCurrency = product.FiatCurrency
+	req.PurchasePriceCents = price
+	return nil
+}
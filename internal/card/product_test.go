@@ -0,0 +1,33 @@
+package card
+
+import (
+	"testing"
+
+	"btc-giftcard/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProductPricing(t *testing.T) {
+	// €50 with a 2% + €0.50 fee = €51.50
+	product := &database.CardProduct{FiatAmountCents: 5000, FiatCurrency: "EUR", FeeBps: 200, FeeFixedCents: 50}
+
+	req := CreateCardRequest{}
+	require.NoError(t, applyProductPricing(&req, product))
+	assert.Equal(t, int64(5000), req.FiatAmountCents)
+	assert.Equal(t, "EUR", req.FiatCurrency)
+	assert.Equal(t, int64(5150), req.PurchasePriceCents)
+
+	// Matching amounts the caller already set are accepted
+	req = CreateCardRequest{FiatAmountCents: 5000, FiatCurrency: "eur", PurchasePriceCents: 5150}
+	assert.NoError(t, applyProductPricing(&req, product))
+
+	for name, req := range map[string]CreateCardRequest{
+		"face value": {FiatAmountCents: 10000},
+		"currency":   {FiatCurrency: "USD"},
+		"price":      {PurchasePriceCents: 5000},
+	} {
+		assert.ErrorIs(t, applyProductPricing(&req, product), ErrProductMismatch, name)
+	}
+}
@@ -0,0 +1,173 @@
+package card
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"btc-giftcard/pkg/cache"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrQuoteNotFound  = errors.New("rate quote not found or expired")
+	ErrQuoteMismatch  = errors.New("rate quote does not match the card amount or currency")
+	ErrQuoteUsed      = errors.New("rate quote was already used for another card")
+	ErrQuotesDisabled = errors.New("rate quotes are not configured")
+)
+
+const quoteKeyPrefix = "quote:"
+
+// QuoteConfig controls checkout rate locking
+// (populated from config.toml [quotes] section).
+type QuoteConfig struct {
+	Provider string        // Price provider name recorded on each quote (e.g. "coinbase")
+	Validity time.Duration // How long a quoted rate stays locked
+	GuardBps int64         // Max drift (basis points) between the quoted and funding-time price
+}
+
+// RateQuote is a BTC rate locked at checkout. The frontend shows BTCAmountSats;
+// CreateCard binds the quote to the card it creates, and the fund worker
+// honors Price for that card only, if the quote is still valid and the market
+// hasn't moved more than GuardBps since (see Honors).
+type RateQuote struct {
+	ID              string    `json:"id"`
+	CardID          string    `json:"card_id,omitempty"` // Card the quote was used for (empty until CreateCard)
+	Provider        string    `json:"provider"`
+	FiatAmountCents int64     `json:"fiat_amount_cents"`
+	FiatCurrency    string    `json:"fiat_currency"`
+	Price           float64   `json:"price"` // Fiat per BTC
+	BTCAmountSats   int64     `json:"btc_amount_sats"`
+	GuardBps        int64     `json:"guard_bps"`
+	CreatedAt       time.Time `json:"created_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// SatsForFiat converts a fiat amount in cents to satoshis at price (fiat per BTC).
+func SatsForFiat(fiatAmountCents int64, price float64) int64 {
+	btcAmount := float64(fiatAmountCents) / 100.0 / price
+	return int64(btcAmount * 100_000_000)
+}
+
+// QuoteRate prices fiatAmountCents at the current rate and locks it for
+// QuoteConfig.Validity. The quote ID can be passed to CreateCard.
+func (s *Service) QuoteRate(ctx context.Context, fiatAmountCents int64, fiatCurrency string) (*RateQuote, error) {
+	if s.prices == nil || s.cfg.Quotes.Validity <= 0 {
+		return nil, ErrQuotesDisabled
+	}
+	if fiatAmountCents <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	fiatCurrency = strings.ToUpper(fiatCurrency)
+
+	price, err := s.prices.GetPrice(ctx, fiatCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching BTC price: %w", err)
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("invalid BTC price: %v", price)
+	}
+
+	now := time.Now().UTC()
+	quote := &RateQuote{
+		ID:              uuid.New().String(),
+		Provider:        s.cfg.Quotes.Provider,
+		FiatAmountCents: fiatAmountCents,
+		FiatCurrency:    fiatCurrency,
+		Price:           price,
+		BTCAmountSats:   SatsForFiat(fiatAmountCents, price),
+		GuardBps:        s.cfg.Quotes.GuardBps,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(s.cfg.Quotes.Validity),
+	}
+
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rate quote: %w", err)
+	}
+	if err := cache.Set(ctx, quoteKeyPrefix+quote.ID, data, s.cfg.Quotes.Validity); err != nil {
+		return nil, fmt.Errorf("failed to store rate quote: %w", err)
+	}
+
+	return quote, nil
+}
+
+// GetQuote loads a locked rate quote. Returns ErrQuoteNotFound once it has expired.
+func GetQuote(ctx context.Context, id string) (*RateQuote, error) {
+	raw, err := cache.Get(ctx, quoteKeyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate quote: %w", err)
+	}
+	if raw == "" {
+		return nil, ErrQuoteNotFound
+	}
+
+	var quote RateQuote
+	if err := json.Unmarshal([]byte(raw), &quote); err != nil {
+		return nil, fmt.Errorf("invalid rate quote %s: %w", id, err)
+	}
+	if time.Now().After(quote.ExpiresAt) {
+		return nil, ErrQuoteNotFound
+	}
+	return &quote, nil
+}
+
+// Matches reports whether the quote was issued for this amount and currency.
+func (q *RateQuote) Matches(fiatAmountCents int64, fiatCurrency string) bool {
+	return q.FiatAmountCents == fiatAmountCents && strings.EqualFold(q.FiatCurrency, fiatCurrency)
+}
+
+// Honors reports whether the quoted price can still be used at time now given
+// the current market price from provider: the quote must not have expired, come
+// from the same provider, and be within GuardBps of currentPrice.
+func (q *RateQuote) Honors(provider string, currentPrice float64, now time.Time) bool {
+	if now.After(q.ExpiresAt) || !strings.EqualFold(q.Provider, provider) || currentPrice <= 0 {
+		return false
+	}
+	driftBps := math.Abs(currentPrice-q.Price) / q.Price * 10_000
+	return driftBps <= float64(q.GuardBps)
+}
+
+// claimQuote binds a checkout quote to cardID, after checking it was issued
+// for this amount and currency. A quote can be used for a single card:
+// deleting the unbound quote is the claim, so of two concurrent checkouts
+// only one gets it, and the bound copy stored back carries the card ID.
+func (s *Service) claimQuote(ctx context.Context, quoteID, cardID string, fiatAmountCents int64, fiatCurrency string) error {
+	quote, err := GetQuote(ctx, quoteID)
+	if err != nil {
+		return err
+	}
+	if quote.CardID != "" {
+		return ErrQuoteUsed
+	}
+	if !quote.Matches(fiatAmountCents, fiatCurrency) {
+		return ErrQuoteMismatch
+	}
+
+	deleted, err := cache.Delete(ctx, quoteKeyPrefix+quoteID)
+	if err != nil {
+		return fmt.Errorf("failed to claim rate quote: %w", err)
+	}
+	if deleted == 0 {
+		return ErrQuoteUsed
+	}
+
+	quote.CardID = cardID
+	ttl := time.Until(quote.ExpiresAt)
+	if ttl <= 0 {
+		return ErrQuoteNotFound
+	}
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rate quote: %w", err)
+	}
+	if err := cache.Set(ctx, quoteKeyPrefix+quoteID, data, ttl); err != nil {
+		return fmt.Errorf("failed to store rate quote: %w", err)
+	}
+	return nil
+}
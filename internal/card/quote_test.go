@@ -0,0 +1,43 @@
+package card
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSatsForFiat(t *testing.T) {
+	// €100 at €67,000/BTC = 149,253.73 sats → 149,253
+	assert.Equal(t, int64(149_253), SatsForFiat(10_000, 67_000))
+}
+
+func TestRateQuote_Honors(t *testing.T) {
+	now := time.Now()
+	q := &RateQuote{Provider: "coinbase", Price: 60_000, GuardBps: 100, ExpiresAt: now.Add(time.Minute)}
+
+	assert.True(t, q.Honors("coinbase", 60_000, now), "unchanged price")
+	assert.True(t, q.Honors("Coinbase", 60_600, now), "+1% is within the guard")
+	assert.True(t, q.Honors("coinbase", 59_400, now), "-1% is within the guard")
+	assert.False(t, q.Honors("coinbase", 60_601, now), "beyond the guard")
+	assert.False(t, q.Honors("coinbase", 59_399, now), "beyond the guard on the way down")
+	assert.False(t, q.Honors("bitstamp", 60_000, now), "different provider")
+	assert.False(t, q.Honors("coinbase", 60_000, now.Add(2*time.Minute)), "expired")
+	assert.False(t, q.Honors("coinbase", 0, now), "no current price")
+}
+
+func TestRateQuote_Matches(t *testing.T) {
+	q := &RateQuote{FiatAmountCents: 5000, FiatCurrency: "EUR"}
+
+	assert.True(t, q.Matches(5000, "eur"))
+	assert.False(t, q.Matches(5001, "EUR"))
+	assert.False(t, q.Matches(5000, "USD"))
+}
+
+func TestQuoteRate_Disabled(t *testing.T) {
+	s := &Service{cfg: Config{}}
+
+	_, err := s.QuoteRate(context.Background(), 5000, "EUR")
+	assert.ErrorIs(t, err, ErrQuotesDisabled)
+}
@@ -0,0 +1,226 @@
+package card
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/featureflag"
+	"btc-giftcard/internal/payment"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrRefundsDisabled = errors.New("refunds are not enabled for this card")
+	ErrRefundTooSmall  = errors.New("remaining balance is below the minimum refund after fees")
+	ErrRefundTooLarge  = errors.New("refund exceeds the maximum allowed amount")
+)
+
+// RefundConfig is the refund fee schedule and compliance limits
+// (populated from config.toml [refunds] section).
+type RefundConfig struct {
+	FeeBps        int64 // Percentage fee in basis points (100 = 1%)
+	FeeFixedCents int64 // Flat fee per refund
+	MinCents      int64 // Minimum net refund (smaller balances can't be cashed out)
+	MaxCents      int64 // Maximum net refund per card (0 = no limit)
+}
+
+// RefundCardResponse contains the refund details
+type RefundCardResponse struct {
+	TransactionID string
+	RefundID      string // Payment provider refund ID
+	RefundedSats  int64  // Sats deducted from the card (the whole remaining balance)
+	GrossCents    int64  // Fiat value of the refunded sats (capped at face value)
+	FeeCents      int64
+	NetCents      int64 // Amount returned to the original payment method
+	Currency      string
+	Status        payment.RefundStatus
+}
+
+// RefundQuote is the fiat breakdown for cashing out a card balance.
+type RefundQuote struct {
+	GrossCents int64
+	FeeCents   int64
+	NetCents   int64
+}
+
+// quoteRefund converts sats to fiat at price (fiat per BTC) and applies the fee
+// schedule. The gross amount is capped at the card's face value: a refund
+// returns the purchase, never BTC price gains.
+func (s *Service) quoteRefund(sats int64, price float64, faceValueCents int64) (*RefundQuote, error) {
+	gross := int64(math.Floor(float64(sats) * price / 1_000_000)) // sats/1e8 BTC * price * 100 cents
+	if gross > faceValueCents {
+		gross = faceValueCents
+	}
+
+	fee := s.cfg.Refunds.FeeFixedCents + gross*s.cfg.Refunds.FeeBps/10_000
+	net := gross - fee
+
+	if net <= 0 || net < s.cfg.Refunds.MinCents {
+		return nil, ErrRefundTooSmall
+	}
+	if s.cfg.Refunds.MaxCents > 0 && net > s.cfg.Refunds.MaxCents {
+		return nil, ErrRefundTooLarge
+	}
+
+	return &RefundQuote{GrossCents: gross, FeeCents: fee, NetCents: net}, nil
+}
+
+// RefundCard cashes out a card's remaining balance to the original payment
+// method at the current BTC price, minus the refund fee.
+//
+// Reserve → refund → commit: a pending Refund transaction is recorded and the
+// card moved to 'refunding' BEFORE the provider is called, so the sats can't
+// be spent concurrently but stay on the card (and reserved). Once the provider
+// accepts, the balance is zeroed and the card marked 'refunded'; if it
+// declines, the card goes back to 'active' and the transaction is marked failed.
+func (s *Service) RefundCard(ctx context.Context, code string) (*RefundCardResponse, error) {
+	// Step 1: Acquire per-card lock (shared with RedeemCard)
+	release, err := s.acquireCardLock(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Step 2: Retrieve and validate card
+	card, err := s.GetCardByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if card.Status != database.Active {
+		return nil, ErrCardNotActive
+	}
+	if card.BTCAmountSats <= 0 {
+		return nil, ErrInsufficientFunds
+	}
+	if !s.FeatureEnabled(ctx, featureflag.InstantRefunds, card.ID, merchantOf(card)) {
+		return nil, ErrRefundsDisabled
+	}
+
+	// Step 3: Price the remaining balance
+	price, err := s.prices.GetPrice(ctx, card.FiatCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch BTC price: %w", err)
+	}
+	quote, err := s.quoteRefund(card.BTCAmountSats, price, card.FiatAmountCents)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 4: Record pending refund and reserve the balance
+	now := time.Now().UTC()
+	tx := &database.Transaction{
+		ID:              uuid.New().String(),
+		CardID:          card.ID,
+		Type:            database.Refund,
+		BTCAmountSats:   card.BTCAmountSats,
+		Status:          database.Pending,
+		CreatedAt:       now,
+		FiatAmountCents: &quote.NetCents,
+	}
+	if err := s.txRepo.Create(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	reserved, err := s.cardRepo.ReserveRefund(ctx, card.ID, card.BTCAmountSats)
+	if err != nil || !reserved {
+		s.failTransaction(ctx, tx.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrCardNotActive // Spent or refunded since it was read
+	}
+
+	// Step 5: Refund via the payment provider (tx ID as idempotency key)
+	refund, err := s.payments.Refund(ctx, payment.RefundRequest{
+		CardID:         card.ID,
+		AmountCents:    quote.NetCents,
+		Currency:       card.FiatCurrency,
+		IdempotencyKey: tx.ID,
+		Reason:         "card balance cash-out",
+	})
+	if err != nil {
+		// Compensate: nothing was refunded, make the balance spendable again
+		if releaseErr := s.cardRepo.ReleaseRefund(ctx, card.ID); releaseErr != nil {
+			logger.Error("CRITICAL: failed to release card after refund failure",
+				zap.String("card_id", card.ID),
+				zap.Int64("balance_sats", card.BTCAmountSats),
+				zap.Error(releaseErr),
+			)
+		}
+		s.failTransaction(ctx, tx.ID)
+		return nil, fmt.Errorf("refund failed: %w", err)
+	}
+
+	// Step 6: Commit — zero the balance and confirm the transaction
+	if err := s.cardRepo.CommitRefund(ctx, card.ID); err != nil {
+		// Refund paid but the card still shows its balance (not spendable while refunding) — needs manual fix
+		logger.Error("CRITICAL: refund accepted but card not marked refunded",
+			zap.String("card_id", card.ID),
+			zap.String("refund_id", refund.ID),
+			zap.Error(err),
+		)
+	}
+	if err := s.txRepo.SetExternalReference(ctx, tx.ID, refund.ID); err != nil {
+		logger.Error("Failed to record refund ID",
+			zap.String("tx_id", tx.ID),
+			zap.String("refund_id", refund.ID),
+			zap.Error(err),
+		)
+	}
+	confirmedAt := time.Now().UTC()
+	if err := s.txRepo.Update(ctx, tx.ID, database.Confirmed, 0, &now, &confirmedAt); err != nil {
+		logger.Error("Failed to confirm refund transaction",
+			zap.String("tx_id", tx.ID),
+			zap.Error(err),
+		)
+	}
+
+	// Step 7: Invalidate treasury cache (reserved balance changed)
+	s.InvalidateTreasuryCache(ctx)
+
+	logger.Info("Card refunded",
+		zap.String("card_id", card.ID),
+		zap.String("tx_id", tx.ID),
+		zap.String("refund_id", refund.ID),
+		zap.Int64("sats", card.BTCAmountSats),
+		zap.Int64("net_cents", quote.NetCents),
+		zap.Int64("fee_cents", quote.FeeCents),
+		zap.String("currency", card.FiatCurrency),
+	)
+
+	return &RefundCardResponse{
+		TransactionID: tx.ID,
+		RefundID:      refund.ID,
+		RefundedSats:  card.BTCAmountSats,
+		GrossCents:    quote.GrossCents,
+		FeeCents:      quote.FeeCents,
+		NetCents:      quote.NetCents,
+		Currency:      card.FiatCurrency,
+		Status:        refund.Status,
+	}, nil
+}
+
+// merchantOf returns the merchant a card was sold through ("" if none), the
+// merchant feature flags are evaluated for.
+func merchantOf(card *database.Card) string {
+	if card.MerchantID == nil {
+		return ""
+	}
+	return *card.MerchantID
+}
+
+// failTransaction marks a transaction failed (best-effort, logged on error).
+func (s *Service) failTransaction(ctx context.Context, txID string) {
+	if err := s.txRepo.Update(ctx, txID, database.Failed, 0, nil, nil); err != nil {
+		logger.Error("Failed to mark transaction failed",
+			zap.String("tx_id", txID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,51 @@
+package card
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteRefund(t *testing.T) {
+	s := &Service{cfg: Config{Refunds: RefundConfig{FeeBps: 200, FeeFixedCents: 50, MinCents: 100}}}
+
+	// 100,000 sats at €60,000/BTC = €60.00; fee = €0.50 + 2% (€1.20)
+	quote, err := s.quoteRefund(100_000, 60_000, 10_000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6000), quote.GrossCents)
+	assert.Equal(t, int64(170), quote.FeeCents)
+	assert.Equal(t, int64(5830), quote.NetCents)
+}
+
+func TestQuoteRefund_CappedAtFaceValue(t *testing.T) {
+	s := &Service{cfg: Config{}}
+
+	// BTC doubled since purchase: €120 of sats on a €60 card refunds €60
+	quote, err := s.quoteRefund(100_000, 120_000, 6000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6000), quote.GrossCents)
+	assert.Equal(t, int64(6000), quote.NetCents)
+}
+
+func TestQuoteRefund_RoundsDown(t *testing.T) {
+	s := &Service{cfg: Config{}}
+
+	// 1,234 sats at $65,432.10 = 80.74 cents → 80
+	quote, err := s.quoteRefund(1_234, 65_432.10, 10_000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(80), quote.GrossCents)
+}
+
+func TestQuoteRefund_Limits(t *testing.T) {
+	s := &Service{cfg: Config{Refunds: RefundConfig{FeeFixedCents: 50, MinCents: 500, MaxCents: 10_000}}}
+
+	_, err := s.quoteRefund(1_000, 60_000, 10_000) // €0.60 gross, €0.10 net
+	assert.ErrorIs(t, err, ErrRefundTooSmall)
+
+	_, err = s.quoteRefund(50, 60_000, 10_000) // €0.03 gross, below the fixed fee
+	assert.ErrorIs(t, err, ErrRefundTooSmall)
+
+	_, err = s.quoteRefund(1_000_000, 60_000, 100_000) // €600 net
+	assert.ErrorIs(t, err, ErrRefundTooLarge)
+}
@@ -0,0 +1,212 @@
+package card
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/payment"
+	messages "btc-giftcard/internal/queue"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidBatch      = errors.New("invalid batch: retailer, a quantity of 1-10000 and a product or amount are required")
+	ErrRetailDisabled    = errors.New("retail activation is not configured")
+	ErrCardNotInactive   = errors.New("card is not an inactive retail card")
+	ErrAuthorizationID   = errors.New("POS authorization ID is required")
+	errBatchCodesCollide = errors.New("batch codes collide with existing cards")
+)
+
+// maxBatchQuantity caps a batch so its codes are inserted in one statement
+const maxBatchQuantity = 10_000
+
+// GenerateBatchRequest contains the parameters for a batch of retail cards.
+// Either ProductID or FiatAmountCents/FiatCurrency sets the face value.
+type GenerateBatchRequest struct {
+	Retailer        string
+	ProductID       string // Optional catalog product the cards are sold as
+	FiatAmountCents int64  // Face value (ignored if ProductID is set)
+	FiatCurrency    string
+	Quantity        int
+}
+
+// ActivateCardRequest contains the parameters for activating a retail card at the POS.
+type ActivateCardRequest struct {
+	Code            string // Printed code scanned at the till
+	AuthorizationID string // POS payment authorization to capture
+	PurchaseEmail   string // Optional buyer email for the receipt
+}
+
+// GenerateBatch creates Quantity inactive cards with zero balance for retail
+// distribution and returns the batch and its codes for printing. The cards
+// can't be redeemed until ActivateCard captures their payment and funds them.
+func (s *Service) GenerateBatch(ctx context.Context, req GenerateBatchRequest) (*database.CardBatch, []string, error) {
+	if s.batches == nil {
+		return nil, nil, ErrRetailDisabled
+	}
+	if req.Retailer == "" || req.Quantity <= 0 || req.Quantity > maxBatchQuantity {
+		return nil, nil, ErrInvalidBatch
+	}
+
+	batch := &database.CardBatch{
+		ID:              uuid.New().String(),
+		Retailer:        req.Retailer,
+		FiatAmountCents: req.FiatAmountCents,
+		FiatCurrency:    strings.ToUpper(req.FiatCurrency),
+		Quantity:        req.Quantity,
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	if req.ProductID != "" {
+		product, err := s.getProduct(ctx, req.ProductID)
+		if err != nil {
+			return nil, nil, err
+		}
+		batch.ProductID = &product.ID
+		batch.FiatAmountCents = product.FiatAmountCents
+		batch.FiatCurrency = product.FiatCurrency
+	}
+	if batch.FiatAmountCents <= 0 || len(batch.FiatCurrency) != 3 {
+		return nil, nil, ErrInvalidBatch
+	}
+
+	// A collision with an existing card fails the whole insert; with 31^12
+	// possible codes a retry with fresh codes practically always succeeds.
+	for attempt := 0; attempt < 3; attempt++ {
+		cardIDs, codes, err := newBatchCodes(req.Quantity)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = s.batches.Create(ctx, batch, cardIDs, codes)
+		if errors.Is(err, database.ErrCardCodeExists) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		logger.Info("Retail card batch generated",
+			zap.String("batch_id", batch.ID),
+			zap.String("retailer", batch.Retailer),
+			zap.Int("quantity", batch.Quantity),
+			zap.Int64("fiat_amount_cents", batch.FiatAmountCents),
+		)
+		return batch, codes, nil
+	}
+
+	return nil, nil, errBatchCodesCollide
+}
+
+// newBatchCodes returns n card IDs and n distinct random codes.
+func newBatchCodes(n int) ([]string, []string, error) {
+	cardIDs := make([]string, 0, n)
+	codes := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+
+	for len(codes) < n {
+		code, err := randomCardCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		cardIDs = append(cardIDs, uuid.New().String())
+		codes = append(codes, code)
+	}
+
+	return cardIDs, codes, nil
+}
+
+// ActivateCard activates a pre-generated retail card at the point of sale:
+// it captures the POS payment, moves the card from Inactive to Created and
+// queues it for funding like an online purchase.
+//
+// The capture uses the card ID as idempotency key, so if activation fails
+// after the capture, retrying the same activation never charges twice.
+func (s *Service) ActivateCard(ctx context.Context, req ActivateCardRequest) (*CreateCardResponse, error) {
+	// Step 1: Validate input
+	if s.payments == nil {
+		return nil, ErrRetailDisabled
+	}
+	if req.AuthorizationID == "" {
+		return nil, ErrAuthorizationID
+	}
+
+	// Step 2: Acquire per-card lock (shared with RedeemCard)
+	release, err := s.acquireCardLock(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Step 3: Retrieve and validate card
+	card, err := s.GetCardByCode(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if card.Status != database.Inactive {
+		return nil, ErrCardNotInactive
+	}
+
+	// Step 4: Price the card (product price including fees, else face value)
+	priceCents := card.FiatAmountCents
+	if card.ProductID != nil {
+		product, err := s.getProduct(ctx, *card.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		priceCents = product.PurchasePriceCents()
+	}
+
+	// Step 5: Capture the POS payment
+	capture, err := s.payments.Capture(ctx, payment.CaptureRequest{
+		CardID:          card.ID,
+		AuthorizationID: req.AuthorizationID,
+		AmountCents:     priceCents,
+		Currency:        card.FiatCurrency,
+		IdempotencyKey:  card.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture payment: %w", err)
+	}
+
+	// Step 6: Activate and queue for funding
+	now := time.Now().UTC()
+	if err := s.cardRepo.Activate(ctx, card.ID, req.PurchaseEmail, priceCents, now); err != nil {
+		if errors.Is(err, database.ErrCardNotInactive) {
+			return nil, ErrCardNotInactive
+		}
+		return nil, err
+	}
+
+	s.publishFundCard(ctx, messages.FundCardMessage{
+		CardID:          card.ID,
+		FiatAmountCents: card.FiatAmountCents,
+		FiatCurrency:    card.FiatCurrency,
+	})
+
+	logger.Info("Retail card activated",
+		zap.String("card_id", card.ID),
+		zap.Stringp("batch_id", card.BatchID),
+		zap.String("charge_id", capture.ID),
+		zap.Int64("price_cents", priceCents),
+	)
+
+	return &CreateCardResponse{
+		CardID:        card.ID,
+		Code:          card.Code,
+		BTCAmountSats: card.BTCAmountSats,
+		Status:        database.Created,
+		CreatedAt:     card.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package card
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBatchCodes(t *testing.T) {
+	cardIDs, codes, err := newBatchCodes(500)
+	require.NoError(t, err)
+	require.Len(t, cardIDs, 500)
+	require.Len(t, codes, 500)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.True(t, strings.HasPrefix(code, "GIFT-"), code)
+		assert.Len(t, code, len("GIFT-XXXX-YYYY-ZZZZ"))
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+	}
+}
+
+func TestGenerateBatch_Validation(t *testing.T) {
+	ctx := context.Background()
+
+	_, _, err := (&Service{}).GenerateBatch(ctx, GenerateBatchRequest{Retailer: "acme", Quantity: 10, FiatAmountCents: 2500, FiatCurrency: "EUR"})
+	assert.ErrorIs(t, err, ErrRetailDisabled, "no batch repository")
+}
+
+func TestActivateCard_Validation(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := (&Service{}).ActivateCard(ctx, ActivateCardRequest{Code: "GIFT-AAAA-BBBB-CCCC", AuthorizationID: "auth_1"})
+	assert.ErrorIs(t, err, ErrRetailDisabled, "no payment provider")
+}
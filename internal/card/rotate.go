@@ -0,0 +1,123 @@
+package card
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"btc-giftcard/internal/database"
+	messages "btc-giftcard/internal/queue"
+	"btc-giftcard/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCodeRotated         = errors.New("card code has been replaced after a suspected exposure; use the new code sent to the card owner")
+	ErrCodeRotationBlocked = errors.New("card is redeemed, refunded or expired; its code can't be rotated")
+)
+
+// RotateCodeResponse carries the card's new code.
+type RotateCodeResponse struct {
+	CardID    string
+	Code      string
+	RotatedAt time.Time
+}
+
+// RotateCode issues a new code for a card whose code may have leaked. The
+// old code is retired in the same statement that sets the new one (and kept
+// in retired_card_codes for audit); from then on redeeming, refunding or
+// activating with it fails with ErrCodeRotated. The balance is unchanged.
+//
+// The rotation takes the card lock of the old code, so a redemption already
+// in progress with it finishes first. The owner is emailed the new code via
+// a CodeRotatedMessage; if that publish fails the rotation still stands and
+// support relays the returned code.
+func (s *Service) RotateCode(ctx context.Context, cardID string) (*RotateCodeResponse, error) {
+	card, err := s.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		if errors.Is(err, database.ErrCardNotFound) {
+			return nil, ErrCardNotFound
+		}
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+
+	release, err := s.acquireCardLock(ctx, card.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Re-read under the lock: a redemption may have emptied the card meanwhile
+	if card, err = s.cardRepo.GetByID(ctx, cardID); err != nil {
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+	switch card.Status {
+	case database.Redeemed, database.Refunded, database.Expired:
+		return nil, ErrCodeRotationBlocked
+	}
+
+	code, err := s.generateCardCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate card code: %w", err)
+	}
+
+	now := time.Now().UTC()
+	rotated, err := s.cardRepo.RotateCode(ctx, card.ID, card.Code, code, now)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, ErrCodeRotated // Rotated by a concurrent request (under another lock key)
+	}
+
+	logger.Info("Card code rotated",
+		zap.String("card_id", card.ID),
+		zap.String("status", string(card.Status)),
+	)
+
+	s.publishCodeRotated(ctx, messages.CodeRotatedMessage{
+		CardID:     card.ID,
+		OwnerEmail: card.OwnerEmail,
+		Code:       code,
+		RotatedAt:  now,
+	})
+
+	return &RotateCodeResponse{CardID: card.ID, Code: code, RotatedAt: now}, nil
+}
+
+// publishCodeRotated emails the owner their new code. Failures are logged,
+// not returned: the code is already rotated.
+func (s *Service) publishCodeRotated(ctx context.Context, msg messages.CodeRotatedMessage) {
+	msgJSON, err := msg.ToJSON()
+	if err != nil {
+		logger.Error("Failed to serialize CodeRotatedMessage", zap.String("card_id", msg.CardID), zap.Error(err))
+		return
+	}
+
+	if _, err := s.queue.Publish(ctx, "card_notifications", msgJSON); err != nil {
+		logger.Error("Failed to notify owner of rotated card code",
+			zap.String("card_id", msg.CardID),
+			zap.Error(err),
+		)
+	}
+}
+
+// retiredCodeError returns ErrCodeRotated if code was retired by RotateCode,
+// and ErrCardNotFound otherwise.
+func (s *Service) retiredCodeError(ctx context.Context, code string) error {
+	retired, err := s.cardRepo.GetRetiredCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, database.ErrRetiredCodeNotFound) {
+			return ErrCardNotFound
+		}
+		return fmt.Errorf("failed to get card: %w", err)
+	}
+
+	logger.Warn("Attempt to use a rotated card code",
+		zap.String("card_id", retired.CardID),
+		zap.Time("retired_at", retired.RetiredAt),
+	)
+	return ErrCodeRotated
+}
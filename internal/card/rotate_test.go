@@ -0,0 +1,90 @@
+//go:build integration
+
+package card
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/database"
+	messages "btc-giftcard/internal/queue"
+	"btc-giftcard/pkg/cache"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RotateCode(t *testing.T) {
+	db := database.SetupTestDB(t)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+
+	require.NoError(t, cache.Init(cache.Config{Host: "localhost", Port: "6379", DB: 1}))
+	defer cache.Close()
+
+	ctx := context.Background()
+	cache.Client.Del(ctx, "card_notifications")
+	cardRepo := database.NewCardRepository(db)
+	svc := NewService(Config{Network: "testnet"}, Deps{
+		Cards:        cardRepo,
+		Transactions: database.NewTransactionRepository(db),
+		Queue:        streams.NewStreamQueue(cache.Client),
+		Custodian:    &ledgerCustodian{},
+	})
+
+	now := time.Now().UTC()
+	balance := int64(100_000)
+	card := &database.Card{
+		ID:                 uuid.New().String(),
+		PurchaseEmail:      "buyer@example.com",
+		OwnerEmail:         "owner@example.com",
+		Code:               "GIFT-ROTA-" + uuid.New().String()[:9],
+		FiatAmountCents:    5_000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5_000,
+		Status:             database.Created,
+		CreatedAt:          now,
+	}
+	require.NoError(t, cardRepo.Create(ctx, card))
+	require.NoError(t, cardRepo.UpdateFunding(ctx, card.ID, database.Active, balance, &balance, &now))
+
+	rotated, err := svc.RotateCode(ctx, card.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, card.Code, rotated.Code)
+
+	// Same card and balance under the new code
+	got, err := svc.GetCardByCode(ctx, rotated.Code)
+	require.NoError(t, err)
+	assert.Equal(t, card.ID, got.ID)
+	assert.Equal(t, balance, got.BTCAmountSats)
+
+	// The old code is blocked with a specific error, and kept for audit
+	_, err = svc.RedeemCard(ctx, RedeemCardRequest{Code: card.Code, Method: OnChain, AmountSats: 20_000, DestinationAddress: ledgerTestAddress})
+	assert.ErrorIs(t, err, ErrCodeRotated)
+	_, err = svc.ValidateCardCode(ctx, card.Code)
+	assert.ErrorIs(t, err, ErrCodeRotated)
+	retired, err := cardRepo.GetRetiredCode(ctx, card.Code)
+	require.NoError(t, err)
+	assert.Equal(t, card.ID, retired.CardID)
+
+	// Unknown codes are still just not found
+	_, err = svc.GetCardByCode(ctx, "GIFT-NOPE-NOPE-NOPE")
+	assert.ErrorIs(t, err, ErrCardNotFound)
+
+	// The owner is sent the new code
+	entries, err := cache.Client.XRange(ctx, "card_notifications", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	msg, err := messages.FromJSONCodeRotated([]byte(entries[0].Values["data"].(string)))
+	require.NoError(t, err)
+	assert.Equal(t, "owner@example.com", msg.OwnerEmail)
+	assert.Equal(t, rotated.Code, msg.Code)
+
+	// A redeemed card has nothing left to protect
+	require.NoError(t, cardRepo.Update(ctx, card.ID, database.Redeemed, new(int64), nil, &now))
+	_, err = svc.RotateCode(ctx, card.ID)
+	assert.ErrorIs(t, err, ErrCodeRotationBlocked)
+}
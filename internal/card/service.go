@@ -1,12 +1,18 @@
 package card
 
 import (
-	"btc-giftcard/internal/lnd"
+	"btc-giftcard/internal/analytics"
+	"btc-giftcard/internal/exchange"
+	"btc-giftcard/internal/featureflag"
+	"btc-giftcard/internal/payment"
+	"btc-giftcard/internal/payout"
 	messages "btc-giftcard/internal/queue"
+	"btc-giftcard/internal/treasury"
 	"btc-giftcard/internal/wallet"
 	"btc-giftcard/pkg/cache"
 	streams "btc-giftcard/pkg/queue"
 
+	"btc-giftcard/internal/chaos"
 	"btc-giftcard/internal/database"
 	"btc-giftcard/pkg/logger"
 	"context"
@@ -31,6 +37,8 @@ var (
 	ErrInvalidMethod       = errors.New("invalid redeem method")
 	ErrInvalidAddress      = errors.New("invalid bitcoin address")
 	ErrLightningInvoice    = errors.New("lightning invoice is required")
+	ErrInvoiceAmount       = errors.New("invoice amount outside allowed tolerance")
+	ErrNoLiquidity         = errors.New("insufficient outbound liquidity")
 )
 
 // Treasury cache and lock constants
@@ -53,35 +61,98 @@ const (
 	cardLockTTL    = 10 * time.Second
 )
 
+// Config holds the card service settings (populated from config.toml [redemption] section).
+type Config struct {
+	Network string // "testnet" or "mainnet"
+
+	// Lightning invoice amount tolerance: an invoice may differ from the
+	// requested amount by up to max(InvoiceToleranceSats, requested * InvoiceToleranceBps / 10000)
+	// to absorb wallets rounding msats. The card is always charged the invoice amount.
+	InvoiceToleranceSats int64
+	InvoiceToleranceBps  int64 // Basis points (10 = 0.1%)
+
+	// Lightning payments of at least ProbeThresholdSats are probed (route fee
+	// estimate) before paying, to fail fast on missing liquidity. 0 disables probing.
+	ProbeThresholdSats int64
+
+	// In-flight Lightning payments are held against channel liquidity until they
+	// settle or fail; InFlightHoldTTL expires a hold left behind by a crash.
+	// 0 uses defaultInFlightHoldTTL.
+	InFlightHoldTTL time.Duration
+
+	// MemoPolicy is how paid Lightning invoices are stored ("" = MemoHash)
+	MemoPolicy MemoPolicy
+
+	Quotes  QuoteConfig
+	Refunds RefundConfig
+	Support SupportConfig
+	Payouts PayoutConfig
+}
+
 // Service handles gift card business logic.
 type Service struct {
 	cardRepo  *database.CardRepository
 	txRepo    *database.TransactionRepository
-	network   string // "testnet" or "mainnet"
+	giftRepo  *database.GiftRepository
+	products  *database.ProductRepository
+	batches   *database.BatchRepository
+	cfg       Config
 	queue     *streams.StreamQueue
-	lndClient *lnd.Client
+	custodian treasury.Custodian
+	flags     *featureflag.Flags
+	prices    exchange.PriceProvider // BTC price for fiat refunds
+	payments  payment.Provider       // Fiat payment provider for refunds and retail activations
+	payouts   payout.Provider        // Open Banking PSP for SEPA Instant redemptions
+	analytics *analytics.Publisher   // Card lifecycle events (nil disables them)
+}
+
+// Deps are the card service's collaborators. Only the ones used by the
+// caller's code paths need to be set (e.g. the fund worker leaves the payment
+// providers nil).
+type Deps struct {
+	Cards        *database.CardRepository
+	Transactions *database.TransactionRepository
+	Gifts        *database.GiftRepository
+	Products     *database.ProductRepository
+	Batches      *database.BatchRepository
+	Queue        *streams.StreamQueue
+	Custodian    treasury.Custodian
+	Flags        *featureflag.Flags
+	Prices       exchange.PriceProvider // BTC price for fiat refunds
+	Payments     payment.Provider       // Fiat payment provider for refunds and retail activations
+	Payouts      payout.Provider        // Open Banking PSP for SEPA Instant redemptions
+	Analytics    *analytics.Publisher   // Card lifecycle events (nil disables them)
 }
 
 // NewService creates a new card service instance.
-func NewService(
-	cardRepo *database.CardRepository,
-	txRepo *database.TransactionRepository,
-	network string,
-	queue *streams.StreamQueue,
-	lndClient *lnd.Client,
-) *Service {
+func NewService(cfg Config, deps Deps) *Service {
 	return &Service{
-		cardRepo:  cardRepo,
-		txRepo:    txRepo,
-		network:   network,
-		queue:     queue,
-		lndClient: lndClient,
+		cardRepo:  deps.Cards,
+		txRepo:    deps.Transactions,
+		giftRepo:  deps.Gifts,
+		products:  deps.Products,
+		batches:   deps.Batches,
+		cfg:       cfg,
+		queue:     deps.Queue,
+		custodian: deps.Custodian,
+		flags:     deps.Flags,
+		prices:    deps.Prices,
+		payments:  deps.Payments,
+		payouts:   deps.Payouts,
+		analytics: deps.Analytics,
 	}
 }
 
-// GetTreasuryAvailableBalance returns the available treasury balance (total LND
+// FeatureEnabled reports whether a soft-launched feature is on for a card
+// (percentage rollouts bucket by card ID). Risky code paths (on-chain
+// batching, LNURL withdraw, instant refunds) must check this first.
+func (s *Service) FeatureEnabled(ctx context.Context, flag featureflag.Flag, cardID, merchantID string) bool {
+	return s.flags.Enabled(ctx, flag, featureflag.Subject{MerchantID: merchantID, Key: cardID})
+}
+
+// GetTreasuryAvailableBalance returns the available treasury balance (total custodian
 // holdings minus reserved card balances). Results are cached in Redis for 10s
-// to avoid hitting LND (~50-100ms latency) on every call.
+// to avoid hitting the custodian (~50-100ms latency for LND) on every call.
 func (s *Service) GetTreasuryAvailableBalance(ctx context.Context) (int64, error) {
 	// Try cache first
 	if cached, err := cache.Get(ctx, treasuryAvailableCacheKey); err == nil && cached != "" {
@@ -91,7 +162,7 @@ func (s *Service) GetTreasuryAvailableBalance(ctx context.Context) (int64, error
 		// Invalid cache value — fall through to recompute
 	}
 
-	// Compute from LND + DB
+	// Compute from custodian + DB
 	available, err := s.computeTreasuryBalance(ctx)
 	if err != nil {
 		return 0, err
@@ -105,20 +176,15 @@ func (s *Service) GetTreasuryAvailableBalance(ctx context.Context) (int64, error
 	return available, nil
 }
 
-// computeTreasuryBalance fetches LND balances and DB reserved amounts
+// computeTreasuryBalance fetches custodian balances and DB reserved amounts
 // to calculate the available treasury balance without caching.
 func (s *Service) computeTreasuryBalance(ctx context.Context) (int64, error) {
-	channelBal, err := s.lndClient.GetChannelBalance(ctx)
+	balances, err := s.custodian.GetBalances(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get channel balance: %w", err)
+		return 0, fmt.Errorf("failed to get treasury balances: %w", err)
 	}
 
-	walletBal, err := s.lndClient.GetWalletBalance(ctx)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get wallet balance: %w", err)
-	}
-
-	totalTreasury := channelBal.LocalSats + walletBal.ConfirmedSats
+	totalTreasury := balances.SpendableSats()
 
 	totalReserved, err := s.cardRepo.GetTotalReservedBalance(ctx)
 	if err != nil {
@@ -174,6 +240,43 @@ func (s *Service) InvalidateTreasuryCache(ctx context.Context) {
 	}
 }
 
+// acquireCardLock takes the per-card lock shared by every operation that
+// moves a card's balance. Until the returned release func is called the lock
+// is refreshed every cardLockTTL/3, so a Lightning payment that outlasts
+// cardLockTTL can't let a second request for the same card in.
+func (s *Service) acquireCardLock(ctx context.Context, code string) (func(), error) {
+	lockKey := cardLockPrefix + code
+	acquired, err := cache.SetNX(ctx, lockKey, "locked", cardLockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire card lock: %w", err)
+	}
+	if !acquired {
+		return nil, errors.New("card is being processed by another request")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cardLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// Not ctx: a cancelled request still holds the lock until it returns
+				if err := cache.Expire(context.Background(), lockKey, cardLockTTL); err != nil {
+					logger.Warn("failed to refresh card lock", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		cache.Delete(ctx, lockKey)
+	}, nil
+}
+
 // CreateCardRequest contains the parameters for creating a new gift card
 // Note: BTCAmountSats is NOT provided at creation - it will be calculated and set
 // by the funding worker based on the current BTC/fiat exchange rate.
@@ -183,6 +286,10 @@ type CreateCardRequest struct {
 	PurchasePriceCents int64  // Total charged including fees
 	UserID             *string
 	PurchaseEmail      string
+	Gift               *GiftDetails // Optional personalization (sender, recipient, message, theme)
+	QuoteID            string       // Optional rate quote from QuoteRate, honored by the fund worker while valid
+	ProductID          string       // Optional catalog product; sets face value, currency and price (see applyProduct)
+	MerchantID         *string      // Merchant selling the card (nil = sold directly)
 }
 
 // CreateCardResponse contains the created card details
@@ -197,6 +304,34 @@ type CreateCardResponse struct {
 // CreateCard creates a new gift card as a balance claim on the treasury.
 // No wallet or private key is generated — cards are custodial.
 func (s *Service) CreateCard(ctx context.Context, req CreateCardRequest) (*CreateCardResponse, error) {
+	// Validate gift details up front so a bad message doesn't leave an orphan card
+	var gift GiftDetails
+	if req.Gift != nil {
+		var err error
+		if gift, err = sanitizeGift(*req.Gift); err != nil {
+			return nil, err
+		}
+	}
+
+	// Catalog products fix the face value and price
+	var productID *string
+	if req.ProductID != "" {
+		product, err := s.applyProduct(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		productID = &product.ID
+	}
+
+	// Reject a stale, mismatched or already used checkout quote rather than
+	// silently repricing, and bind it to the card so no other card gets its rate
+	cardID := uuid.New().String()
+	if req.QuoteID != "" {
+		if err := s.claimQuote(ctx, req.QuoteID, cardID, req.FiatAmountCents, req.FiatCurrency); err != nil {
+			return nil, err
+		}
+	}
+
 	// 1. Generate a unique card code
 	code, err := s.generateCardCode(ctx)
 	if err != nil {
@@ -207,7 +342,7 @@ func (s *Service) CreateCard(ctx context.Context, req CreateCardRequest) (*Creat
 	// BTCAmountSats is 0 and will be set by the funding worker
 	// based on the current exchange rate when the card is funded.
 	card := &database.Card{
-		ID:                 uuid.New().String(),
+		ID:                 cardID,
 		UserID:             req.UserID,
 		PurchaseEmail:      req.PurchaseEmail,
 		OwnerEmail:         req.PurchaseEmail,
@@ -218,6 +353,8 @@ func (s *Service) CreateCard(ctx context.Context, req CreateCardRequest) (*Creat
 		PurchasePriceCents: req.PurchasePriceCents,
 		Status:             database.Created,
 		CreatedAt:          time.Now().UTC(),
+		ProductID:          productID,
+		MerchantID:         req.MerchantID,
 	}
 
 	// 3. Save card to database
@@ -229,32 +366,20 @@ func (s *Service) CreateCard(ctx context.Context, req CreateCardRequest) (*Creat
 		return nil, fmt.Errorf("failed to save card: %w", err)
 	}
 
+	if req.Gift != nil {
+		if err := s.saveGift(ctx, card.ID, gift); err != nil {
+			return nil, err
+		}
+	}
+
 	// 4. Publish FundCardMessage to queue (don't fail card creation if this fails)
-	msg := messages.FundCardMessage{
+	s.publishFundCard(ctx, messages.FundCardMessage{
 		CardID:          card.ID,
 		FiatAmountCents: card.FiatAmountCents,
 		FiatCurrency:    card.FiatCurrency,
-	}
-
-	msgJSON, err := msg.ToJSON()
-	if err != nil {
-		logger.Error("Failed to serialize FundCardMessage",
-			zap.String("card_id", card.ID),
-			zap.Error(err),
-		)
-	} else {
-		_, err = s.queue.Publish(ctx, "fund_card", msgJSON)
-		if err != nil {
-			logger.Error("Failed to publish FundCardMessage",
-				zap.String("card_id", card.ID),
-				zap.Error(err),
-			)
-		} else {
-			logger.Info("Published FundCardMessage",
-				zap.String("card_id", card.ID),
-			)
-		}
-	}
+		QuoteID:         req.QuoteID,
+	})
+	s.emitCardCreated(ctx, card, req.QuoteID != "")
 
 	// 5. Return response
 	return &CreateCardResponse{
@@ -302,15 +427,11 @@ func (s *Service) RedeemCard(ctx context.Context, req RedeemCardRequest) (*Redee
 	}
 
 	// Step 2: Acquire per-card lock (prevent concurrent double-spend)
-	lockKey := cardLockPrefix + req.Code
-	acquired, err := cache.SetNX(ctx, lockKey, "locked", cardLockTTL)
+	release, err := s.acquireCardLock(ctx, req.Code)
 	if err != nil {
-		return nil, fmt.Errorf("failed to acquire card lock: %w", err)
-	}
-	if !acquired {
-		return nil, errors.New("card is being processed by another request")
+		return nil, err
 	}
-	defer cache.Delete(ctx, lockKey)
+	defer release()
 
 	// Step 3: Retrieve and validate card
 	card, err := s.validateCardForRedemption(ctx, req.Code, req.AmountSats)
@@ -318,27 +439,33 @@ func (s *Service) RedeemCard(ctx context.Context, req RedeemCardRequest) (*Redee
 		return nil, err
 	}
 
-	// Step 4: Execute payment via LND
-	payResult, err := s.executePayment(ctx, req)
+	// Step 4: Execute payment via the treasury custodian
+	payResult, err := s.executePayment(ctx, req, card.BTCAmountSats)
 	if err != nil {
 		return nil, err
 	}
 
-	// Step 5: Create transaction record
-	now := time.Now().UTC()
-	tx, err := s.recordRedemptionTransaction(ctx, card.ID, req, payResult, now)
+	// Step 5: Update card balance (charge the amount actually paid). The card
+	// is charged before the transaction is recorded: if the insert fails, the
+	// paid amount can't be spent again and LedgerDiscrepancies reports the card.
+	remainingBalance, err := s.updateCardBalance(ctx, card.ID, card.BTCAmountSats, payResult.AmountSats)
 	if err != nil {
 		return nil, err
 	}
+	if err := chaos.Fail(chaos.RedeemAfterCardUpdate); err != nil {
+		return nil, err
+	}
 
-	// Step 6: Update card balance
-	remainingBalance, err := s.updateCardBalance(ctx, card.ID, card.BTCAmountSats, req.AmountSats)
+	// Step 6: Create transaction record
+	now := time.Now().UTC()
+	tx, err := s.recordRedemptionTransaction(ctx, card.ID, req, payResult, now, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Step 7: Invalidate treasury cache (balance changed)
 	s.InvalidateTreasuryCache(ctx)
+	s.emitRedemption(ctx, card, string(req.Method), card.BTCAmountSats, payResult.AmountSats, remainingBalance)
 
 	// Step 8: Publish monitor message for on-chain transactions
 	if req.Method == OnChain && payResult.TxHash != nil {
@@ -349,7 +476,7 @@ func (s *Service) RedeemCard(ctx context.Context, req RedeemCardRequest) (*Redee
 		zap.String("card_id", card.ID),
 		zap.String("tx_id", tx.ID),
 		zap.String("method", string(req.Method)),
-		zap.Int64("amount_sats", req.AmountSats),
+		zap.Int64("amount_sats", payResult.AmountSats),
 		zap.Int64("remaining_sats", remainingBalance),
 	)
 
@@ -358,7 +485,7 @@ func (s *Service) RedeemCard(ctx context.Context, req RedeemCardRequest) (*Redee
 		Method:           string(req.Method),
 		TxHash:           payResult.TxHash,
 		PaymentHash:      payResult.PaymentHash,
-		BTCAmountSats:    req.AmountSats,
+		BTCAmountSats:    payResult.AmountSats,
 		RemainingBalance: remainingBalance,
 		Status:           tx.Status,
 	}, nil
@@ -410,6 +537,7 @@ func (s *Service) validateCardForRedemption(ctx context.Context, code string, am
 
 // paymentOutput holds the results of executePayment (unified for both paths).
 type paymentOutput struct {
+	AmountSats      int64 // Amount actually sent (the invoice amount for Lightning)
 	PaymentHash     *string
 	PaymentPreimage *string
 	TxHash          *string
@@ -420,10 +548,11 @@ type paymentOutput struct {
 }
 
 // executePayment dispatches to the correct payment path (Lightning or on-chain).
-func (s *Service) executePayment(ctx context.Context, req RedeemCardRequest) (*paymentOutput, error) {
+// balanceSats is the card's current balance, the upper bound for any payment.
+func (s *Service) executePayment(ctx context.Context, req RedeemCardRequest, balanceSats int64) (*paymentOutput, error) {
 	switch req.Method {
 	case Lightning:
-		return s.executeLightningPayment(ctx, req.LightningInvoice, req.AmountSats)
+		return s.executeLightningPayment(ctx, req.LightningInvoice, req.AmountSats, balanceSats)
 	case OnChain:
 		return s.executeOnChainPayment(ctx, req.DestinationAddress, req.AmountSats)
 	default:
@@ -432,43 +561,56 @@ func (s *Service) executePayment(ctx context.Context, req RedeemCardRequest) (*p
 }
 
 // executeLightningPayment decodes, validates, and pays a BOLT11 invoice.
-func (s *Service) executeLightningPayment(ctx context.Context, invoice string, amountSats int64) (*paymentOutput, error) {
+// The invoice amount may differ from amountSats within the configured tolerance;
+// the invoice amount is what gets paid and charged to the card.
+func (s *Service) executeLightningPayment(ctx context.Context, invoice string, amountSats, balanceSats int64) (*paymentOutput, error) {
 	// Decode and validate
-	decoded, err := s.lndClient.DecodeInvoice(ctx, invoice)
+	decoded, err := s.validateLightningInvoice(ctx, invoice, amountSats, balanceSats)
 	if err != nil {
-		return nil, fmt.Errorf("invalid invoice: %w", err)
-	}
-
-	if decoded.AmountSats == 0 {
-		return nil, errors.New("zero-amount invoices not supported")
-	}
-
-	if decoded.IsExpired {
-		return nil, errors.New("invoice has expired")
+		return nil, err
 	}
 
-	if decoded.AmountSats != amountSats {
-		return nil, fmt.Errorf("invoice amount (%d sats) does not match requested amount (%d sats)", decoded.AmountSats, amountSats)
+	// Pre-flight probe for large payments (fail fast instead of a long in-flight timeout)
+	if s.cfg.ProbeThresholdSats > 0 && decoded.AmountSats >= s.cfg.ProbeThresholdSats {
+		estimate, err := s.probeLightningRoute(ctx, invoice)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Lightning route probe succeeded",
+			zap.Int64("amount_sats", decoded.AmountSats),
+			zap.Int64("estimated_fee_sats", estimate.FeeSats),
+		)
 	}
 
 	// Pay the invoice
 	logger.Info("Paying Lightning invoice",
-		zap.Int64("amount_sats", amountSats),
+		zap.Int64("amount_sats", decoded.AmountSats),
+		zap.Int64("requested_sats", amountSats),
 		zap.String("destination", decoded.Destination),
 	)
 
-	result, err := s.lndClient.PayInvoice(ctx, invoice, s.lndClient.Cfg.MaxPaymentFeeSats)
+	// Hold the amount against channel liquidity while the payment is in flight
+	hold, err := s.reserveLightningLiquidity(ctx, decoded.PaymentHash, decoded.AmountSats)
 	if err != nil {
-		return nil, fmt.Errorf("lightning payment failed: %w", err)
+		return nil, err
 	}
 
-	// Verify payment actually succeeded (PayInvoice could return non-error with failed status)
-	if result.Status != lnd.Succeeded {
-		return nil, fmt.Errorf("lightning payment did not succeed: status=%s", result.Status)
+	// The custodian only returns a result once the payment has succeeded
+	result, err := s.custodian.PayLightning(ctx, invoice)
+	if err != nil {
+		if errors.Is(err, treasury.ErrPaymentFailed) {
+			s.releaseLightningHold(ctx, hold)
+		} else {
+			// HTLCs may still be in flight: keep the hold until the payment resolves
+			go s.keepLightningHold(hold, decoded.PaymentHash)
+		}
+		return nil, fmt.Errorf("lightning payment failed: %w", err)
 	}
+	s.releaseLightningHold(ctx, hold)
 
 	now := time.Now().UTC()
 	return &paymentOutput{
+		AmountSats:      decoded.AmountSats,
 		PaymentHash:     &result.PaymentHash,
 		PaymentPreimage: &result.PaymentPreimage,
 		Invoice:         &invoice,
@@ -477,20 +619,60 @@ func (s *Service) executeLightningPayment(ctx context.Context, invoice string, a
 	}, nil
 }
 
-// executeOnChainPayment validates the address and sends an on-chain transaction.
-func (s *Service) executeOnChainPayment(ctx context.Context, address string, amountSats int64) (*paymentOutput, error) {
-	// Validate destination address
-	isValid, err := wallet.ValidateAddress(address, s.network)
+// validateLightningInvoice decodes a BOLT11 invoice and checks it can be paid
+// for a redemption of amountSats from a card holding balanceSats.
+func (s *Service) validateLightningInvoice(ctx context.Context, invoice string, amountSats, balanceSats int64) (*treasury.Invoice, error) {
+	decoded, err := s.custodian.DecodeInvoice(ctx, invoice)
 	if err != nil {
-		return nil, fmt.Errorf("failed to validate address: %w", err)
+		return nil, fmt.Errorf("invalid invoice: %w", err)
 	}
-	if !isValid {
-		return nil, ErrInvalidAddress
+
+	if decoded.AmountSats == 0 {
+		return nil, errors.New("zero-amount invoices not supported")
 	}
 
-	// Enforce minimum on-chain amount (mining fees make tiny sends uneconomical)
-	if amountSats < minOnChainAmountSats {
-		return nil, fmt.Errorf("on-chain minimum is %d sats", minOnChainAmountSats)
+	if decoded.IsExpired {
+		return nil, errors.New("invoice has expired")
+	}
+
+	if err := s.checkInvoiceAmount(decoded.AmountSats, amountSats, balanceSats); err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// EstimateLightningFee returns an upfront routing fee estimate (in sats) for
+// paying a BOLT11 invoice, so the user can see the fee before redeeming.
+func (s *Service) EstimateLightningFee(ctx context.Context, invoice string) (int64, error) {
+	if invoice == "" {
+		return 0, ErrLightningInvoice
+	}
+
+	estimate, err := s.probeLightningRoute(ctx, invoice)
+	if err != nil {
+		return 0, err
+	}
+	return estimate.FeeSats, nil
+}
+
+// probeLightningRoute probes the invoice route via the custodian, mapping a
+// missing route to ErrNoLiquidity.
+func (s *Service) probeLightningRoute(ctx context.Context, invoice string) (*treasury.FeeEstimate, error) {
+	estimate, err := s.custodian.EstimateLightningFee(ctx, invoice)
+	if err != nil {
+		if errors.Is(err, treasury.ErrInsufficientLiquidity) {
+			return nil, ErrNoLiquidity
+		}
+		return nil, fmt.Errorf("route probe failed: %w", err)
+	}
+	return estimate, nil
+}
+
+// executeOnChainPayment validates the address and sends an on-chain transaction.
+func (s *Service) executeOnChainPayment(ctx context.Context, address string, amountSats int64) (*paymentOutput, error) {
+	if err := s.validateOnChainPayment(address, amountSats); err != nil {
+		return nil, err
 	}
 
 	// Send on-chain
@@ -500,25 +682,46 @@ func (s *Service) executeOnChainPayment(ctx context.Context, address string, amo
 		zap.Int32("target_conf", defaultTargetConf),
 	)
 
-	result, err := s.lndClient.SendOnChain(ctx, address, amountSats, defaultTargetConf)
+	result, err := s.custodian.SendOnChain(ctx, address, amountSats, defaultTargetConf)
 	if err != nil {
 		return nil, fmt.Errorf("on-chain send failed: %w", err)
 	}
 
 	return &paymentOutput{
-		TxHash:    &result.TxHash,
-		ToAddress: &address,
-		Status:    database.Pending, // Confirmed later by monitor worker
+		AmountSats: amountSats,
+		TxHash:     &result.TxHash,
+		ToAddress:  &address,
+		Status:     database.Pending, // Confirmed later by monitor worker
 	}, nil
 }
 
+// validateOnChainPayment checks the destination address and the on-chain minimum.
+func (s *Service) validateOnChainPayment(address string, amountSats int64) error {
+	isValid, err := wallet.ValidateAddress(address, s.cfg.Network)
+	if err != nil {
+		return fmt.Errorf("failed to validate address: %w", err)
+	}
+	if !isValid {
+		return ErrInvalidAddress
+	}
+
+	// Enforce minimum on-chain amount (mining fees make tiny sends uneconomical)
+	if amountSats < minOnChainAmountSats {
+		return fmt.Errorf("on-chain minimum is %d sats", minOnChainAmountSats)
+	}
+
+	return nil
+}
+
 // recordRedemptionTransaction creates a Transaction record for the redemption.
+// redemptionID links the legs of a split redemption (nil for single-leg spends).
 func (s *Service) recordRedemptionTransaction(
 	ctx context.Context,
 	cardID string,
 	req RedeemCardRequest,
 	pay *paymentOutput,
 	now time.Time,
+	redemptionID *string,
 ) (*database.Transaction, error) {
 	method := string(req.Method)
 	tx := &database.Transaction{
@@ -529,14 +732,15 @@ func (s *Service) recordRedemptionTransaction(
 		TxHash:           pay.TxHash,
 		PaymentHash:      pay.PaymentHash,
 		PaymentPreimage:  pay.PaymentPreimage,
-		LightningInvoice: pay.Invoice,
+		LightningInvoice: s.storedInvoice(pay.Invoice),
 		ToAddress:        pay.ToAddress,
-		BTCAmountSats:    req.AmountSats,
+		BTCAmountSats:    pay.AmountSats,
 		Status:           pay.Status,
 		Confirmations:    0,
 		CreatedAt:        now,
 		BroadcastAt:      &now,
 		ConfirmedAt:      pay.ConfirmedAt,
+		RedemptionID:     redemptionID,
 	}
 
 	if err := s.txRepo.Create(ctx, tx); err != nil {
@@ -565,6 +769,31 @@ func (s *Service) updateCardBalance(ctx context.Context, cardID string, currentB
 	return remaining, nil
 }
 
+// publishFundCard publishes a FundCardMessage so the fund worker prices and
+// funds the card. Failures are logged, not returned: the card already exists
+// and can be re-queued.
+func (s *Service) publishFundCard(ctx context.Context, msg messages.FundCardMessage) {
+	msgJSON, err := msg.ToJSON()
+	if err != nil {
+		logger.Error("Failed to serialize FundCardMessage",
+			zap.String("card_id", msg.CardID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if _, err := s.queue.Publish(ctx, "fund_card", msgJSON); err != nil {
+		logger.Error("Failed to publish FundCardMessage",
+			zap.String("card_id", msg.CardID),
+			zap.Error(err),
+		)
+	} else {
+		logger.Info("Published FundCardMessage",
+			zap.String("card_id", msg.CardID),
+		)
+	}
+}
+
 // publishMonitorTransaction publishes a MonitorTransactionMessage so a worker
 // can track on-chain confirmations and update the transaction status.
 func (s *Service) publishMonitorTransaction(ctx context.Context, cardID, txID, txHash string, amountSats int64, destAddr string) {
@@ -599,12 +828,13 @@ func (s *Service) publishMonitorTransaction(ctx context.Context, cardID, txID, t
 	}
 }
 
-// GetCardByCode retrieves card details by redemption code.
+// GetCardByCode retrieves card details by redemption code. Returns
+// ErrCodeRotated for a code replaced by RotateCode.
 func (s *Service) GetCardByCode(ctx context.Context, code string) (*database.Card, error) {
 	card, err := s.cardRepo.GetByCode(ctx, code)
 	if err != nil {
 		if errors.Is(err, database.ErrCardNotFound) {
-			return nil, ErrCardNotFound
+			return nil, s.retiredCodeError(ctx, code)
 		}
 		return nil, fmt.Errorf("failed to get card: %w", err)
 	}
@@ -630,7 +860,7 @@ func (s *Service) ValidateCardCode(ctx context.Context, code string) (database.C
 	card, err := s.cardRepo.GetByCode(ctx, code)
 	if err != nil {
 		if errors.Is(err, database.ErrCardNotFound) {
-			return database.Expired, ErrCardNotFound
+			return database.Expired, s.retiredCodeError(ctx, code)
 		}
 		return database.Expired, fmt.Errorf("failed to validate card: %w", err)
 	}
@@ -640,32 +870,19 @@ func (s *Service) ValidateCardCode(ctx context.Context, code string) (database.C
 // Helper function to generate a unique card code
 // Format: GIFT-XXXX-YYYY-ZZZZ (16 alphanumeric characters in groups)
 func (s *Service) generateCardCode(ctx context.Context) (string, error) {
-	// Character set excluding visually similar characters (O, 0, I, 1, L)
-	const charset = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
-	const codeLength = 16
-
 	for attempt := 0; attempt < 5; attempt++ {
-		// Generate 16 random characters
-		code := make([]byte, codeLength)
-		if _, err := rand.Read(code); err != nil {
-			return "", fmt.Errorf("failed to generate random bytes: %w", err)
-		}
-		for i := range code {
-			code[i] = charset[int(code[i])%len(charset)]
+		formattedCode, err := randomCardCode()
+		if err != nil {
+			return "", err
 		}
 
-		// Format as GIFT-XXXX-YYYY-ZZZZ
-		codeStr := string(code)
-		formattedCode := fmt.Sprintf("GIFT-%s-%s-%s",
-			codeStr[0:4],
-			codeStr[4:8],
-			codeStr[8:12],
-		)
-
-		// Check uniqueness in database
-		_, err := s.cardRepo.GetByCode(ctx, formattedCode)
+		// Check uniqueness in database (retired codes are never reissued)
+		_, err = s.cardRepo.GetByCode(ctx, formattedCode)
 		if err != nil {
 			if errors.Is(err, database.ErrCardNotFound) {
+				if _, err := s.cardRepo.GetRetiredCode(ctx, formattedCode); !errors.Is(err, database.ErrRetiredCodeNotFound) {
+					continue // Retired, or the check failed: try another code
+				}
 				// Code is unique, return it
 				return formattedCode, nil
 			}
@@ -677,3 +894,28 @@ func (s *Service) generateCardCode(ctx context.Context) (string, error) {
 
 	return "", errors.New("failed to generate unique card code after 5 attempts")
 }
+
+// randomCardCode returns a random code formatted as GIFT-XXXX-YYYY-ZZZZ
+// (uniqueness is up to the caller).
+func randomCardCode() (string, error) {
+	// Character set excluding visually similar characters (O, 0, I, 1, L)
+	const charset = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	const codeLength = 16
+
+	// Generate 16 random characters
+	code := make([]byte, codeLength)
+	if _, err := rand.Read(code); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	for i := range code {
+		code[i] = charset[int(code[i])%len(charset)]
+	}
+
+	// Format as GIFT-XXXX-YYYY-ZZZZ
+	codeStr := string(code)
+	return fmt.Sprintf("GIFT-%s-%s-%s",
+		codeStr[0:4],
+		codeStr[4:8],
+		codeStr[8:12],
+	), nil
+}
@@ -5,6 +5,7 @@ package card
 import (
 	"btc-giftcard/internal/database"
 	messages "btc-giftcard/internal/queue"
+	"btc-giftcard/pkg/cache"
 	"btc-giftcard/pkg/logger"
 	streams "btc-giftcard/pkg/queue"
 	"context"
@@ -31,6 +32,8 @@ func setupTestService(t *testing.T) (*Service, *database.DB, *database.CardRepos
 
 	cardRepo := database.NewCardRepository(db)
 	txRepo := database.NewTransactionRepository(db)
+	giftRepo := database.NewGiftRepository(db)
+	productRepo := database.NewProductRepository(db)
 
 	// Setup Redis for queue
 	redisClient := redis.NewClient(&redis.Options{
@@ -47,7 +50,14 @@ func setupTestService(t *testing.T) (*Service, *database.DB, *database.CardRepos
 	err := queue.DeclareStream(ctx, "fund_card", "test_workers")
 	require.NoError(t, err)
 
-	service := NewService(cardRepo, txRepo, "testnet", queue, nil)
+	service := NewService(Config{Network: "testnet"}, Deps{
+		Cards:        cardRepo,
+		Transactions: txRepo,
+		Gifts:        giftRepo,
+		Products:     productRepo,
+		Batches:      database.NewBatchRepository(db),
+		Queue:        queue,
+	})
 
 	return service, db, cardRepo, redisClient
 }
@@ -284,3 +294,118 @@ func TestService_generateCardCode_RetriesOnDuplicate(t *testing.T) {
 	// Verify the existing code is not in the generated codes
 	assert.NotContains(t, codes, "GIFT-TEST-CODE-0001")
 }
+
+func TestService_CreateCard_WithGift(t *testing.T) {
+	service, db, _, redisClient := setupTestService(t)
+	defer db.Close()
+	defer redisClient.Close()
+	defer database.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+
+	resp, err := service.CreateCard(ctx, CreateCardRequest{
+		FiatAmountCents:    5000,
+		FiatCurrency:       "USD",
+		PurchasePriceCents: 5200,
+		PurchaseEmail:      "alice@example.com",
+		Gift: &GiftDetails{
+			SenderName:    "Alice",
+			RecipientName: "Bob",
+			Message:       "<i>Happy</i> birthday!",
+			Theme:         "birthday",
+		},
+	})
+	require.NoError(t, err)
+
+	gift, err := service.GetGift(ctx, resp.CardID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", gift.SenderName)
+	assert.Equal(t, "Happy birthday!", gift.Message)
+	assert.Equal(t, "birthday", gift.Theme)
+
+	// Update before delivery
+	err = service.UpdateGift(ctx, resp.CardID, GiftDetails{SenderName: "Alice", Message: "Enjoy!"})
+	require.NoError(t, err)
+
+	gift, err = service.GetGift(ctx, resp.CardID)
+	require.NoError(t, err)
+	assert.Equal(t, "Enjoy!", gift.Message)
+	assert.Equal(t, "classic", gift.Theme)
+}
+
+func TestService_CreateCard_InvalidGift(t *testing.T) {
+	service, db, _, redisClient := setupTestService(t)
+	defer db.Close()
+	defer redisClient.Close()
+	defer database.CleanupTestDB(t, db)
+
+	_, err := service.CreateCard(context.Background(), CreateCardRequest{
+		FiatAmountCents:    5000,
+		FiatCurrency:       "USD",
+		PurchasePriceCents: 5200,
+		PurchaseEmail:      "alice@example.com",
+		Gift:               &GiftDetails{Theme: "neon"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidGift)
+}
+
+func TestService_UpdateGift_Errors(t *testing.T) {
+	service, db, _, redisClient := setupTestService(t)
+	defer db.Close()
+	defer redisClient.Close()
+	defer database.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+
+	err := service.UpdateGift(ctx, uuid.New().String(), GiftDetails{Message: "hi"})
+	assert.ErrorIs(t, err, ErrCardNotFound)
+
+	resp, err := service.CreateCard(ctx, CreateCardRequest{
+		FiatAmountCents:    5000,
+		FiatCurrency:       "USD",
+		PurchasePriceCents: 5200,
+		PurchaseEmail:      "alice@example.com",
+		Gift:               &GiftDetails{Message: "hi"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, service.giftRepo.MarkDelivered(ctx, resp.CardID, time.Now().UTC()))
+
+	err = service.UpdateGift(ctx, resp.CardID, GiftDetails{Message: "changed"})
+	assert.ErrorIs(t, err, ErrGiftDelivered)
+}
+
+func TestService_CreateCard_QuoteUsedOnce(t *testing.T) {
+	service, db, _, redisClient := setupTestService(t)
+	defer db.Close()
+	defer redisClient.Close()
+	defer database.CleanupTestDB(t, db)
+
+	require.NoError(t, cache.Init(cache.Config{Host: "localhost", Port: "6379", DB: 1}))
+	defer cache.Close()
+
+	service.prices = fixedPrice(60_000)
+	service.cfg.Quotes = QuoteConfig{Provider: "coinbase", Validity: time.Minute, GuardBps: 100}
+
+	ctx := context.Background()
+	quote, err := service.QuoteRate(ctx, 5000, "EUR")
+	require.NoError(t, err)
+
+	req := CreateCardRequest{
+		FiatAmountCents:    5000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5200,
+		PurchaseEmail:      "alice@example.com",
+		QuoteID:            quote.ID,
+	}
+	resp, err := service.CreateCard(ctx, req)
+	require.NoError(t, err)
+
+	bound, err := GetQuote(ctx, quote.ID)
+	require.NoError(t, err)
+	assert.Equal(t, resp.CardID, bound.CardID, "quote bound to the card")
+	assert.Equal(t, quote.Price, bound.Price)
+
+	_, err = service.CreateCard(ctx, req)
+	assert.ErrorIs(t, err, ErrQuoteUsed, "a second card can't use the same quote")
+}
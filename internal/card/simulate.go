@@ -0,0 +1,78 @@
+package card
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedemptionSimulation is what RedeemCard would do for a request.
+type RedemptionSimulation struct {
+	Method           string
+	AmountSats       int64 // Amount that would be paid and charged (the invoice amount for Lightning)
+	EstimatedFeeSats int64 // Lightning routing fee estimate, paid by the treasury (0 for on-chain)
+	RemainingBalance int64 // Card balance after the redemption
+}
+
+// SimulateRedemption runs the validation RedeemCard would run for req — card
+// state and balance, invoice decode and amount tolerance, address and on-chain
+// minimum, treasury liquidity, and for Lightning a route probe for the fee —
+// and reports the outcome, so merchants can test an integration without
+// moving sats. It returns the same errors RedeemCard would.
+//
+// Nothing is written: no card lock, no in-flight hold, no transaction, and
+// the liquidity check reads in-flight holds without pruning expired ones. A
+// simulation that succeeds can still fail for real if liquidity or the card
+// change in between.
+func (s *Service) SimulateRedemption(ctx context.Context, req RedeemCardRequest) (*RedemptionSimulation, error) {
+	if err := s.validateRedeemRequest(req); err != nil {
+		return nil, err
+	}
+
+	card, err := s.validateCardForRedemption(ctx, req.Code, req.AmountSats)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.custodian.GetBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury balances: %w", err)
+	}
+
+	sim := &RedemptionSimulation{Method: string(req.Method)}
+	switch req.Method {
+	case Lightning:
+		decoded, err := s.validateLightningInvoice(ctx, req.LightningInvoice, req.AmountSats, card.BTCAmountSats)
+		if err != nil {
+			return nil, err
+		}
+
+		inFlight, err := activeInFlightSats(ctx, strconv.FormatInt(time.Now().Unix(), 10))
+		if err != nil {
+			return nil, err
+		}
+		if available := balances.LightningSats - inFlight; decoded.AmountSats > available {
+			return nil, fmt.Errorf("%w: %d sats available, %d sats in flight", ErrNoLiquidity, available, inFlight)
+		}
+
+		estimate, err := s.probeLightningRoute(ctx, req.LightningInvoice)
+		if err != nil {
+			return nil, err
+		}
+		sim.AmountSats = decoded.AmountSats
+		sim.EstimatedFeeSats = estimate.FeeSats
+
+	case OnChain:
+		if err := s.validateOnChainPayment(req.DestinationAddress, req.AmountSats); err != nil {
+			return nil, err
+		}
+		if req.AmountSats > balances.OnChainConfirmedSats {
+			return nil, fmt.Errorf("%w: %d sats confirmed on-chain", ErrNoLiquidity, balances.OnChainConfirmedSats)
+		}
+		sim.AmountSats = req.AmountSats
+	}
+
+	sim.RemainingBalance = card.BTCAmountSats - sim.AmountSats
+	return sim, nil
+}
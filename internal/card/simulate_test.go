@@ -0,0 +1,92 @@
+//go:build integration
+
+package card
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/treasury"
+	"btc-giftcard/pkg/cache"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// probingCustodian is a ledgerCustodian that also estimates routing fees.
+type probingCustodian struct {
+	ledgerCustodian
+}
+
+func (c *probingCustodian) EstimateLightningFee(_ context.Context, _ string) (*treasury.FeeEstimate, error) {
+	return &treasury.FeeEstimate{FeeSats: 12}, nil
+}
+
+func TestService_SimulateRedemption(t *testing.T) {
+	db := database.SetupTestDB(t)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+
+	require.NoError(t, cache.Init(cache.Config{Host: "localhost", Port: "6379", DB: 1}))
+	defer cache.Close()
+
+	ctx := context.Background()
+	cardRepo := database.NewCardRepository(db)
+	txRepo := database.NewTransactionRepository(db)
+	svc := NewService(Config{Network: "testnet"}, Deps{
+		Cards:        cardRepo,
+		Transactions: txRepo,
+		Queue:        streams.NewStreamQueue(cache.Client),
+		Custodian:    &probingCustodian{},
+	})
+
+	now := time.Now().UTC()
+	target := int64(100_000)
+	card := &database.Card{
+		ID:                 uuid.New().String(),
+		PurchaseEmail:      "sim@example.com",
+		OwnerEmail:         "sim@example.com",
+		Code:               "GIFT-SIMU-" + uuid.New().String()[:9],
+		FiatAmountCents:    5_000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5_000,
+		Status:             database.Created,
+		CreatedAt:          now,
+	}
+	require.NoError(t, cardRepo.Create(ctx, card))
+	require.NoError(t, cardRepo.UpdateFunding(ctx, card.ID, database.Active, target, &target, &now))
+
+	sim, err := svc.SimulateRedemption(ctx, RedeemCardRequest{Code: card.Code, Method: Lightning, AmountSats: 30_000, LightningInvoice: "lnfake:30000:" + randomHash()})
+	require.NoError(t, err)
+	assert.Equal(t, &RedemptionSimulation{Method: "lightning", AmountSats: 30_000, EstimatedFeeSats: 12, RemainingBalance: 70_000}, sim)
+
+	sim, err = svc.SimulateRedemption(ctx, RedeemCardRequest{Code: card.Code, Method: OnChain, AmountSats: 20_000, DestinationAddress: ledgerTestAddress})
+	require.NoError(t, err)
+	assert.Equal(t, int64(80_000), sim.RemainingBalance)
+
+	// Failures are the ones RedeemCard would return
+	_, err = svc.SimulateRedemption(ctx, RedeemCardRequest{Code: card.Code, Method: Lightning, AmountSats: 200_000, LightningInvoice: "lnfake:200000:" + randomHash()})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = svc.SimulateRedemption(ctx, RedeemCardRequest{Code: card.Code, Method: OnChain, AmountSats: 20_000, DestinationAddress: "not-an-address"})
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = svc.SimulateRedemption(ctx, RedeemCardRequest{Code: card.Code, Method: OnChain, AmountSats: 5_000, DestinationAddress: ledgerTestAddress})
+	assert.Error(t, err, "below the on-chain minimum")
+
+	// Nothing changed
+	got, err := cardRepo.GetByID(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target, got.BTCAmountSats)
+	txs, err := txRepo.ListByCardID(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+	inFlight, err := svc.LightningInFlightSats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, inFlight)
+	locked, err := cache.Exists(ctx, cardLockPrefix+card.Code)
+	require.NoError(t, err)
+	assert.False(t, locked)
+}
@@ -0,0 +1,245 @@
+package card
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"btc-giftcard/internal/chaos"
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/wallet"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidSplit    = errors.New("invalid split: invoice must not exceed the amount, and an on-chain remainder must be at least the on-chain minimum")
+	ErrSplitIncomplete = errors.New("split redemption incomplete: on-chain leg failed after the lightning leg was paid")
+)
+
+// SplitPlan is how a redemption would be divided between the two rails.
+type SplitPlan struct {
+	LightningSats int64 // Amount to request a Lightning invoice for
+	OnChainSats   int64 // Remainder sent on-chain (0 = Lightning covers everything)
+}
+
+// SplitRedeemRequest contains the parameters for a split redemption.
+type SplitRedeemRequest struct {
+	Code               string
+	AmountSats         int64  // Total to redeem across both legs
+	LightningInvoice   string // BOLT11 for the Lightning leg; its amount sets the split (empty = on-chain only)
+	DestinationAddress string // Receives AmountSats minus the invoice amount (unused if the invoice covers it all)
+}
+
+// SplitRedeemResponse contains both legs of a split redemption.
+type SplitRedeemResponse struct {
+	RedemptionID     string              // Shared by both legs' transactions
+	Lightning        *RedeemCardResponse // Lightning leg (nil if the plan had none)
+	OnChain          *RedeemCardResponse // On-chain leg (nil if the plan had none, or it failed, see ErrSplitIncomplete)
+	RemainingBalance int64
+}
+
+// planSplit divides amountSats given the Lightning liquidity available: as much
+// as possible over Lightning, the rest on-chain, never leaving an on-chain leg
+// below the on-chain minimum.
+func planSplit(amountSats, liquiditySats int64) *SplitPlan {
+	if liquiditySats >= amountSats {
+		return &SplitPlan{LightningSats: amountSats}
+	}
+
+	lightning := max(liquiditySats, 0)
+	if amountSats-lightning < minOnChainAmountSats {
+		lightning = amountSats - minOnChainAmountSats
+	}
+	if lightning <= 0 {
+		// Too little Lightning liquidity to be worth splitting
+		return &SplitPlan{OnChainSats: amountSats}
+	}
+
+	return &SplitPlan{LightningSats: lightning, OnChainSats: amountSats - lightning}
+}
+
+// checkSplit validates paying lightningSats of amountSats over Lightning and
+// the rest on-chain. Either leg may be empty, which is what planSplit returns
+// when one rail can carry the whole amount, but an on-chain leg must reach
+// the on-chain minimum.
+func checkSplit(amountSats, lightningSats int64) error {
+	onChainSats := amountSats - lightningSats
+	if lightningSats < 0 || onChainSats < 0 {
+		return ErrInvalidSplit
+	}
+	if onChainSats > 0 && onChainSats < minOnChainAmountSats {
+		return ErrInvalidSplit
+	}
+	return nil
+}
+
+// PlanSplitRedemption tells the client how much to request a Lightning invoice
+// for when redeeming amountSats in split mode, based on current Lightning
+// liquidity (local channel balance minus in-flight payments).
+func (s *Service) PlanSplitRedemption(ctx context.Context, code string, amountSats int64) (*SplitPlan, error) {
+	if amountSats <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	if _, err := s.validateCardForRedemption(ctx, code, amountSats); err != nil {
+		return nil, err
+	}
+
+	balances, err := s.custodian.GetBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury balances: %w", err)
+	}
+	inFlight, err := s.LightningInFlightSats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planSplit(amountSats, balances.LightningSats-inFlight)
+	if err := checkSplit(amountSats, plan.LightningSats); err != nil {
+		return nil, fmt.Errorf("%w: amount is below the on-chain minimum of %d sats", ErrNoLiquidity, minOnChainAmountSats)
+	}
+	return plan, nil
+}
+
+// RedeemCardSplit redeems AmountSats as one logical redemption: the Lightning
+// invoice's amount is paid over Lightning and the remainder is sent on-chain.
+// Both legs are recorded as Redeem transactions sharing a redemption ID. A
+// plan from PlanSplitRedemption can be redeemed as is: without an invoice the
+// whole amount goes on-chain, and an invoice for the whole amount needs no
+// address.
+//
+// The Lightning leg runs first (it is the one likely to fail on liquidity, and
+// failing it costs nothing). If the on-chain send then fails, the Lightning
+// leg stands: the response describes it, the error is ErrSplitIncomplete, and
+// the unspent remainder stays on the card.
+func (s *Service) RedeemCardSplit(ctx context.Context, req SplitRedeemRequest) (*SplitRedeemResponse, error) {
+	// Step 1: Validate input
+	if req.AmountSats <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	// Step 2: Acquire per-card lock (shared with RedeemCard), held across both legs
+	release, err := s.acquireCardLock(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Step 3: Retrieve and validate card
+	card, err := s.validateCardForRedemption(ctx, req.Code, req.AmountSats)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 4: The invoice amount sets the split
+	var lightningSats int64
+	if req.LightningInvoice != "" {
+		decoded, err := s.custodian.DecodeInvoice(ctx, req.LightningInvoice)
+		if err != nil {
+			return nil, fmt.Errorf("invalid invoice: %w", err)
+		}
+		if decoded.AmountSats <= 0 {
+			return nil, ErrInvalidSplit
+		}
+		lightningSats = decoded.AmountSats
+	}
+	if err := checkSplit(req.AmountSats, lightningSats); err != nil {
+		return nil, err
+	}
+	onChainSats := req.AmountSats - lightningSats
+
+	// Check the address now: once the Lightning leg is paid it can't be undone
+	if onChainSats > 0 {
+		if req.DestinationAddress == "" {
+			return nil, ErrInvalidAddress
+		}
+		if isValid, err := wallet.ValidateAddress(req.DestinationAddress, s.cfg.Network); err != nil || !isValid {
+			return nil, ErrInvalidAddress
+		}
+	}
+
+	redemptionID := uuid.New().String()
+	resp := &SplitRedeemResponse{RedemptionID: redemptionID, RemainingBalance: card.BTCAmountSats}
+
+	// Step 5: Lightning leg
+	if lightningSats > 0 {
+		lnReq := RedeemCardRequest{Code: req.Code, Method: Lightning, AmountSats: lightningSats, LightningInvoice: req.LightningInvoice}
+		resp.Lightning, err = s.redeemLeg(ctx, card, lnReq, redemptionID, resp.RemainingBalance)
+		if err != nil {
+			return nil, err
+		}
+		resp.RemainingBalance = resp.Lightning.RemainingBalance
+	}
+
+	// Step 6: On-chain leg
+	if onChainSats > 0 {
+		ocReq := RedeemCardRequest{Code: req.Code, Method: OnChain, AmountSats: onChainSats, DestinationAddress: req.DestinationAddress}
+		resp.OnChain, err = s.redeemLeg(ctx, card, ocReq, redemptionID, resp.RemainingBalance)
+		if err != nil {
+			if resp.Lightning == nil {
+				return nil, err
+			}
+			logger.Error("Split redemption on-chain leg failed",
+				zap.String("card_id", card.ID),
+				zap.String("redemption_id", redemptionID),
+				zap.Int64("onchain_sats", onChainSats),
+				zap.Error(err),
+			)
+			return resp, fmt.Errorf("%w: %v", ErrSplitIncomplete, err)
+		}
+		resp.RemainingBalance = resp.OnChain.RemainingBalance
+	}
+
+	logger.Info("Card redeemed in split mode",
+		zap.String("card_id", card.ID),
+		zap.String("redemption_id", redemptionID),
+		zap.Int64("lightning_sats", lightningSats),
+		zap.Int64("onchain_sats", onChainSats),
+		zap.Int64("remaining_sats", resp.RemainingBalance),
+	)
+
+	return resp, nil
+}
+
+// redeemLeg pays one leg of a split redemption and charges it to the card
+// (RedeemCard steps 4–8 for a single method).
+func (s *Service) redeemLeg(ctx context.Context, card *database.Card, req RedeemCardRequest, redemptionID string, balanceSats int64) (*RedeemCardResponse, error) {
+	payResult, err := s.executePayment(ctx, req, balanceSats)
+	if err != nil {
+		return nil, err
+	}
+
+	remainingBalance, err := s.updateCardBalance(ctx, card.ID, balanceSats, payResult.AmountSats)
+	if err != nil {
+		return nil, err
+	}
+	if err := chaos.Fail(chaos.RedeemAfterCardUpdate); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	tx, err := s.recordRedemptionTransaction(ctx, card.ID, req, payResult, now, &redemptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.InvalidateTreasuryCache(ctx)
+	s.emitRedemption(ctx, card, string(req.Method), balanceSats, payResult.AmountSats, remainingBalance)
+
+	if req.Method == OnChain && payResult.TxHash != nil {
+		s.publishMonitorTransaction(ctx, card.ID, tx.ID, *payResult.TxHash, req.AmountSats, req.DestinationAddress)
+	}
+
+	return &RedeemCardResponse{
+		TransactionID:    tx.ID,
+		Method:           string(req.Method),
+		TxHash:           payResult.TxHash,
+		PaymentHash:      payResult.PaymentHash,
+		BTCAmountSats:    payResult.AmountSats,
+		RemainingBalance: remainingBalance,
+		Status:           tx.Status,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package card
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		liquidity int64
+		want      SplitPlan
+	}{
+		{"Lightning covers everything", 500_000, 1_000_000, SplitPlan{LightningSats: 500_000}},
+		{"Split at available liquidity", 1_000_000, 600_000, SplitPlan{LightningSats: 600_000, OnChainSats: 400_000}},
+		{"On-chain remainder raised to the minimum", 1_000_000, 995_000, SplitPlan{LightningSats: 990_000, OnChainSats: 10_000}},
+		{"No liquidity goes fully on-chain", 1_000_000, 0, SplitPlan{OnChainSats: 1_000_000}},
+		{"In-flight exceeds local balance", 1_000_000, -50_000, SplitPlan{OnChainSats: 1_000_000}},
+		{"Amount below the on-chain minimum (rejected by PlanSplitRedemption)", 8_000, 5_000, SplitPlan{OnChainSats: 8_000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, *planSplit(tt.amount, tt.liquidity))
+		})
+	}
+}
+
+func TestCheckSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		lightning int64
+		wantErr   bool
+	}{
+		{"Both legs", 1_000_000, 600_000, false},
+		{"Lightning only", 500_000, 500_000, false},
+		{"On-chain only", 1_000_000, 0, false},
+		{"On-chain remainder at the minimum", 1_000_000, 990_000, false},
+		{"On-chain remainder below the minimum", 1_000_000, 995_000, true},
+		{"On-chain only below the minimum", 8_000, 0, true},
+		{"Invoice exceeds the amount", 500_000, 600_000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSplit(tt.amount, tt.lightning)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSplit)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPlanSplit_PassesCheckSplit(t *testing.T) {
+	for _, amount := range []int64{10_000, 250_000, 1_000_000} {
+		for _, liquidity := range []int64{-50_000, 0, 5_000, 600_000, 995_000, 2_000_000} {
+			plan := planSplit(amount, liquidity)
+			assert.NoError(t, checkSplit(amount, plan.LightningSats), "amount %d, liquidity %d", amount, liquidity)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package card
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"btc-giftcard/internal/database"
+	messages "btc-giftcard/internal/queue"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSupportDisabled     = errors.New("support redemptions are not configured")
+	ErrSupportLinkInvalid  = errors.New("support redemption link is invalid")
+	ErrSupportLinkExpired  = errors.New("support redemption link has expired")
+	ErrSupportLinkUsed     = errors.New("support redemption link has already been used")
+	ErrSupportAmountExceed = errors.New("amount exceeds the support redemption cap")
+)
+
+const supportLinkUsedPrefix = "support_redemption:used:"
+
+// SupportConfig controls support-initiated redemptions
+// (populated from config.toml [support] section).
+type SupportConfig struct {
+	Secret  string        // HMAC key signing redemption links (empty disables support redemptions)
+	LinkTTL time.Duration // How long the customer has to confirm
+}
+
+// SupportRedemptionRequest is a support agent's request to redeem on a
+// customer's behalf. The card is identified by ID: agents never handle codes.
+type SupportRedemptionRequest struct {
+	CardID             string
+	AgentID            string           // Recorded in the link and the audit log
+	Method             RedeemCardMethod // "lightning" or "onchain"
+	MaxAmountSats      int64            // Cap on what the customer can confirm
+	DestinationAddress string           // On-chain address (required if method=onchain)
+	LightningInvoice   string           // BOLT11 invoice (required if method=lightning)
+}
+
+// SupportRedemption is what the agent gets back: the link itself only goes to
+// the card owner's email, so the agent can't confirm it.
+type SupportRedemption struct {
+	ID        string
+	CardID    string
+	ExpiresAt time.Time
+}
+
+// supportClaims is the signed content of a redemption link. It binds the
+// card, amount cap and destination so none can be changed after the agent
+// set them up.
+type supportClaims struct {
+	ID                 string           `json:"id"`
+	CardID             string           `json:"card_id"`
+	AgentID            string           `json:"agent_id"`
+	Method             RedeemCardMethod `json:"method"`
+	MaxAmountSats      int64            `json:"max_amount_sats"`
+	DestinationAddress string           `json:"destination_address,omitempty"`
+	LightningInvoice   string           `json:"lightning_invoice,omitempty"`
+	ExpiresAt          int64            `json:"exp"` // Unix seconds
+}
+
+// StartSupportRedemption creates a single-use redemption link for a card and
+// emails it to the card owner, who must confirm it within SupportConfig.LinkTTL.
+func (s *Service) StartSupportRedemption(ctx context.Context, req SupportRedemptionRequest) (*SupportRedemption, error) {
+	if s.cfg.Support.Secret == "" || s.cfg.Support.LinkTTL <= 0 {
+		return nil, ErrSupportDisabled
+	}
+	if req.AgentID == "" {
+		return nil, errors.New("agent ID is required")
+	}
+	if err := s.validateRedeemRequest(RedeemCardRequest{
+		Method:             req.Method,
+		AmountSats:         req.MaxAmountSats,
+		DestinationAddress: req.DestinationAddress,
+		LightningInvoice:   req.LightningInvoice,
+	}); err != nil {
+		return nil, err
+	}
+
+	card, err := s.cardRepo.GetByID(ctx, req.CardID)
+	if err != nil {
+		if errors.Is(err, database.ErrCardNotFound) {
+			return nil, ErrCardNotFound
+		}
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+	if card.Status != database.Active {
+		return nil, ErrCardNotActive
+	}
+	if req.MaxAmountSats > card.BTCAmountSats {
+		return nil, ErrInsufficientFunds
+	}
+
+	expiresAt := time.Now().UTC().Add(s.cfg.Support.LinkTTL).Truncate(time.Second)
+	claims := supportClaims{
+		ID:                 uuid.New().String(),
+		CardID:             card.ID,
+		AgentID:            req.AgentID,
+		Method:             req.Method,
+		MaxAmountSats:      req.MaxAmountSats,
+		DestinationAddress: req.DestinationAddress,
+		LightningInvoice:   req.LightningInvoice,
+		ExpiresAt:          expiresAt.Unix(),
+	}
+	token, err := signSupportClaims(s.cfg.Support.Secret, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := messages.SupportRedemptionMessage{
+		CardID:        card.ID,
+		OwnerEmail:    card.OwnerEmail,
+		Token:         token,
+		Method:        string(req.Method),
+		MaxAmountSats: req.MaxAmountSats,
+		ExpiresAt:     expiresAt,
+	}
+	msgJSON, err := msg.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	// Unlike other notifications this one is the whole point: fail if it can't be sent
+	if _, err := s.queue.Publish(ctx, "card_notifications", msgJSON); err != nil {
+		return nil, fmt.Errorf("failed to publish support redemption link: %w", err)
+	}
+
+	logger.Info("Support redemption link sent",
+		zap.String("link_id", claims.ID),
+		zap.String("card_id", card.ID),
+		zap.String("agent_id", req.AgentID),
+		zap.String("method", string(req.Method)),
+		zap.Int64("max_amount_sats", req.MaxAmountSats),
+	)
+
+	return &SupportRedemption{ID: claims.ID, CardID: card.ID, ExpiresAt: expiresAt}, nil
+}
+
+// ConfirmSupportRedemption redeems a card from the owner's confirmation of a
+// support redemption link. amountSats may be lower than the link's cap
+// (0 redeems the full cap); for Lightning the bound invoice sets the amount.
+// A link can only be confirmed once, even if the redemption then fails.
+func (s *Service) ConfirmSupportRedemption(ctx context.Context, token string, amountSats int64) (*RedeemCardResponse, error) {
+	if s.cfg.Support.Secret == "" {
+		return nil, ErrSupportDisabled
+	}
+
+	claims, err := verifySupportToken(s.cfg.Support.Secret, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if amountSats == 0 {
+		amountSats = claims.MaxAmountSats
+	}
+	if amountSats > claims.MaxAmountSats {
+		return nil, ErrSupportAmountExceed
+	}
+
+	// Claim the link before redeeming so concurrent confirmations can't both pay
+	ttl := time.Until(time.Unix(claims.ExpiresAt, 0)) + time.Minute
+	fresh, err := cache.SetNX(ctx, supportLinkUsedPrefix+claims.ID, "1", ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim support redemption link: %w", err)
+	}
+	if !fresh {
+		return nil, ErrSupportLinkUsed
+	}
+
+	card, err := s.cardRepo.GetByID(ctx, claims.CardID)
+	if err != nil {
+		if errors.Is(err, database.ErrCardNotFound) {
+			return nil, ErrCardNotFound
+		}
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+
+	logger.Info("Support redemption confirmed by card owner",
+		zap.String("link_id", claims.ID),
+		zap.String("card_id", claims.CardID),
+		zap.String("agent_id", claims.AgentID),
+		zap.Int64("amount_sats", amountSats),
+	)
+
+	return s.RedeemCard(ctx, RedeemCardRequest{
+		Code:               card.Code,
+		Method:             claims.Method,
+		AmountSats:         amountSats,
+		DestinationAddress: claims.DestinationAddress,
+		LightningInvoice:   claims.LightningInvoice,
+	})
+}
+
+// signSupportClaims encodes claims as base64url(JSON) + "." + base64url(HMAC-SHA256).
+func signSupportClaims(secret string, claims supportClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal support redemption link: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(supportMAC(secret, encoded)), nil
+}
+
+// verifySupportToken checks a link's signature and expiry at time now.
+func verifySupportToken(secret, token string, now time.Time) (*supportClaims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrSupportLinkInvalid
+	}
+
+	given, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(given, supportMAC(secret, encoded)) {
+		return nil, ErrSupportLinkInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrSupportLinkInvalid
+	}
+	var claims supportClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrSupportLinkInvalid
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrSupportLinkExpired
+	}
+	return &claims, nil
+}
+
+func supportMAC(secret, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
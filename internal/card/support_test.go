@@ -0,0 +1,62 @@
+package card
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportToken_RoundTrip(t *testing.T) {
+	now := time.Now()
+	claims := supportClaims{
+		ID:                 "link-1",
+		CardID:             "card-1",
+		AgentID:            "agent-7",
+		Method:             OnChain,
+		MaxAmountSats:      50_000,
+		DestinationAddress: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+		ExpiresAt:          now.Add(15 * time.Minute).Unix(),
+	}
+
+	token, err := signSupportClaims("secret", claims)
+	require.NoError(t, err)
+
+	got, err := verifySupportToken("secret", token, now)
+	require.NoError(t, err)
+	assert.Equal(t, claims, *got)
+
+	_, err = verifySupportToken("other-secret", token, now)
+	assert.ErrorIs(t, err, ErrSupportLinkInvalid, "wrong key")
+
+	_, err = verifySupportToken("secret", token, now.Add(15*time.Minute))
+	assert.ErrorIs(t, err, ErrSupportLinkExpired)
+}
+
+func TestSupportToken_Tampered(t *testing.T) {
+	token, err := signSupportClaims("secret", supportClaims{ID: "link-1", CardID: "card-1", MaxAmountSats: 50_000, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+
+	// Raising the cap invalidates the signature
+	raised, err := signSupportClaims("attacker", supportClaims{ID: "link-1", CardID: "card-1", MaxAmountSats: 5_000_000, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	require.NoError(t, err)
+	payload, _, _ := strings.Cut(raised, ".")
+	_, sig, _ := strings.Cut(token, ".")
+
+	for _, bad := range []string{payload + "." + sig, "no-dot", token + "x", ""} {
+		_, err := verifySupportToken("secret", bad, time.Now())
+		assert.ErrorIs(t, err, ErrSupportLinkInvalid, bad)
+	}
+}
+
+func TestStartSupportRedemption_Disabled(t *testing.T) {
+	s := &Service{cfg: Config{}}
+
+	_, err := s.StartSupportRedemption(context.Background(), SupportRedemptionRequest{CardID: "card-1"})
+	assert.ErrorIs(t, err, ErrSupportDisabled)
+	_, err = s.ConfirmSupportRedemption(context.Background(), "token", 0)
+	assert.ErrorIs(t, err, ErrSupportDisabled)
+}
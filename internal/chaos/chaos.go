@@ -0,0 +1,43 @@
+// Package chaos provides injectable failure points for resilience testing.
+//
+// Faults are configured with the CHAOS_FAULTS environment variable, a
+// comma-separated list of point=value pairs. Failure points take a
+// probability (0-1), delay points a duration:
+//
+//	CHAOS_FAULTS="fund.after_card_update=0.2,worker.ack_delay=30s,lnd.disconnect=0.05"
+//
+// Injection is only compiled in with the chaos build tag; without it every
+// point is a no-op and CHAOS_FAULTS is never read, so release builds carry no
+// fault injection at all. Even then a point is a no-op unless configured, and
+// CHAOS_FAULTS is ignored when ENVIRONMENT=production. The integration tests
+// (go test -tags "integration chaos") assert that the recovery paths (message
+// redelivery, idempotency, funding reconciliation, ledger checks) heal or
+// surface every fault injected here.
+package chaos
+
+import "errors"
+
+// Point names a place in the code where a fault can be injected.
+type Point string
+
+const (
+	// FundAfterCardUpdate fails a funding tranche after the card balance is
+	// updated but before its Fund transaction is recorded.
+	FundAfterCardUpdate Point = "fund.after_card_update"
+	// WorkerAckDelay delays a worker's ACK after the handler succeeded.
+	WorkerAckDelay Point = "worker.ack_delay"
+	// LNDDisconnect drops the LND connection before a payment is sent.
+	LNDDisconnect Point = "lnd.disconnect"
+	// RedeemAfterCardUpdate fails a redemption after the payment was sent and
+	// the card charged, but before its Redeem transaction is recorded.
+	RedeemAfterCardUpdate Point = "redeem.after_card_update"
+)
+
+// Known lists every injection point, for validating CHAOS_FAULTS.
+var Known = []Point{FundAfterCardUpdate, WorkerAckDelay, LNDDisconnect, RedeemAfterCardUpdate}
+
+// ErrInjected is returned by an injected failure.
+var ErrInjected = errors.New("chaos: injected failure")
+
+// EnvVar is the environment variable faults are read from.
+const EnvVar = "CHAOS_FAULTS"
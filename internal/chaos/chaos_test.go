@@ -0,0 +1,68 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"btc-giftcard/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Initialize logger for tests
+	_ = logger.Init("development")
+}
+
+func TestParse(t *testing.T) {
+	faults, err := parse(" fund.after_card_update=0.25, worker.ack_delay=30s ,lnd.disconnect=1,")
+	require.NoError(t, err)
+	assert.Equal(t, fault{probability: 0.25}, faults[FundAfterCardUpdate])
+	assert.Equal(t, fault{delay: 30 * time.Second}, faults[WorkerAckDelay])
+	assert.Equal(t, fault{probability: 1}, faults[LNDDisconnect])
+
+	for _, spec := range []string{
+		"fund.after_card_updat=0.5",  // typo
+		"fund.after_card_update",     // no value
+		"fund.after_card_update=1.5", // not a probability
+		"worker.ack_delay=0.5",       // not a duration
+	} {
+		_, err := parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestFail(t *testing.T) {
+	defer Configure("")
+
+	require.NoError(t, Configure("fund.after_card_update=1"))
+	assert.ErrorIs(t, Fail(FundAfterCardUpdate), ErrInjected)
+	assert.NoError(t, Fail(LNDDisconnect), "unconfigured point")
+
+	require.NoError(t, Configure("fund.after_card_update=0"))
+	assert.NoError(t, Fail(FundAfterCardUpdate))
+
+	require.NoError(t, Configure(""))
+	assert.NoError(t, Fail(FundAfterCardUpdate))
+}
+
+func TestDelay(t *testing.T) {
+	defer Configure("")
+	require.NoError(t, Configure("worker.ack_delay=50ms"))
+
+	start := time.Now()
+	Delay(context.Background(), WorkerAckDelay)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// A cancelled context cuts the delay short
+	require.NoError(t, Configure("worker.ack_delay=1h"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start = time.Now()
+	Delay(ctx, WorkerAckDelay)
+	assert.Less(t, time.Since(start), time.Second)
+}
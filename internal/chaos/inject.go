@@ -0,0 +1,153 @@
+//go:build chaos
+
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"btc-giftcard/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// fault is the configuration of one point.
+type fault struct {
+	probability float64       // Failure points: chance of failing each call
+	delay       time.Duration // Delay points: how long to wait
+}
+
+var (
+	mu       sync.RWMutex
+	faults   map[Point]fault
+	loadOnce sync.Once
+)
+
+// Configure replaces the active faults with spec (CHAOS_FAULTS syntax);
+// an empty spec disables them all. Tests use it instead of the environment.
+func Configure(spec string) error {
+	loadOnce.Do(func() {}) // An explicit configuration wins over the environment
+
+	parsed, err := parse(spec)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	faults = parsed
+	mu.Unlock()
+	return nil
+}
+
+// Fail returns ErrInjected (wrapped with the point name) if p is configured
+// and its probability fires, nil otherwise.
+func Fail(p Point) error {
+	f, ok := lookup(p)
+	if !ok || f.probability <= 0 || rand.Float64() >= f.probability {
+		return nil
+	}
+
+	logger.Warn("Chaos: injecting failure", zap.String("point", string(p)))
+	return fmt.Errorf("%w at %s", ErrInjected, p)
+}
+
+// Delay waits for p's configured duration, or until ctx is done.
+func Delay(ctx context.Context, p Point) {
+	f, ok := lookup(p)
+	if !ok || f.delay <= 0 {
+		return
+	}
+
+	logger.Warn("Chaos: injecting delay", zap.String("point", string(p)), zap.Duration("delay", f.delay))
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+	}
+}
+
+func lookup(p Point) (fault, bool) {
+	loadOnce.Do(loadEnv)
+
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := faults[p]
+	return f, ok
+}
+
+// loadEnv reads CHAOS_FAULTS once, on first use.
+func loadEnv() {
+	spec := os.Getenv(EnvVar)
+	if spec == "" {
+		return
+	}
+	if logger.GetEnv() == "production" {
+		logger.Warn("Chaos: ignoring " + EnvVar + " in production")
+		return
+	}
+
+	parsed, err := parse(spec)
+	if err != nil {
+		logger.Error("Chaos: invalid "+EnvVar+", no faults injected", zap.Error(err))
+		return
+	}
+
+	mu.Lock()
+	faults = parsed
+	mu.Unlock()
+	logger.Warn("Chaos: fault injection enabled", zap.String("faults", spec))
+}
+
+// parse parses a CHAOS_FAULTS spec.
+func parse(spec string) (map[Point]fault, error) {
+	parsed := make(map[Point]fault)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("chaos: %q is not point=value", entry)
+		}
+		p, err := knownPoint(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		value = strings.TrimSpace(value)
+
+		if p == WorkerAckDelay {
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("chaos: %s needs a duration, got %q", p, value)
+			}
+			parsed[p] = fault{delay: d}
+			continue
+		}
+
+		prob, err := strconv.ParseFloat(value, 64)
+		if err != nil || prob < 0 || prob > 1 {
+			return nil, fmt.Errorf("chaos: %s needs a probability between 0 and 1, got %q", p, value)
+		}
+		parsed[p] = fault{probability: prob}
+	}
+
+	return parsed, nil
+}
+
+// knownPoint rejects typos so a misspelled fault doesn't silently do nothing.
+func knownPoint(name string) (Point, error) {
+	for _, p := range Known {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("chaos: unknown injection point %q", name)
+}
@@ -0,0 +1,23 @@
+//go:build !chaos
+
+package chaos
+
+import (
+	"context"
+	"errors"
+)
+
+// Configure fails: this binary was built without the chaos tag, so there is
+// nothing to configure. Failing keeps a chaos test from passing vacuously.
+func Configure(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	return errors.New("chaos: fault injection needs the chaos build tag")
+}
+
+// Fail never fails without the chaos build tag.
+func Fail(p Point) error { return nil }
+
+// Delay never waits without the chaos build tag.
+func Delay(ctx context.Context, p Point) {}
@@ -0,0 +1,24 @@
+//go:build !chaos
+
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Without the chaos build tag nothing can be injected, whatever the environment says.
+func TestNoop(t *testing.T) {
+	t.Setenv(EnvVar, "lnd.disconnect=1,worker.ack_delay=1h")
+
+	assert.Error(t, Configure("lnd.disconnect=1"))
+	assert.NoError(t, Configure(""))
+	assert.NoError(t, Fail(LNDDisconnect))
+
+	start := time.Now()
+	Delay(context.Background(), WorkerAckDelay)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrBatchNotFound is returned when no retail card batch matches the lookup
+var ErrBatchNotFound = errors.New("card batch not found")
+
+// BatchRepository handles all database operations for batches of
+// pre-generated retail cards
+type BatchRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewBatchRepository creates a new batch repository instance
+func NewBatchRepository(db *DB) *BatchRepository {
+	return &BatchRepository{
+		db: db.pool,
+	}
+}
+
+// Create inserts a batch together with its inactive cards (one per code), in
+// a single statement so a batch is never left half-generated. cardIDs and
+// codes must have one entry per card. Returns ErrCardCodeExists if any code
+// is already taken (nothing is inserted).
+func (r *BatchRepository) Create(ctx context.Context, batch *CardBatch, cardIDs, codes []string) error {
+	if len(cardIDs) != len(codes) || len(codes) != batch.Quantity {
+		return fmt.Errorf("batch %s: %d card IDs and %d codes for quantity %d", batch.ID, len(cardIDs), len(codes), batch.Quantity)
+	}
+
+	query := `WITH batch AS (
+		INSERT INTO card_batches (
+			id,
+			retailer,
+			product_id,
+			fiat_amount_cents,
+			fiat_currency,
+			quantity,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	)
+	INSERT INTO cards (
+		id, code, purchase_email, owner_email,
+		fiat_amount_cents, fiat_currency, purchase_price_cents,
+		status, created_at, product_id, batch_id
+	)
+	SELECT c.id, c.code, '', '', $4, $5, 0, 'inactive', $7, $3, batch.id
+	FROM batch, unnest($8::uuid[], $9::text[]) AS c (id, code)`
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		batch.ID,
+		batch.Retailer,
+		batch.ProductID,
+		batch.FiatAmountCents,
+		batch.FiatCurrency,
+		batch.Quantity,
+		batch.CreatedAt,
+		cardIDs,
+		codes,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			return ErrCardCodeExists
+		}
+		return fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a batch by ID. Returns ErrBatchNotFound if missing.
+func (r *BatchRepository) GetByID(ctx context.Context, id string) (*CardBatch, error) {
+	query := `SELECT
+		id, retailer, product_id, fiat_amount_cents, fiat_currency,
+		quantity, created_at, printed_at
+	FROM card_batches WHERE id = $1`
+
+	var b CardBatch
+
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&b.ID,
+		&b.Retailer,
+		&b.ProductID,
+		&b.FiatAmountCents,
+		&b.FiatCurrency,
+		&b.Quantity,
+		&b.CreatedAt,
+		&b.PrintedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBatchNotFound
+		}
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+
+	return &b, nil
+}
+
+// List returns up to limit batches, newest first, with how many of their
+// cards have been activated.
+func (r *BatchRepository) List(ctx context.Context, limit int) ([]*BatchSummary, error) {
+	query := `SELECT
+		b.id, b.retailer, b.product_id, b.fiat_amount_cents, b.fiat_currency,
+		b.quantity, b.created_at, b.printed_at,
+		COUNT(c.id) FILTER (WHERE c.status <> 'inactive')
+	FROM card_batches b
+	LEFT JOIN cards c ON c.batch_id = b.id
+	GROUP BY b.id
+	ORDER BY b.created_at DESC
+	LIMIT $1`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*BatchSummary
+	for rows.Next() {
+		var b BatchSummary
+
+		err := rows.Scan(
+			&b.ID,
+			&b.Retailer,
+			&b.ProductID,
+			&b.FiatAmountCents,
+			&b.FiatCurrency,
+			&b.Quantity,
+			&b.CreatedAt,
+			&b.PrintedAt,
+			&b.ActivatedCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch row: %w", err)
+		}
+
+		batches = append(batches, &b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return batches, nil
+}
+
+// Codes returns the codes of a batch's cards, sorted, for printing.
+func (r *BatchRepository) Codes(ctx context.Context, batchID string) ([]string, error) {
+	query := `SELECT code FROM cards WHERE batch_id = $1 ORDER BY code`
+
+	rows, err := r.db.Query(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get codes of batch %s: %w", batchID, err)
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("failed to scan batch code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return codes, nil
+}
+
+// MarkPrinted records when a batch's codes were exported for printing
+// (kept if already set, so re-exports don't hide the first print run).
+// Returns ErrBatchNotFound if the batch ID does not exist.
+func (r *BatchRepository) MarkPrinted(ctx context.Context, id string, printedAt time.Time) error {
+	query := `UPDATE card_batches SET printed_at = COALESCE(printed_at, $2) WHERE id = $1`
+
+	commandTag, err := r.db.Exec(ctx, query, id, printedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark batch %s printed: %w", id, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrBatchNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchRepository(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	batches := NewBatchRepository(db)
+	cards := NewCardRepository(db)
+
+	batch := &CardBatch{
+		ID:              uuid.New().String(),
+		Retailer:        "acme-stores",
+		FiatAmountCents: 2500,
+		FiatCurrency:    "EUR",
+		Quantity:        2,
+		CreatedAt:       time.Now().UTC(),
+	}
+	cardIDs := []string{uuid.New().String(), uuid.New().String()}
+	codes := []string{"GIFT-BTCH-TEST-0002", "GIFT-BTCH-TEST-0001"}
+	require.NoError(t, batches.Create(ctx, batch, cardIDs, codes))
+
+	// Cards are inactive with zero balance
+	card, err := cards.GetByCode(ctx, "GIFT-BTCH-TEST-0001")
+	require.NoError(t, err)
+	assert.Equal(t, Inactive, card.Status)
+	assert.Equal(t, int64(0), card.BTCAmountSats)
+	require.NotNil(t, card.BatchID)
+	assert.Equal(t, batch.ID, *card.BatchID)
+
+	got, err := batches.Codes(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GIFT-BTCH-TEST-0001", "GIFT-BTCH-TEST-0002"}, got)
+
+	// A code that already exists fails the whole batch
+	dup := &CardBatch{ID: uuid.New().String(), Retailer: "acme-stores", FiatAmountCents: 2500, FiatCurrency: "EUR", Quantity: 1, CreatedAt: time.Now().UTC()}
+	assert.ErrorIs(t, batches.Create(ctx, dup, []string{uuid.New().String()}, []string{"GIFT-BTCH-TEST-0001"}), ErrCardCodeExists)
+	_, err = batches.GetByID(ctx, dup.ID)
+	assert.ErrorIs(t, err, ErrBatchNotFound)
+
+	// Activation only succeeds once
+	require.NoError(t, cards.Activate(ctx, card.ID, "buyer@example.com", 2500, time.Now().UTC()))
+	assert.ErrorIs(t, cards.Activate(ctx, card.ID, "buyer@example.com", 2500, time.Now().UTC()), ErrCardNotInactive)
+
+	activated, err := cards.GetByID(ctx, card.ID)
+	require.NoError(t, err)
+	assert.Equal(t, Created, activated.Status)
+	assert.Equal(t, "buyer@example.com", activated.OwnerEmail)
+	assert.NotNil(t, activated.ActivatedAt)
+
+	list, err := batches.List(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(1), list[0].ActivatedCount)
+
+	require.NoError(t, batches.MarkPrinted(ctx, batch.ID, time.Now().UTC()))
+	printed, err := batches.GetByID(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, printed.PrintedAt)
+}
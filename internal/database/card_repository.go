@@ -16,6 +16,10 @@ var (
 	ErrCardNotFound = errors.New("card not found")
 	// ErrCardCodeExists is returned when trying to create a card with an existing code
 	ErrCardCodeExists = errors.New("card code already exists")
+	// ErrCardNotInactive is returned when activating a card that isn't an inactive retail card
+	ErrCardNotInactive = errors.New("card is not an inactive retail card")
+	// ErrRetiredCodeNotFound is returned when a code was never retired
+	ErrRetiredCodeNotFound = errors.New("retired card code not found")
 )
 
 // CardRepository handles all database operations for cards
@@ -46,9 +50,15 @@ func (r *CardRepository) Create(ctx context.Context, card *Card) error {
 		status,
 		created_at,
 		funded_at,
-		redeemed_at
+		redeemed_at,
+		funded_sats,
+		funding_target_sats,
+		product_id,
+		batch_id,
+		activated_at,
+		merchant_id
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
 
 	_, err := r.db.Exec(
 		ctx,
@@ -66,6 +76,12 @@ func (r *CardRepository) Create(ctx context.Context, card *Card) error {
 		card.CreatedAt,
 		card.FundedAt,
 		card.RedeemedAt,
+		card.FundedSats,
+		card.FundingTargetSats,
+		card.ProductID,
+		card.BatchID,
+		card.ActivatedAt,
+		card.MerchantID,
 	)
 
 	if err != nil {
@@ -90,7 +106,8 @@ func (r *CardRepository) GetByCode(ctx context.Context, code string) (*Card, err
 	query := `SELECT 
         id, user_id, purchase_email, owner_email, code,
         btc_amount_sats, fiat_amount_cents, fiat_currency, purchase_price_cents,
-        status, created_at, funded_at, redeemed_at
+        status, created_at, funded_at, redeemed_at,
+        funded_sats, funding_target_sats, product_id, batch_id, activated_at, merchant_id
     FROM cards WHERE code = $1`
 
 	var card Card
@@ -109,6 +126,12 @@ func (r *CardRepository) GetByCode(ctx context.Context, code string) (*Card, err
 		&card.CreatedAt,
 		&card.FundedAt,
 		&card.RedeemedAt,
+		&card.FundedSats,
+		&card.FundingTargetSats,
+		&card.ProductID,
+		&card.BatchID,
+		&card.ActivatedAt,
+		&card.MerchantID,
 	)
 
 	if err != nil {
@@ -127,7 +150,8 @@ func (r *CardRepository) GetByID(ctx context.Context, id string) (*Card, error)
 	query := `SELECT 
         id, user_id, purchase_email, owner_email, code,
         btc_amount_sats, fiat_amount_cents, fiat_currency, purchase_price_cents,
-        status, created_at, funded_at, redeemed_at
+        status, created_at, funded_at, redeemed_at,
+        funded_sats, funding_target_sats, product_id, batch_id, activated_at, merchant_id
     FROM cards WHERE id = $1`
 
 	var card Card
@@ -146,6 +170,12 @@ func (r *CardRepository) GetByID(ctx context.Context, id string) (*Card, error)
 		&card.CreatedAt,
 		&card.FundedAt,
 		&card.RedeemedAt,
+		&card.FundedSats,
+		&card.FundingTargetSats,
+		&card.ProductID,
+		&card.BatchID,
+		&card.ActivatedAt,
+		&card.MerchantID,
 	)
 
 	if err != nil {
@@ -181,13 +211,36 @@ func (r *CardRepository) Update(ctx context.Context, id string, status CardStatu
 	return nil
 }
 
+// Credit adds sats back to a card's balance (e.g., a payout returned by the
+// bank after the balance was deducted). A card redeemed by that payout becomes
+// active again. Returns ErrCardNotFound if the card ID does not exist.
+func (r *CardRepository) Credit(ctx context.Context, id string, sats int64) error {
+	query := `UPDATE cards
+		SET btc_amount_sats = btc_amount_sats + $2,
+			status = CASE WHEN status = 'redeemed' THEN 'active'::card_status ELSE status END,
+			redeemed_at = CASE WHEN status = 'redeemed' THEN NULL ELSE redeemed_at END
+		WHERE id = $1`
+
+	commandTag, err := r.db.Exec(ctx, query, id, sats)
+	if err != nil {
+		return fmt.Errorf("failed to credit card with id %s: %w", id, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrCardNotFound
+	}
+
+	return nil
+}
+
 // ListByUserID retrieves all cards belonging to a user, ordered by creation date (newest first).
 // Returns an empty slice if the user has no cards.
 func (r *CardRepository) ListByUserID(ctx context.Context, userID string) ([]*Card, error) {
 	query := `SELECT 
         id, user_id, purchase_email, owner_email, code,
         btc_amount_sats, fiat_amount_cents, fiat_currency, purchase_price_cents,
-        status, created_at, funded_at, redeemed_at
+        status, created_at, funded_at, redeemed_at,
+        funded_sats, funding_target_sats, product_id, batch_id, activated_at, merchant_id
     FROM cards WHERE user_id = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, userID)
@@ -214,6 +267,12 @@ func (r *CardRepository) ListByUserID(ctx context.Context, userID string) ([]*Ca
 			&card.CreatedAt,
 			&card.FundedAt,
 			&card.RedeemedAt,
+			&card.FundedSats,
+			&card.FundingTargetSats,
+			&card.ProductID,
+			&card.BatchID,
+			&card.ActivatedAt,
+			&card.MerchantID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan card row: %w", err)
@@ -230,10 +289,15 @@ func (r *CardRepository) ListByUserID(ctx context.Context, userID string) ([]*Ca
 	return cards, nil
 }
 
-// GetTotalReservedBalance returns the sum of btc_amount_sats for all cards
-// with status 'active' or 'funding'. These represent reserved treasury funds.
+// GetTotalReservedBalance returns the treasury funds owed to someone else:
+// the sum of btc_amount_sats for all cards with status 'active', 'funding',
+// 'partially_funded' or 'refunding', plus the balances deposits credited to
+// accounts in treasury_ledger (house treasury entries have no account and
+// aren't owed).
 func (r *CardRepository) GetTotalReservedBalance(ctx context.Context) (int64, error) {
-	query := `SELECT COALESCE(SUM(btc_amount_sats), 0) FROM cards WHERE status IN ('active', 'funding')`
+	query := `SELECT
+		(SELECT COALESCE(SUM(btc_amount_sats), 0) FROM cards WHERE status IN ('active', 'funding', 'partially_funded', 'refunding'))
+		+ (SELECT COALESCE(SUM(amount_sats), 0) FROM treasury_ledger WHERE account_id IS NOT NULL)`
 
 	var totalReservedBalance int64
 	err := r.db.QueryRow(ctx, query).Scan(&totalReservedBalance)
@@ -243,3 +307,215 @@ func (r *CardRepository) GetTotalReservedBalance(ctx context.Context) (int64, er
 
 	return totalReservedBalance, nil
 }
+
+// UpdateFunding records a funding tranche: the card's balance and funded_sats
+// become fundedSats and the funding target is set (kept if nil).
+// fundedAt is only set once, when the card becomes fully funded.
+// Returns ErrCardNotFound if the card ID does not exist.
+func (r *CardRepository) UpdateFunding(ctx context.Context, id string, status CardStatus, fundedSats int64, targetSats *int64, fundedAt *time.Time) error {
+	query := `UPDATE cards
+		SET status = $2,
+			btc_amount_sats = $3,
+			funded_sats = $3,
+			funding_target_sats = COALESCE($4, funding_target_sats),
+			funded_at = COALESCE($5, funded_at)
+		WHERE id = $1`
+
+	commandTag, err := r.db.Exec(ctx, query, id, status, fundedSats, targetSats, fundedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update funding of card %s: %w", id, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrCardNotFound
+	}
+
+	return nil
+}
+
+// RotateCode replaces a card's code and records the old one in
+// retired_card_codes, in a single statement. Returns false without error if
+// the card's code is no longer oldCode (rotated concurrently), and
+// ErrCardCodeExists if newCode is taken.
+func (r *CardRepository) RotateCode(ctx context.Context, id, oldCode, newCode string, retiredAt time.Time) (bool, error) {
+	query := `WITH rotated AS (
+		UPDATE cards SET code = $3 WHERE id = $1 AND code = $2
+		RETURNING id
+	)
+	INSERT INTO retired_card_codes (code, card_id, retired_at)
+	SELECT $2, id, $4 FROM rotated`
+
+	commandTag, err := r.db.Exec(ctx, query, id, oldCode, newCode, retiredAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "cards_code_key" { // unique_violation
+			return false, ErrCardCodeExists
+		}
+		return false, fmt.Errorf("failed to rotate code of card %s: %w", id, err)
+	}
+
+	return commandTag.RowsAffected() == 1, nil
+}
+
+// GetRetiredCode returns the code retired by RotateCode, if code is one.
+// Returns ErrRetiredCodeNotFound otherwise.
+func (r *CardRepository) GetRetiredCode(ctx context.Context, code string) (*RetiredCardCode, error) {
+	var retired RetiredCardCode
+	err := r.db.QueryRow(ctx, `SELECT code, card_id, retired_at FROM retired_card_codes WHERE code = $1`, code).
+		Scan(&retired.Code, &retired.CardID, &retired.RetiredAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRetiredCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get retired code: %w", err)
+	}
+	return &retired, nil
+}
+
+// SetFundingPrice records the BTC price (fiat per BTC) the card was priced at,
+// for the conversion accuracy report (OTCFillRepository.WeeklyAccuracy).
+// Returns ErrCardNotFound if the card ID does not exist.
+func (r *CardRepository) SetFundingPrice(ctx context.Context, id string, price float64) error {
+	commandTag, err := r.db.Exec(ctx, `UPDATE cards SET funding_price = $2 WHERE id = $1`, id, price)
+	if err != nil {
+		return fmt.Errorf("failed to set funding price of card %s: %w", id, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrCardNotFound
+	}
+
+	return nil
+}
+
+// ListByStatus retrieves up to limit cards with the given status, oldest first.
+func (r *CardRepository) ListByStatus(ctx context.Context, status CardStatus, limit int) ([]*Card, error) {
+	query := `SELECT 
+        id, user_id, purchase_email, owner_email, code,
+        btc_amount_sats, fiat_amount_cents, fiat_currency, purchase_price_cents,
+        status, created_at, funded_at, redeemed_at,
+        funded_sats, funding_target_sats, product_id, batch_id, activated_at, merchant_id
+    FROM cards WHERE status = $1 ORDER BY created_at ASC LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cards with status %s: %w", status, err)
+	}
+	defer rows.Close()
+
+	var cards []*Card
+	for rows.Next() {
+		var card Card
+
+		err := rows.Scan(
+			&card.ID,
+			&card.UserID,
+			&card.PurchaseEmail,
+			&card.OwnerEmail,
+			&card.Code,
+			&card.BTCAmountSats,
+			&card.FiatAmountCents,
+			&card.FiatCurrency,
+			&card.PurchasePriceCents,
+			&card.Status,
+			&card.CreatedAt,
+			&card.FundedAt,
+			&card.RedeemedAt,
+			&card.FundedSats,
+			&card.FundingTargetSats,
+			&card.ProductID,
+			&card.BatchID,
+			&card.ActivatedAt,
+			&card.MerchantID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card row: %w", err)
+		}
+
+		cards = append(cards, &card)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return cards, nil
+}
+
+// Activate turns an inactive retail card into a created card once its POS
+// payment is captured, recording the buyer and the price charged. The status
+// guard makes concurrent activations of the same card fail instead of both
+// succeeding. Returns ErrCardNotInactive if the card isn't inactive.
+func (r *CardRepository) Activate(ctx context.Context, id, purchaseEmail string, purchasePriceCents int64, activatedAt time.Time) error {
+	query := `UPDATE cards
+		SET status = 'created',
+			purchase_email = $2,
+			owner_email = $2,
+			purchase_price_cents = $3,
+			activated_at = $4
+		WHERE id = $1 AND status = 'inactive'`
+
+	commandTag, err := r.db.Exec(ctx, query, id, purchaseEmail, purchasePriceCents, activatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to activate card %s: %w", id, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrCardNotInactive
+	}
+
+	return nil
+}
+
+// ReserveRefund moves an active card holding exactly balanceSats to
+// 'refunding', so it can't be spent while the payment provider processes the
+// refund. The balance stays on the card (and reserved) until CommitRefund.
+// Returns false without error if the card is no longer active with that balance.
+func (r *CardRepository) ReserveRefund(ctx context.Context, id string, balanceSats int64) (bool, error) {
+	query := `UPDATE cards SET status = 'refunding'
+		WHERE id = $1 AND status = 'active' AND btc_amount_sats = $2`
+
+	commandTag, err := r.db.Exec(ctx, query, id, balanceSats)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve refund for card %s: %w", id, err)
+	}
+
+	return commandTag.RowsAffected() == 1, nil
+}
+
+// CommitRefund zeroes a refunding card's balance and marks it refunded, once
+// the payment provider accepted the refund. Returns ErrCardNotFound if the
+// card isn't refunding.
+func (r *CardRepository) CommitRefund(ctx context.Context, id string) error {
+	query := `UPDATE cards SET status = 'refunded', btc_amount_sats = 0
+		WHERE id = $1 AND status = 'refunding'`
+
+	commandTag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to commit refund for card %s: %w", id, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrCardNotFound
+	}
+
+	return nil
+}
+
+// ReleaseRefund returns a refunding card to 'active' with its balance intact,
+// after the payment provider declined the refund. Returns ErrCardNotFound if
+// the card isn't refunding.
+func (r *CardRepository) ReleaseRefund(ctx context.Context, id string) error {
+	query := `UPDATE cards SET status = 'active' WHERE id = $1 AND status = 'refunding'`
+
+	commandTag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to release refund for card %s: %w", id, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrCardNotFound
+	}
+
+	return nil
+}
@@ -198,6 +198,160 @@ func TestCardRepository_Update(t *testing.T) {
 	assert.WithinDuration(t, redeemedAt, *retrieved.RedeemedAt, time.Second) // Verify redeemed time set correctly
 }
 
+func TestCardRepository_UpdateFunding(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewCardRepository(db)
+	ctx := context.Background()
+
+	cardID := uuid.New().String()
+	card := &Card{
+		ID:                 cardID,
+		PurchaseEmail:      "corp@example.com",
+		OwnerEmail:         "corp@example.com",
+		Code:               "TRANCHE-TEST",
+		FiatAmountCents:    5000000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5150000,
+		Status:             Funding,
+		CreatedAt:          time.Now().UTC(),
+	}
+	require.NoError(t, repo.Create(ctx, card))
+
+	// First tranche: partially funded, target recorded, no funded_at yet
+	target := int64(75_000_000)
+	err := repo.UpdateFunding(ctx, cardID, PartiallyFunded, 30_000_000, &target, nil)
+	require.NoError(t, err)
+
+	retrieved, err := repo.GetByID(ctx, cardID)
+	require.NoError(t, err)
+	assert.Equal(t, PartiallyFunded, retrieved.Status)
+	assert.Equal(t, int64(30_000_000), retrieved.FundedSats)
+	assert.Equal(t, int64(30_000_000), retrieved.BTCAmountSats)
+	require.NotNil(t, retrieved.FundingTargetSats)
+	assert.Equal(t, target, *retrieved.FundingTargetSats)
+	assert.Nil(t, retrieved.FundedAt)
+
+	partial, err := repo.ListByStatus(ctx, PartiallyFunded, 10)
+	require.NoError(t, err)
+	require.Len(t, partial, 1)
+	assert.Equal(t, cardID, partial[0].ID)
+
+	// Final tranche: active, target preserved via COALESCE
+	fundedAt := time.Now().UTC()
+	err = repo.UpdateFunding(ctx, cardID, Active, target, nil, &fundedAt)
+	require.NoError(t, err)
+
+	retrieved, err = repo.GetByID(ctx, cardID)
+	require.NoError(t, err)
+	assert.Equal(t, Active, retrieved.Status)
+	assert.Equal(t, target, retrieved.FundedSats)
+	assert.Equal(t, target, retrieved.BTCAmountSats)
+	require.NotNil(t, retrieved.FundingTargetSats)
+	assert.Equal(t, target, *retrieved.FundingTargetSats)
+	require.NotNil(t, retrieved.FundedAt)
+
+	partial, err = repo.ListByStatus(ctx, PartiallyFunded, 10)
+	require.NoError(t, err)
+	assert.Empty(t, partial)
+}
+
+func TestCardRepository_Credit(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewCardRepository(db)
+	ctx := context.Background()
+
+	cardID := uuid.New().String()
+	require.NoError(t, repo.Create(ctx, &Card{
+		ID:                 cardID,
+		PurchaseEmail:      "test@example.com",
+		OwnerEmail:         "test@example.com",
+		Code:               "CREDIT-TEST",
+		FiatAmountCents:    5000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5000,
+		Status:             Created,
+		CreatedAt:          time.Now().UTC(),
+	}))
+
+	// Fully spent card gets a returned payout back
+	zero := int64(0)
+	redeemedAt := time.Now().UTC()
+	require.NoError(t, repo.Update(ctx, cardID, Redeemed, &zero, nil, &redeemedAt))
+	require.NoError(t, repo.Credit(ctx, cardID, 40_000))
+
+	retrieved, err := repo.GetByID(ctx, cardID)
+	require.NoError(t, err)
+	assert.Equal(t, Active, retrieved.Status)
+	assert.Equal(t, int64(40_000), retrieved.BTCAmountSats)
+	assert.Nil(t, retrieved.RedeemedAt)
+
+	assert.ErrorIs(t, repo.Credit(ctx, uuid.New().String(), 1), ErrCardNotFound)
+}
+
+func TestCardRepository_Refund(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewCardRepository(db)
+	ctx := context.Background()
+
+	merchant := "acme"
+	cardID := uuid.New().String()
+	require.NoError(t, repo.Create(ctx, &Card{
+		ID:                 cardID,
+		PurchaseEmail:      "test@example.com",
+		OwnerEmail:         "test@example.com",
+		Code:               "REFUND-TEST",
+		BTCAmountSats:      50_000,
+		FiatAmountCents:    5000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5000,
+		Status:             Active,
+		CreatedAt:          time.Now().UTC(),
+		MerchantID:         &merchant,
+	}))
+
+	// Stale balance: not reserved
+	reserved, err := repo.ReserveRefund(ctx, cardID, 40_000)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+
+	// Declined: back to active, balance intact
+	reserved, err = repo.ReserveRefund(ctx, cardID, 50_000)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	total, err := repo.GetTotalReservedBalance(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50_000), total, "a refunding balance stays reserved")
+	require.NoError(t, repo.ReleaseRefund(ctx, cardID))
+
+	retrieved, err := repo.GetByID(ctx, cardID)
+	require.NoError(t, err)
+	assert.Equal(t, Active, retrieved.Status)
+	assert.Equal(t, int64(50_000), retrieved.BTCAmountSats)
+	assert.Equal(t, &merchant, retrieved.MerchantID)
+
+	// Accepted: refunded with a zero balance
+	reserved, err = repo.ReserveRefund(ctx, cardID, 50_000)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	require.NoError(t, repo.CommitRefund(ctx, cardID))
+
+	retrieved, err = repo.GetByID(ctx, cardID)
+	require.NoError(t, err)
+	assert.Equal(t, Refunded, retrieved.Status)
+	assert.Zero(t, retrieved.BTCAmountSats)
+
+	assert.ErrorIs(t, repo.ReleaseRefund(ctx, cardID), ErrCardNotFound, "only refunding cards can be released")
+}
+
 func TestCardRepository_Update_NotFound(t *testing.T) {
 	db := SetupTestDB(t)
 	defer db.Close()
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrDepositNotFound is returned when no deposit matches the lookup
+var ErrDepositNotFound = errors.New("deposit not found")
+
+const depositColumns = `id, account_id, payment_hash, payment_request, memo,
+	expected_sats, received_sats, settle_index, status,
+	created_at, expires_at, settled_at`
+
+// DepositRepository handles all database operations for incoming Lightning
+// deposits and the treasury ledger they credit
+type DepositRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewDepositRepository creates a new deposit repository instance
+func NewDepositRepository(db *DB) *DepositRepository {
+	return &DepositRepository{
+		db: db.pool,
+	}
+}
+
+// Create inserts a pending deposit for an invoice we issued.
+func (r *DepositRepository) Create(ctx context.Context, deposit *Deposit) error {
+	query := `INSERT INTO deposits (
+		id,
+		account_id,
+		payment_hash,
+		payment_request,
+		memo,
+		expected_sats,
+		status,
+		created_at,
+		expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		deposit.ID,
+		deposit.AccountID,
+		deposit.PaymentHash,
+		deposit.PaymentRequest,
+		deposit.Memo,
+		deposit.ExpectedSats,
+		deposit.Status,
+		deposit.CreatedAt,
+		deposit.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create deposit: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a deposit by ID. Returns ErrDepositNotFound if missing.
+func (r *DepositRepository) GetByID(ctx context.Context, id string) (*Deposit, error) {
+	return r.getOne(ctx, `SELECT `+depositColumns+` FROM deposits WHERE id = $1`, id)
+}
+
+// GetByPaymentHash retrieves a deposit by invoice payment hash.
+// Returns ErrDepositNotFound if missing.
+func (r *DepositRepository) GetByPaymentHash(ctx context.Context, paymentHash string) (*Deposit, error) {
+	return r.getOne(ctx, `SELECT `+depositColumns+` FROM deposits WHERE payment_hash = $1`, paymentHash)
+}
+
+func (r *DepositRepository) getOne(ctx context.Context, query string, arg any) (*Deposit, error) {
+	var d Deposit
+
+	err := r.db.QueryRow(ctx, query, arg).Scan(
+		&d.ID,
+		&d.AccountID,
+		&d.PaymentHash,
+		&d.PaymentRequest,
+		&d.Memo,
+		&d.ExpectedSats,
+		&d.ReceivedSats,
+		&d.SettleIndex,
+		&d.Status,
+		&d.CreatedAt,
+		&d.ExpiresAt,
+		&d.SettledAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDepositNotFound
+		}
+		return nil, fmt.Errorf("failed to get deposit: %w", err)
+	}
+
+	return &d, nil
+}
+
+// Settle marks a pending deposit settled by the invoice with paymentHash and
+// credits its account (or the house treasury) in treasury_ledger, in a single
+// statement. The hash is stored even when the deposit was matched by memo, so
+// a redelivered settlement finds the deposit by hash instead of crediting the
+// invoice again as unmatched. Returns false without error if the deposit was
+// already settled, so replayed settlements are no-ops.
+func (r *DepositRepository) Settle(ctx context.Context, id, paymentHash string, receivedSats, settleIndex int64, settledAt time.Time) (bool, error) {
+	query := `WITH settled AS (
+		UPDATE deposits
+		SET status = 'settled', payment_hash = $7, received_sats = $2, settle_index = $3, settled_at = $4
+		WHERE id = $1 AND status = 'pending'
+		RETURNING id, account_id, received_sats
+	)
+	INSERT INTO treasury_ledger (id, account_id, deposit_id, amount_sats, entry_type, created_at)
+	SELECT $5, account_id, id, received_sats, $6, $4 FROM settled`
+
+	commandTag, err := r.db.Exec(ctx, query, id, receivedSats, settleIndex, settledAt, uuid.New().String(), LedgerLightningDeposit, paymentHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to settle deposit %s: %w", id, err)
+	}
+
+	return commandTag.RowsAffected() == 1, nil
+}
+
+// RecordUnmatched records a settled invoice that matches no pending deposit and
+// credits the house treasury. Returns false without error if the payment hash
+// was already recorded.
+func (r *DepositRepository) RecordUnmatched(ctx context.Context, deposit *Deposit) (bool, error) {
+	query := `WITH recorded AS (
+		INSERT INTO deposits (
+			id, payment_hash, payment_request, memo,
+			received_sats, settle_index, status, created_at, settled_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, 'settled', $7, $7)
+		ON CONFLICT (payment_hash) DO NOTHING
+		RETURNING id, received_sats
+	)
+	INSERT INTO treasury_ledger (id, account_id, deposit_id, amount_sats, entry_type, created_at)
+	SELECT $8, NULL, id, received_sats, $9, $7 FROM recorded`
+
+	commandTag, err := r.db.Exec(
+		ctx,
+		query,
+		deposit.ID,
+		deposit.PaymentHash,
+		deposit.PaymentRequest,
+		deposit.Memo,
+		deposit.ReceivedSats,
+		deposit.SettleIndex,
+		deposit.SettledAt,
+		uuid.New().String(),
+		LedgerLightningDeposit,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record unmatched deposit %s: %w", deposit.PaymentHash, err)
+	}
+
+	return commandTag.RowsAffected() == 1, nil
+}
+
+// LastSettleIndex returns the highest LND settle index recorded (0 if none),
+// the point an invoice subscription resumes from.
+func (r *DepositRepository) LastSettleIndex(ctx context.Context) (uint64, error) {
+	query := `SELECT COALESCE(MAX(settle_index), 0) FROM deposits`
+
+	var index int64
+	if err := r.db.QueryRow(ctx, query).Scan(&index); err != nil {
+		return 0, fmt.Errorf("failed to get last settle index: %w", err)
+	}
+
+	return uint64(index), nil
+}
+
+// LedgerBalance returns the total credited to an account (nil = house treasury).
+func (r *DepositRepository) LedgerBalance(ctx context.Context, accountID *string) (int64, error) {
+	query := `SELECT COALESCE(SUM(amount_sats), 0) FROM treasury_ledger WHERE account_id IS NOT DISTINCT FROM $1`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, query, accountID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to get ledger balance: %w", err)
+	}
+
+	return total, nil
+}
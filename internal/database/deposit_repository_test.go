@@ -0,0 +1,137 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositRepository_Settle(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewDepositRepository(db)
+	ctx := context.Background()
+
+	account := "acme-corp"
+	deposit := &Deposit{
+		ID:             uuid.New().String(),
+		AccountID:      &account,
+		PaymentHash:    "aa11",
+		PaymentRequest: "lntb50m1...",
+		Memo:           "deposit:x",
+		ExpectedSats:   5_000_000,
+		Status:         DepositPending,
+		CreatedAt:      time.Now().UTC(),
+	}
+	require.NoError(t, repo.Create(ctx, deposit))
+
+	settledAt := time.Now().UTC()
+	credited, err := repo.Settle(ctx, deposit.ID, "aa11", 5_000_000, 12, settledAt)
+	require.NoError(t, err)
+	assert.True(t, credited)
+
+	got, err := repo.GetByPaymentHash(ctx, "aa11")
+	require.NoError(t, err)
+	assert.Equal(t, DepositSettled, got.Status)
+	require.NotNil(t, got.ReceivedSats)
+	assert.Equal(t, int64(5_000_000), *got.ReceivedSats)
+	require.NotNil(t, got.SettledAt)
+
+	// Replayed settlement is a no-op
+	credited, err = repo.Settle(ctx, deposit.ID, "aa11", 5_000_000, 12, settledAt)
+	require.NoError(t, err)
+	assert.False(t, credited)
+
+	balance, err := repo.LedgerBalance(ctx, &account)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5_000_000), balance)
+
+	index, err := repo.LastSettleIndex(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), index)
+
+	// Account credits are owed to the account, so they count as reserved
+	reserved, err := NewCardRepository(db).GetTotalReservedBalance(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5_000_000), reserved)
+}
+
+func TestDepositRepository_Settle_StoresMatchedPaymentHash(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewDepositRepository(db)
+	ctx := context.Background()
+
+	deposit := &Deposit{
+		ID:           uuid.New().String(),
+		PaymentHash:  "issued",
+		Memo:         "deposit:x",
+		ExpectedSats: 10_000,
+		Status:       DepositPending,
+		CreatedAt:    time.Now().UTC(),
+	}
+	require.NoError(t, repo.Create(ctx, deposit))
+
+	// Matched by memo: the settled invoice has a different hash
+	credited, err := repo.Settle(ctx, deposit.ID, "paid", 10_000, 4, time.Now().UTC())
+	require.NoError(t, err)
+	assert.True(t, credited)
+
+	got, err := repo.GetByPaymentHash(ctx, "paid")
+	require.NoError(t, err, "a redelivery finds the deposit by hash")
+	assert.Equal(t, deposit.ID, got.ID)
+}
+
+func TestDepositRepository_RecordUnmatched(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewDepositRepository(db)
+	ctx := context.Background()
+
+	received := int64(21_000)
+	settleIndex := int64(3)
+	settledAt := time.Now().UTC()
+	unmatched := &Deposit{
+		ID:           uuid.New().String(),
+		PaymentHash:  "bb22",
+		Memo:         "tip",
+		ReceivedSats: &received,
+		SettleIndex:  &settleIndex,
+		SettledAt:    &settledAt,
+	}
+
+	credited, err := repo.RecordUnmatched(ctx, unmatched)
+	require.NoError(t, err)
+	assert.True(t, credited)
+
+	// Same payment hash again (subscription replay)
+	unmatched.ID = uuid.New().String()
+	credited, err = repo.RecordUnmatched(ctx, unmatched)
+	require.NoError(t, err)
+	assert.False(t, credited)
+
+	balance, err := repo.LedgerBalance(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(21_000), balance, "credited once, to the house treasury")
+}
+
+func TestDepositRepository_GetByID_NotFound(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	_, err := NewDepositRepository(db).GetByID(context.Background(), uuid.New().String())
+	assert.ErrorIs(t, err, ErrDepositNotFound)
+}
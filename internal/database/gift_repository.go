@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrGiftNotFound is returned when a card has no gift personalization
+	ErrGiftNotFound = errors.New("gift not found")
+	// ErrGiftDelivered is returned when updating a gift that was already delivered
+	ErrGiftDelivered = errors.New("gift already delivered")
+)
+
+// GiftRepository handles all database operations for card gift personalization
+type GiftRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewGiftRepository creates a new gift repository instance
+func NewGiftRepository(db *DB) *GiftRepository {
+	return &GiftRepository{
+		db: db.pool,
+	}
+}
+
+// Upsert creates or replaces a card's gift personalization.
+// Returns ErrGiftDelivered if the gift was already delivered (the row is left unchanged).
+func (r *GiftRepository) Upsert(ctx context.Context, gift *CardGift) error {
+	query := `INSERT INTO card_gifts (
+		card_id,
+		sender_name,
+		recipient_name,
+		message,
+		theme,
+		created_at,
+		updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (card_id) DO UPDATE
+		SET sender_name = EXCLUDED.sender_name,
+			recipient_name = EXCLUDED.recipient_name,
+			message = EXCLUDED.message,
+			theme = EXCLUDED.theme,
+			updated_at = EXCLUDED.updated_at
+		WHERE card_gifts.delivered_at IS NULL`
+
+	commandTag, err := r.db.Exec(
+		ctx,
+		query,
+		gift.CardID,
+		gift.SenderName,
+		gift.RecipientName,
+		gift.Message,
+		gift.Theme,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save gift for card %s: %w", gift.CardID, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrGiftDelivered
+	}
+
+	return nil
+}
+
+// GetByCardID retrieves a card's gift personalization.
+// Returns ErrGiftNotFound if the card has none.
+func (r *GiftRepository) GetByCardID(ctx context.Context, cardID string) (*CardGift, error) {
+	query := `SELECT
+		card_id, sender_name, recipient_name, message, theme,
+		created_at, updated_at, delivered_at
+	FROM card_gifts WHERE card_id = $1`
+
+	var gift CardGift
+
+	err := r.db.QueryRow(ctx, query, cardID).Scan(
+		&gift.CardID,
+		&gift.SenderName,
+		&gift.RecipientName,
+		&gift.Message,
+		&gift.Theme,
+		&gift.CreatedAt,
+		&gift.UpdatedAt,
+		&gift.DeliveredAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGiftNotFound
+		}
+		return nil, fmt.Errorf("failed to get gift for card %s: %w", cardID, err)
+	}
+
+	return &gift, nil
+}
+
+// MarkDelivered records that the gift was sent to the recipient.
+// Returns ErrGiftNotFound if the card has no gift.
+func (r *GiftRepository) MarkDelivered(ctx context.Context, cardID string, deliveredAt time.Time) error {
+	query := `UPDATE card_gifts SET delivered_at = COALESCE(delivered_at, $2) WHERE card_id = $1`
+
+	commandTag, err := r.db.Exec(ctx, query, cardID, deliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark gift delivered for card %s: %w", cardID, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrGiftNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createGiftTestCard(t *testing.T, ctx context.Context, db *DB) string {
+	t.Helper()
+
+	cardID := uuid.New().String()
+	err := NewCardRepository(db).Create(ctx, &Card{
+		ID:                 cardID,
+		PurchaseEmail:      "sender@example.com",
+		OwnerEmail:         "sender@example.com",
+		Code:               "GIFT-TEST-" + cardID[:8],
+		FiatAmountCents:    5000,
+		FiatCurrency:       "USD",
+		PurchasePriceCents: 5150,
+		Status:             Created,
+		CreatedAt:          time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	return cardID
+}
+
+func TestGiftRepository_UpsertAndGet(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	repo := NewGiftRepository(db)
+	cardID := createGiftTestCard(t, ctx, db)
+
+	err := repo.Upsert(ctx, &CardGift{
+		CardID:        cardID,
+		SenderName:    "Alice",
+		RecipientName: "Bob",
+		Message:       "Happy birthday!",
+		Theme:         "birthday",
+	})
+	require.NoError(t, err)
+
+	gift, err := repo.GetByCardID(ctx, cardID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", gift.SenderName)
+	assert.Equal(t, "Bob", gift.RecipientName)
+	assert.Equal(t, "Happy birthday!", gift.Message)
+	assert.Equal(t, "birthday", gift.Theme)
+	assert.Nil(t, gift.DeliveredAt)
+
+	// Update before delivery
+	err = repo.Upsert(ctx, &CardGift{CardID: cardID, SenderName: "Alice", Message: "Enjoy!", Theme: "classic"})
+	require.NoError(t, err)
+
+	gift, err = repo.GetByCardID(ctx, cardID)
+	require.NoError(t, err)
+	assert.Equal(t, "Enjoy!", gift.Message)
+	assert.Empty(t, gift.RecipientName)
+}
+
+func TestGiftRepository_ReadOnlyAfterDelivery(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	repo := NewGiftRepository(db)
+	cardID := createGiftTestCard(t, ctx, db)
+
+	require.NoError(t, repo.Upsert(ctx, &CardGift{CardID: cardID, Message: "Original", Theme: "classic"}))
+	require.NoError(t, repo.MarkDelivered(ctx, cardID, time.Now().UTC()))
+
+	err := repo.Upsert(ctx, &CardGift{CardID: cardID, Message: "Changed", Theme: "classic"})
+	assert.ErrorIs(t, err, ErrGiftDelivered)
+
+	gift, err := repo.GetByCardID(ctx, cardID)
+	require.NoError(t, err)
+	assert.Equal(t, "Original", gift.Message)
+	assert.NotNil(t, gift.DeliveredAt)
+}
+
+func TestGiftRepository_NotFound(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	repo := NewGiftRepository(db)
+
+	_, err := repo.GetByCardID(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, ErrGiftNotFound)
+
+	err = repo.MarkDelivered(ctx, uuid.New().String(), time.Now().UTC())
+	assert.ErrorIs(t, err, ErrGiftNotFound)
+}
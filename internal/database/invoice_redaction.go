@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// InvoiceHashPrefix marks a lightning_invoice value that holds the SHA-256 of
+// the BOLT11 invoice instead of the invoice itself (migration 000015 writes
+// the same format).
+const InvoiceHashPrefix = "sha256:"
+
+// HashInvoice returns the redacted form of a BOLT11 invoice. The hash still
+// lets support check whether a given invoice was paid, without keeping its
+// description (memo), which may hold the payee's personal data.
+func HashInvoice(bolt11 string) string {
+	if strings.HasPrefix(bolt11, InvoiceHashPrefix) {
+		return bolt11
+	}
+	sum := sha256.Sum256([]byte(bolt11))
+	return InvoiceHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// RedactInvoicesBefore replaces up to limit stored BOLT11 invoices of
+// transactions created before cutoff with their hash (see HashInvoice), in
+// transactions and transactions_archive. Returns the number of rows redacted;
+// callers loop until it returns less than limit.
+func (r *TransactionRepository) RedactInvoicesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	var total int64
+	for _, table := range []string{"transactions", "transactions_archive"} {
+		query := `UPDATE ` + table + `
+			SET lightning_invoice = $3 || encode(sha256(convert_to(lightning_invoice, 'UTF8')), 'hex')
+			WHERE id IN (
+				SELECT id FROM ` + table + `
+				WHERE created_at < $1
+					AND lightning_invoice IS NOT NULL
+					AND lightning_invoice NOT LIKE $3 || '%'
+				ORDER BY created_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)`
+
+		commandTag, err := r.db.Exec(ctx, query, cutoff, limit-int(total), InvoiceHashPrefix)
+		if err != nil {
+			return total, fmt.Errorf("failed to redact invoices in %s before %s: %w", table, cutoff.Format(time.RFC3339), err)
+		}
+		total += commandTag.RowsAffected()
+		if total >= int64(limit) {
+			break
+		}
+	}
+
+	return total, nil
+}
@@ -14,17 +14,24 @@ type TransactionType string
 type TransactionStatus string
 
 const (
-	Created  CardStatus = "created"
-	Funding  CardStatus = "funding"
-	Active   CardStatus = "active"
-	Redeemed CardStatus = "redeemed"
-	Expired  CardStatus = "expired"
+	Inactive        CardStatus = "inactive" // Pre-generated retail card, zero balance until activated at the POS
+	Created         CardStatus = "created"
+	Funding         CardStatus = "funding"
+	PartiallyFunded CardStatus = "partially_funded" // Some tranches reserved, waiting for treasury
+	Active          CardStatus = "active"
+	Redeemed        CardStatus = "redeemed"
+	Refunding       CardStatus = "refunding" // Refund sent to the payment provider, balance still reserved
+	Refunded        CardStatus = "refunded"  // Balance cashed out to the original payment method
+	Expired         CardStatus = "expired"
 )
 
 const (
 	Fund    TransactionType = "fund"
 	Redeem  TransactionType = "redeem"
 	Payment TransactionType = "payment"
+	Refund  TransactionType = "refund"
+
+	FiatPayout TransactionType = "fiat_payout" // Redemption paid out in fiat to a bank account
 )
 
 const (
@@ -47,6 +54,12 @@ type Card struct {
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 	RedeemedAt         *time.Time `json:"redeemed_at,omitempty" db:"redeemed_at"`
 	FundedAt           *time.Time `json:"funded_at,omitempty" db:"funded_at"`
+	FundedSats         int64      `json:"funded_sats" db:"funded_sats"`                           // Total sats reserved by funding tranches
+	FundingTargetSats  *int64     `json:"funding_target_sats,omitempty" db:"funding_target_sats"` // Sats needed to be fully funded (set at first tranche)
+	ProductID          *string    `json:"product_id,omitempty" db:"product_id"`                   // Catalog product the card was bought as (nil = custom amount)
+	BatchID            *string    `json:"batch_id,omitempty" db:"batch_id"`                       // Retail batch the card was pre-generated in (nil = sold online)
+	ActivatedAt        *time.Time `json:"activated_at,omitempty" db:"activated_at"`               // When a retail card was activated at the POS
+	MerchantID         *string    `json:"merchant_id,omitempty" db:"merchant_id"`                 // Merchant the card was sold through (nil = sold directly)
 }
 
 // GetBTC returns BTC amount as float64 for display (e.g., 0.00152345)
@@ -64,26 +77,194 @@ func (c *Card) GetPurchasePrice() float64 {
 	return float64(c.PurchasePriceCents) / 100
 }
 
+// RetiredCardCode is a code replaced by a code rotation, kept for audit.
+type RetiredCardCode struct {
+	Code      string    `json:"code" db:"code"`
+	CardID    string    `json:"card_id" db:"card_id"`
+	RetiredAt time.Time `json:"retired_at" db:"retired_at"`
+}
+
 type Transaction struct {
-	ID               string            `json:"id" db:"id"`
-	CardID           string            `json:"card_id" db:"card_id"`
-	Type             TransactionType   `json:"type" db:"type"`
-	RedemptionMethod *string           `json:"redemption_method,omitempty" db:"redemption_method"` // 'lightning' or 'onchain'
-	TxHash           *string           `json:"tx_hash,omitempty" db:"tx_hash"`                     // On-chain tx hash (NULL for Lightning)
-	PaymentHash      *string           `json:"payment_hash,omitempty" db:"payment_hash"`           // Lightning payment hash (NULL for on-chain)
-	PaymentPreimage  *string           `json:"payment_preimage,omitempty" db:"payment_preimage"`   // Lightning proof of payment (set on success)
-	LightningInvoice *string           `json:"lightning_invoice,omitempty" db:"lightning_invoice"` // BOLT11 invoice (NULL for on-chain)
-	FromAddress      *string           `json:"from_address,omitempty" db:"from_address"`           // Source Bitcoin address (on-chain)
-	ToAddress        *string           `json:"to_address,omitempty" db:"to_address"`               // Destination Bitcoin address (on-chain)
-	BTCAmountSats    int64             `json:"btc_amount_sats" db:"btc_amount_sats"`               // Satoshis
-	Status           TransactionStatus `json:"status" db:"status"`
-	Confirmations    int               `json:"confirmations" db:"confirmations"`
-	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
-	BroadcastAt      *time.Time        `json:"broadcast_at,omitempty" db:"broadcast_at"` // When sent to blockchain
-	ConfirmedAt      *time.Time        `json:"confirmed_at,omitempty" db:"confirmed_at"` // When confirmed
+	ID                string            `json:"id" db:"id"`
+	CardID            string            `json:"card_id" db:"card_id"`
+	Type              TransactionType   `json:"type" db:"type"`
+	RedemptionMethod  *string           `json:"redemption_method,omitempty" db:"redemption_method"` // 'lightning' or 'onchain'
+	TxHash            *string           `json:"tx_hash,omitempty" db:"tx_hash"`                     // On-chain tx hash (NULL for Lightning)
+	PaymentHash       *string           `json:"payment_hash,omitempty" db:"payment_hash"`           // Lightning payment hash (NULL for on-chain)
+	PaymentPreimage   *string           `json:"payment_preimage,omitempty" db:"payment_preimage"`   // Lightning proof of payment (set on success)
+	LightningInvoice  *string           `json:"lightning_invoice,omitempty" db:"lightning_invoice"` // BOLT11 invoice (NULL for on-chain)
+	FromAddress       *string           `json:"from_address,omitempty" db:"from_address"`           // Source Bitcoin address (on-chain)
+	ToAddress         *string           `json:"to_address,omitempty" db:"to_address"`               // Destination Bitcoin address (on-chain)
+	BTCAmountSats     int64             `json:"btc_amount_sats" db:"btc_amount_sats"`               // Satoshis
+	Status            TransactionStatus `json:"status" db:"status"`
+	Confirmations     int               `json:"confirmations" db:"confirmations"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+	BroadcastAt       *time.Time        `json:"broadcast_at,omitempty" db:"broadcast_at"`             // When sent to blockchain
+	ConfirmedAt       *time.Time        `json:"confirmed_at,omitempty" db:"confirmed_at"`             // When confirmed
+	FiatAmountCents   *int64            `json:"fiat_amount_cents,omitempty" db:"fiat_amount_cents"`   // Fiat paid out (refunds)
+	ExternalReference *string           `json:"external_reference,omitempty" db:"external_reference"` // Provider reference (e.g., refund ID)
+	RedemptionID      *string           `json:"redemption_id,omitempty" db:"redemption_id"`           // Links the legs of a split redemption
+}
+
+// LedgerDiscrepancy is a card whose balance doesn't reconcile with its transactions.
+type LedgerDiscrepancy struct {
+	CardID      string
+	BalanceSats int64 // cards.btc_amount_sats
+	LedgerSats  int64 // Fund tranches minus redemptions and refunds
 }
 
 // GetBTC returns BTC amount as float64 for display (e.g., 0.00152345)
 func (t *Transaction) GetBTC() float64 {
 	return float64(t.BTCAmountSats) / 100_000_000
 }
+
+// CardGift is the optional personalization attached to a card.
+type CardGift struct {
+	CardID        string     `json:"card_id" db:"card_id"`
+	SenderName    string     `json:"sender_name" db:"sender_name"`
+	RecipientName string     `json:"recipient_name" db:"recipient_name"`
+	Message       string     `json:"message" db:"message"` // Plain text
+	Theme         string     `json:"theme" db:"theme"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" db:"delivered_at"` // Read-only once set
+}
+
+// DepositStatus represents the state of an incoming Lightning deposit.
+type DepositStatus string
+
+const (
+	DepositPending DepositStatus = "pending"
+	DepositSettled DepositStatus = "settled"
+)
+
+// treasury_ledger entry types
+const (
+	LedgerLightningDeposit = "lightning_deposit" // Settled invoice deposit (credit)
+	LedgerWithdrawal       = "withdrawal"        // Owner withdrawal sent on-chain (debit, negative amount)
+)
+
+type Deposit struct {
+	ID             string        `json:"id" db:"id"`
+	AccountID      *string       `json:"account_id,omitempty" db:"account_id"` // nil = house treasury
+	PaymentHash    string        `json:"payment_hash" db:"payment_hash"`
+	PaymentRequest string        `json:"payment_request" db:"payment_request"`
+	Memo           string        `json:"memo" db:"memo"`
+	ExpectedSats   int64         `json:"expected_sats" db:"expected_sats"`
+	ReceivedSats   *int64        `json:"received_sats,omitempty" db:"received_sats"`
+	SettleIndex    *int64        `json:"settle_index,omitempty" db:"settle_index"`
+	Status         DepositStatus `json:"status" db:"status"`
+	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
+	ExpiresAt      *time.Time    `json:"expires_at,omitempty" db:"expires_at"`
+	SettledAt      *time.Time    `json:"settled_at,omitempty" db:"settled_at"`
+}
+
+// WithdrawalStatus represents the state of an owner withdrawal from the treasury.
+type WithdrawalStatus string
+
+const (
+	WithdrawalRequested WithdrawalStatus = "requested" // Waiting for a second operator
+	WithdrawalApproved  WithdrawalStatus = "approved"  // Approved, on-chain send in progress
+	WithdrawalSent      WithdrawalStatus = "sent"      // Broadcast and debited in treasury_ledger
+	WithdrawalRejected  WithdrawalStatus = "rejected"
+	WithdrawalFailed    WithdrawalStatus = "failed" // The custodian refused the send
+)
+
+// TreasuryWithdrawal is an owner payout of profit from the treasury.
+type TreasuryWithdrawal struct {
+	ID                 string           `json:"id" db:"id"`
+	AmountSats         int64            `json:"amount_sats" db:"amount_sats"`
+	DestinationAddress string           `json:"destination_address" db:"destination_address"`
+	Reason             string           `json:"reason" db:"reason"`
+	RequestedBy        string           `json:"requested_by" db:"requested_by"`
+	DecidedBy          *string          `json:"decided_by,omitempty" db:"decided_by"` // Approver or rejecter, never RequestedBy
+	Status             WithdrawalStatus `json:"status" db:"status"`
+	TxHash             *string          `json:"tx_hash,omitempty" db:"tx_hash"`
+	CreatedAt          time.Time        `json:"created_at" db:"created_at"`
+	DecidedAt          *time.Time       `json:"decided_at,omitempty" db:"decided_at"`
+	SentAt             *time.Time       `json:"sent_at,omitempty" db:"sent_at"`
+}
+
+// CardProduct is a fixed-denomination card in the catalog.
+type CardProduct struct {
+	ID              string    `json:"id" db:"id"`
+	SKU             string    `json:"sku" db:"sku"`
+	Name            string    `json:"name" db:"name"`
+	FiatAmountCents int64     `json:"fiat_amount_cents" db:"fiat_amount_cents"` // Face value
+	FiatCurrency    string    `json:"fiat_currency" db:"fiat_currency"`
+	ArtworkURL      string    `json:"artwork_url" db:"artwork_url"`
+	FeeBps          int64     `json:"fee_bps" db:"fee_bps"`                 // Percentage fee on top of face value (100 = 1%)
+	FeeFixedCents   int64     `json:"fee_fixed_cents" db:"fee_fixed_cents"` // Flat fee on top of face value
+	IsAvailable     bool      `json:"is_available" db:"is_available"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PurchasePriceCents returns what a customer pays for the product (face value plus fees).
+func (p *CardProduct) PurchasePriceCents() int64 {
+	return p.FiatAmountCents + p.FiatAmountCents*p.FeeBps/10_000 + p.FeeFixedCents
+}
+
+// ProductSales is the sales summary of one SKU over a period.
+type ProductSales struct {
+	SKU                string
+	Name               string
+	FiatCurrency       string
+	CardsSold          int64
+	FaceValueCents     int64 // Sum of card face values
+	PurchasePriceCents int64 // Sum charged to customers (face value + fees)
+}
+
+// CardBatch is a batch of pre-generated retail cards.
+type CardBatch struct {
+	ID              string     `json:"id" db:"id"`
+	Retailer        string     `json:"retailer" db:"retailer"`
+	ProductID       *string    `json:"product_id,omitempty" db:"product_id"`
+	FiatAmountCents int64      `json:"fiat_amount_cents" db:"fiat_amount_cents"` // Face value of every card in the batch
+	FiatCurrency    string     `json:"fiat_currency" db:"fiat_currency"`
+	Quantity        int        `json:"quantity" db:"quantity"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	PrintedAt       *time.Time `json:"printed_at,omitempty" db:"printed_at"`
+}
+
+// BatchSummary is a batch with its activation progress.
+type BatchSummary struct {
+	CardBatch
+	ActivatedCount int64 // Cards no longer inactive
+}
+
+// OTCFill is an OTC purchase of BTC for the treasury.
+type OTCFill struct {
+	ID              string    `json:"id" db:"id"`
+	FiatCurrency    string    `json:"fiat_currency" db:"fiat_currency"`
+	FiatAmountCents int64     `json:"fiat_amount_cents" db:"fiat_amount_cents"` // Fiat paid, fees included
+	BTCAmountSats   int64     `json:"btc_amount_sats" db:"btc_amount_sats"`
+	Reference       string    `json:"reference" db:"reference"` // OTC desk trade ID
+	FilledAt        time.Time `json:"filled_at" db:"filled_at"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// Price returns the realized price of the fill in fiat per BTC.
+func (f *OTCFill) Price() float64 {
+	return float64(f.FiatAmountCents) / 100 / (float64(f.BTCAmountSats) / 100_000_000)
+}
+
+// ConversionAccuracy compares, for one currency and week, the price cards
+// were funded at with the price the treasury bought BTC at.
+type ConversionAccuracy struct {
+	Week         time.Time // Start of the week (Monday, UTC)
+	FiatCurrency string
+	CardsFunded  int64
+	FundingPrice float64 // Volume-weighted funding price, fiat per BTC
+	Fills        int64
+	OTCPrice     float64 // Volume-weighted realized OTC price, fiat per BTC
+}
+
+// DeltaBps is how far the funding price was above (positive) or below
+// (negative) the realized OTC price, in basis points.
+func (a *ConversionAccuracy) DeltaBps() float64 {
+	if a.OTCPrice <= 0 {
+		return 0
+	}
+	return (a.FundingPrice - a.OTCPrice) / a.OTCPrice * 10_000
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OTCFillRepository handles all database operations for OTC fills and the
+// conversion accuracy report built on them
+type OTCFillRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewOTCFillRepository creates a new OTC fill repository instance
+func NewOTCFillRepository(db *DB) *OTCFillRepository {
+	return &OTCFillRepository{
+		db: db.pool,
+	}
+}
+
+// Create records an OTC fill.
+func (r *OTCFillRepository) Create(ctx context.Context, fill *OTCFill) error {
+	query := `INSERT INTO otc_fills (
+		id,
+		fiat_currency,
+		fiat_amount_cents,
+		btc_amount_sats,
+		reference,
+		filled_at,
+		created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		fill.ID,
+		fill.FiatCurrency,
+		fill.FiatAmountCents,
+		fill.BTCAmountSats,
+		fill.Reference,
+		fill.FilledAt,
+		fill.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create OTC fill: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the most recent fills, newest first.
+func (r *OTCFillRepository) List(ctx context.Context, limit int) ([]*OTCFill, error) {
+	query := `SELECT id, fiat_currency, fiat_amount_cents, btc_amount_sats, reference, filled_at, created_at
+		FROM otc_fills ORDER BY filled_at DESC LIMIT $1`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OTC fills: %w", err)
+	}
+	defer rows.Close()
+
+	var fills []*OTCFill
+	for rows.Next() {
+		var f OTCFill
+		err := rows.Scan(&f.ID, &f.FiatCurrency, &f.FiatAmountCents, &f.BTCAmountSats, &f.Reference, &f.FilledAt, &f.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan OTC fill: %w", err)
+		}
+		fills = append(fills, &f)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return fills, nil
+}
+
+// WeeklyAccuracy returns, per currency and week since from, the
+// volume-weighted price cards were funded at next to the volume-weighted
+// price of that week's OTC fills, newest week first. A card counts in the week
+// it was priced (funded_at, or created_at while partially funded); weeks
+// without both funded cards and fills are left out.
+func (r *OTCFillRepository) WeeklyAccuracy(ctx context.Context, from time.Time) ([]*ConversionAccuracy, error) {
+	query := `WITH funded AS (
+		SELECT date_trunc('week', COALESCE(funded_at, created_at)) AS week, fiat_currency,
+			COUNT(*) AS cards,
+			SUM(fiat_amount_cents / 100.0) / SUM(fiat_amount_cents / 100.0 / funding_price) AS price
+		FROM cards
+		WHERE funding_price > 0 AND COALESCE(funded_at, created_at) >= $1
+		GROUP BY 1, 2
+	), fills AS (
+		SELECT date_trunc('week', filled_at) AS week, fiat_currency,
+			COUNT(*) AS fills,
+			SUM(fiat_amount_cents / 100.0) / SUM(btc_amount_sats / 100000000.0) AS price
+		FROM otc_fills
+		WHERE filled_at >= $1
+		GROUP BY 1, 2
+	)
+	SELECT f.week, f.fiat_currency, f.cards, f.price::float8, o.fills, o.price::float8
+	FROM funded f
+	JOIN fills o ON o.week = f.week AND o.fiat_currency = f.fiat_currency
+	ORDER BY f.week DESC, f.fiat_currency`
+
+	rows, err := r.db.Query(ctx, query, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversion accuracy: %w", err)
+	}
+	defer rows.Close()
+
+	var report []*ConversionAccuracy
+	for rows.Next() {
+		var a ConversionAccuracy
+		if err := rows.Scan(&a.Week, &a.FiatCurrency, &a.CardsFunded, &a.FundingPrice, &a.Fills, &a.OTCPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan conversion accuracy row: %w", err)
+		}
+		report = append(report, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,71 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTCFillRepository_WeeklyAccuracy(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	fills := NewOTCFillRepository(db)
+	cards := NewCardRepository(db)
+
+	card := &Card{
+		ID:                 uuid.New().String(),
+		PurchaseEmail:      "buyer@example.com",
+		OwnerEmail:         "buyer@example.com",
+		Code:               "OTC-TEST-0001",
+		FiatAmountCents:    5000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5000,
+		Status:             Created,
+		CreatedAt:          time.Now().UTC(),
+	}
+	require.NoError(t, cards.Create(ctx, card))
+	require.NoError(t, cards.SetFundingPrice(ctx, card.ID, 50_000))
+	assert.ErrorIs(t, cards.SetFundingPrice(ctx, uuid.New().String(), 50_000), ErrCardNotFound)
+
+	// 4,900 EUR for 0.1 BTC: 49,000 EUR/BTC
+	fill := &OTCFill{
+		ID:              uuid.New().String(),
+		FiatCurrency:    "EUR",
+		FiatAmountCents: 490_000,
+		BTCAmountSats:   10_000_000,
+		Reference:       "desk-1",
+		FilledAt:        time.Now().UTC(),
+		CreatedAt:       time.Now().UTC(),
+	}
+	require.NoError(t, fills.Create(ctx, fill))
+	assert.InDelta(t, 49_000, fill.Price(), 0.001)
+
+	list, err := fills.List(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "desk-1", list[0].Reference)
+
+	report, err := fills.WeeklyAccuracy(ctx, time.Now().AddDate(0, 0, -7))
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+	assert.Equal(t, "EUR", report[0].FiatCurrency)
+	assert.Equal(t, int64(1), report[0].CardsFunded)
+	assert.Equal(t, int64(1), report[0].Fills)
+	assert.InDelta(t, 50_000, report[0].FundingPrice, 0.001)
+	assert.InDelta(t, 49_000, report[0].OTCPrice, 0.001)
+	assert.InDelta(t, 204.08, report[0].DeltaBps(), 0.01)
+
+	// No fills in the currency: nothing to compare
+	report, err = fills.WeeklyAccuracy(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, report)
+}
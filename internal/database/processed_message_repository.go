@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ProcessedMessageRepository tracks which queue messages have already been handled.
+// Workers use it to make handlers idempotent across crashes and redeliveries.
+type ProcessedMessageRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewProcessedMessageRepository creates a new processed message repository instance
+func NewProcessedMessageRepository(db *DB) *ProcessedMessageRepository {
+	return &ProcessedMessageRepository{
+		db: db.pool,
+	}
+}
+
+// IsProcessed reports whether the given message ID has already been handled on the stream.
+func (r *ProcessedMessageRepository) IsProcessed(ctx context.Context, stream, messageID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM processed_messages WHERE stream = $1 AND message_id = $2)`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, stream, messageID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check processed message %s on stream %s: %w", messageID, stream, err)
+	}
+
+	return exists, nil
+}
+
+// MarkProcessed records the message ID as handled.
+// Marking the same message twice is a no-op.
+func (r *ProcessedMessageRepository) MarkProcessed(ctx context.Context, stream, messageID string) error {
+	query := `INSERT INTO processed_messages (stream, message_id)
+		VALUES ($1, $2)
+		ON CONFLICT (stream, message_id) DO NOTHING`
+
+	_, err := r.db.Exec(ctx, query, stream, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to mark message %s on stream %s as processed: %w", messageID, stream, err)
+	}
+
+	return nil
+}
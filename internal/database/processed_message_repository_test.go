@@ -0,0 +1,59 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessedMessageRepository_MarkAndCheck(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewProcessedMessageRepository(db)
+	ctx := context.Background()
+
+	processed, err := repo.IsProcessed(ctx, "fund_card", "1700000000000-0")
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	err = repo.MarkProcessed(ctx, "fund_card", "1700000000000-0")
+	require.NoError(t, err)
+
+	processed, err = repo.IsProcessed(ctx, "fund_card", "1700000000000-0")
+	require.NoError(t, err)
+	assert.True(t, processed)
+}
+
+func TestProcessedMessageRepository_MarkProcessed_Twice(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewProcessedMessageRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.MarkProcessed(ctx, "fund_card", "1700000000000-0"))
+	assert.NoError(t, repo.MarkProcessed(ctx, "fund_card", "1700000000000-0"), "second mark should be a no-op")
+}
+
+func TestProcessedMessageRepository_ScopedByStream(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewProcessedMessageRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.MarkProcessed(ctx, "fund_card", "1700000000000-0"))
+
+	// Same message ID on a different stream is a different message
+	processed, err := repo.IsProcessed(ctx, "monitor_tx", "1700000000000-0")
+	require.NoError(t, err)
+	assert.False(t, processed)
+}
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrProductNotFound is returned when no catalog product matches the lookup
+	ErrProductNotFound = errors.New("product not found")
+	// ErrProductSKUExists is returned when creating a product with a SKU already in use
+	ErrProductSKUExists = errors.New("product SKU already exists")
+)
+
+const productColumns = `id, sku, name, fiat_amount_cents, fiat_currency, artwork_url,
+	fee_bps, fee_fixed_cents, is_available, created_at, updated_at`
+
+// ProductRepository handles all database operations for the card product catalog
+type ProductRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewProductRepository creates a new product repository instance
+func NewProductRepository(db *DB) *ProductRepository {
+	return &ProductRepository{
+		db: db.pool,
+	}
+}
+
+// Create inserts a new catalog product.
+// Returns ErrProductSKUExists if the SKU is already in use.
+func (r *ProductRepository) Create(ctx context.Context, product *CardProduct) error {
+	query := `INSERT INTO card_products (
+		id,
+		sku,
+		name,
+		fiat_amount_cents,
+		fiat_currency,
+		artwork_url,
+		fee_bps,
+		fee_fixed_cents,
+		is_available,
+		created_at,
+		updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)`
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		product.ID,
+		product.SKU,
+		product.Name,
+		product.FiatAmountCents,
+		product.FiatCurrency,
+		product.ArtworkURL,
+		product.FeeBps,
+		product.FeeFixedCents,
+		product.IsAvailable,
+		product.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrProductSKUExists
+		}
+		return fmt.Errorf("failed to create product: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a product by ID. Returns ErrProductNotFound if missing.
+func (r *ProductRepository) GetByID(ctx context.Context, id string) (*CardProduct, error) {
+	return r.getOne(ctx, `SELECT `+productColumns+` FROM card_products WHERE id = $1`, id)
+}
+
+// GetBySKU retrieves a product by SKU. Returns ErrProductNotFound if missing.
+func (r *ProductRepository) GetBySKU(ctx context.Context, sku string) (*CardProduct, error) {
+	return r.getOne(ctx, `SELECT `+productColumns+` FROM card_products WHERE sku = $1`, sku)
+}
+
+func (r *ProductRepository) getOne(ctx context.Context, query string, arg any) (*CardProduct, error) {
+	var p CardProduct
+
+	err := r.db.QueryRow(ctx, query, arg).Scan(
+		&p.ID,
+		&p.SKU,
+		&p.Name,
+		&p.FiatAmountCents,
+		&p.FiatCurrency,
+		&p.ArtworkURL,
+		&p.FeeBps,
+		&p.FeeFixedCents,
+		&p.IsAvailable,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return &p, nil
+}
+
+// List returns catalog products ordered by currency and face value.
+// If onlyAvailable is true, products toggled off are left out.
+func (r *ProductRepository) List(ctx context.Context, onlyAvailable bool) ([]*CardProduct, error) {
+	query := `SELECT ` + productColumns + ` FROM card_products
+	WHERE is_available OR NOT $1
+	ORDER BY fiat_currency, fiat_amount_cents, sku`
+
+	rows, err := r.db.Query(ctx, query, onlyAvailable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []*CardProduct
+	for rows.Next() {
+		var p CardProduct
+
+		err := rows.Scan(
+			&p.ID,
+			&p.SKU,
+			&p.Name,
+			&p.FiatAmountCents,
+			&p.FiatCurrency,
+			&p.ArtworkURL,
+			&p.FeeBps,
+			&p.FeeFixedCents,
+			&p.IsAvailable,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product row: %w", err)
+		}
+
+		products = append(products, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return products, nil
+}
+
+// SetAvailable toggles whether a product can be bought.
+// Returns ErrProductNotFound if no product has the SKU.
+func (r *ProductRepository) SetAvailable(ctx context.Context, sku string, available bool) error {
+	query := `UPDATE card_products SET is_available = $2, updated_at = $3 WHERE sku = $1`
+
+	commandTag, err := r.db.Exec(ctx, query, sku, available, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to update product %s: %w", sku, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrProductNotFound
+	}
+
+	return nil
+}
+
+// SalesBySKU summarizes cards sold per product created in [from, to).
+// Products with no sales in the period are included with zero totals.
+func (r *ProductRepository) SalesBySKU(ctx context.Context, from, to time.Time) ([]*ProductSales, error) {
+	query := `SELECT
+		p.sku, p.name, p.fiat_currency,
+		COUNT(c.id),
+		COALESCE(SUM(c.fiat_amount_cents), 0),
+		COALESCE(SUM(c.purchase_price_cents), 0)
+	FROM card_products p
+	LEFT JOIN cards c ON c.product_id = p.id AND c.created_at >= $1 AND c.created_at < $2
+	GROUP BY p.id, p.sku, p.name, p.fiat_currency
+	ORDER BY p.sku`
+
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales by SKU: %w", err)
+	}
+	defer rows.Close()
+
+	var sales []*ProductSales
+	for rows.Next() {
+		var s ProductSales
+
+		err := rows.Scan(
+			&s.SKU,
+			&s.Name,
+			&s.FiatCurrency,
+			&s.CardsSold,
+			&s.FaceValueCents,
+			&s.PurchasePriceCents,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sales row: %w", err)
+		}
+
+		sales = append(sales, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return sales, nil
+}
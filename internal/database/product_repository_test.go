@@ -0,0 +1,77 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductRepository(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	products := NewProductRepository(db)
+	cards := NewCardRepository(db)
+
+	product := &CardProduct{
+		ID:              uuid.New().String(),
+		SKU:             "EUR-50",
+		Name:            "€50 gift card",
+		FiatAmountCents: 5000,
+		FiatCurrency:    "EUR",
+		FeeBps:          200,
+		IsAvailable:     true,
+		CreatedAt:       time.Now().UTC(),
+	}
+	require.NoError(t, products.Create(ctx, product))
+	assert.ErrorIs(t, products.Create(ctx, &CardProduct{ID: uuid.New().String(), SKU: "EUR-50", Name: "dup", FiatAmountCents: 1, FiatCurrency: "EUR", CreatedAt: time.Now().UTC()}), ErrProductSKUExists)
+
+	got, err := products.GetBySKU(ctx, "EUR-50")
+	require.NoError(t, err)
+	assert.Equal(t, product.ID, got.ID)
+	assert.Equal(t, int64(5100), got.PurchasePriceCents())
+
+	_, err = products.GetByID(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	// Toggle availability
+	require.NoError(t, products.SetAvailable(ctx, "EUR-50", false))
+	available, err := products.List(ctx, true)
+	require.NoError(t, err)
+	assert.Empty(t, available)
+	all, err := products.List(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+	assert.ErrorIs(t, products.SetAvailable(ctx, "NOPE", true), ErrProductNotFound)
+
+	// Sales report counts cards bought as the product
+	card := &Card{
+		ID:                 uuid.New().String(),
+		PurchaseEmail:      "buyer@example.com",
+		OwnerEmail:         "buyer@example.com",
+		Code:               "PROD-TEST-0001",
+		FiatAmountCents:    5000,
+		FiatCurrency:       "EUR",
+		PurchasePriceCents: 5100,
+		Status:             Created,
+		CreatedAt:          time.Now().UTC(),
+		ProductID:          &product.ID,
+	}
+	require.NoError(t, cards.Create(ctx, card))
+
+	sales, err := products.SalesBySKU(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, sales, 1)
+	assert.Equal(t, "EUR-50", sales[0].SKU)
+	assert.Equal(t, int64(1), sales[0].CardsSold)
+	assert.Equal(t, int64(5000), sales[0].FaceValueCents)
+	assert.Equal(t, int64(5100), sales[0].PurchasePriceCents)
+}
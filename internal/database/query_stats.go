@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrQueryStatsUnavailable is returned when the pg_stat_statements extension is not installed
+	ErrQueryStatsUnavailable = errors.New("pg_stat_statements extension is not installed")
+)
+
+// QueryStat is one normalized statement from pg_stat_statements.
+type QueryStat struct {
+	Query         string  // Normalized query text ($1, $2 placeholders)
+	Calls         int64   // Number of executions
+	TotalTimeMs   float64 // Total execution time in milliseconds
+	MeanTimeMs    float64 // Mean execution time in milliseconds
+	Rows          int64   // Total rows returned or affected
+	CacheHitRatio float64 // shared_blks_hit / (hit + read), 1.0 = fully cached
+}
+
+// SlowQueries returns the statements of the current database with the highest
+// mean execution time, for regression review as data volume grows.
+// Only statements with a mean time of at least minMeanMs are returned.
+// Returns ErrQueryStatsUnavailable if pg_stat_statements is not installed.
+func (db *DB) SlowQueries(ctx context.Context, limit int, minMeanMs float64) ([]*QueryStat, error) {
+	var installed bool
+	err := db.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')`,
+	).Scan(&installed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pg_stat_statements extension: %w", err)
+	}
+	if !installed {
+		return nil, ErrQueryStatsUnavailable
+	}
+
+	query := `SELECT
+		query,
+		calls,
+		total_exec_time,
+		mean_exec_time,
+		rows,
+		COALESCE(shared_blks_hit::float / NULLIF(shared_blks_hit + shared_blks_read, 0), 1.0)
+	FROM pg_stat_statements
+	WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+		AND mean_exec_time >= $1
+	ORDER BY mean_exec_time DESC
+	LIMIT $2`
+
+	rows, err := db.pool.Query(ctx, query, minMeanMs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_stat_statements: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*QueryStat
+	for rows.Next() {
+		var stat QueryStat
+
+		err := rows.Scan(
+			&stat.Query,
+			&stat.Calls,
+			&stat.TotalTimeMs,
+			&stat.MeanTimeMs,
+			&stat.Rows,
+			&stat.CacheHitRatio,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan query stat row: %w", err)
+		}
+
+		stats = append(stats, &stat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ResetQueryStats clears pg_stat_statements counters (e.g., after a deploy,
+// so the next report only reflects the new release).
+func (db *DB) ResetQueryStats(ctx context.Context) error {
+	if _, err := db.pool.Exec(ctx, `SELECT pg_stat_statements_reset()`); err != nil {
+		return fmt.Errorf("failed to reset pg_stat_statements: %w", err)
+	}
+	return nil
+}
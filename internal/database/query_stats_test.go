@@ -0,0 +1,30 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_SlowQueries(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	stats, err := db.SlowQueries(ctx, 10, 0)
+	if errors.Is(err, ErrQueryStatsUnavailable) {
+		t.Skip("pg_stat_statements not installed in test database")
+	}
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(stats), 10)
+
+	for i := 1; i < len(stats); i++ {
+		assert.GreaterOrEqual(t, stats[i-1].MeanTimeMs, stats[i].MeanTimeMs, "should be ordered by mean time")
+	}
+}
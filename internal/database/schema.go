@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrNoSchema is returned when no migration has ever been applied
+	ErrNoSchema = errors.New("database schema is not initialized (no schema_migrations table)")
+)
+
+// SchemaVersion returns the migration version recorded by golang-migrate and
+// whether the last migration failed halfway (dirty).
+func (db *DB) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == "42P01") { // undefined_table
+			return 0, false, ErrNoSchema
+		}
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// LegacyCardColumns returns the pre-custodial per-card wallet columns still
+// present on cards. Migration 000017 moves them to legacy_card_wallets; a
+// non-empty result means the database was forced past it.
+func (db *DB) LegacyCardColumns(ctx context.Context) ([]string, error) {
+	rows, err := db.pool.Query(ctx, `SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'cards'
+		  AND column_name IN ('wallet_address', 'encrypted_priv_key')
+		ORDER BY column_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read card columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("failed to scan card column: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read card columns: %w", err)
+	}
+	return columns, nil
+}
+
+// LatestMigration returns the highest version among the NNNNNN_name.up.sql
+// files in dir, i.e. the version a fully migrated database is at.
+func LatestMigration(dir string) (uint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var latest uint
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".up.sql") {
+			continue
+		}
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %s", filepath.Join(dir, name))
+		}
+		if uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations found in %s", dir)
+	}
+	return latest, nil
+}
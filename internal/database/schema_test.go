@@ -0,0 +1,36 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_SchemaVersion(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+
+	_, filename, _, _ := runtime.Caller(0)
+	latest, err := LatestMigration(filepath.Join(filepath.Dir(filename), "..", "..", "migrations"))
+	require.NoError(t, err)
+
+	version, dirty, err := db.SchemaVersion(context.Background())
+	require.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, latest, version, "SetupTestDB migrates to the latest version")
+}
+
+func TestDB_LegacyCardColumns(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+
+	columns, err := db.LegacyCardColumns(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, columns, "migrated databases have no per-card wallet columns")
+}
@@ -57,7 +57,7 @@ func CleanupTestDB(t *testing.T, db *DB) {
 	defer cancel()
 
 	// Truncate in reverse order due to foreign keys
-	tables := []string{"transactions", "cards"}
+	tables := []string{"retired_card_codes", "otc_fills", "processed_messages", "treasury_ledger", "treasury_withdrawals", "deposits", "card_gifts", "transactions_archive", "transactions", "cards", "card_batches", "card_products"}
 	for _, table := range tables {
 		query := fmt.Sprintf("TRUNCATE TABLE %s CASCADE", table)
 		_, err := db.pool.Exec(ctx, query)
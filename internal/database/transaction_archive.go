@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// transactionColumns lists the columns shared by transactions and transactions_archive,
+// in the order they are scanned into a Transaction.
+const transactionColumns = `id, card_id, type, redemption_method, tx_hash, payment_hash, payment_preimage,
+		lightning_invoice, from_address, to_address,
+		btc_amount_sats, status, confirmations, created_at,
+		broadcast_at, confirmed_at, fiat_amount_cents, external_reference, redemption_id`
+
+// ArchiveBefore moves up to limit settled (confirmed/failed) transactions created
+// before cutoff, belonging to terminal (redeemed/expired) cards, from transactions
+// into transactions_archive. The move is a single statement, so a row is never
+// in both tables or in neither. Returns the number of rows archived; callers loop
+// until it returns less than limit.
+func (r *TransactionRepository) ArchiveBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `WITH moved AS (
+		DELETE FROM transactions
+		WHERE id IN (
+			SELECT t.id FROM transactions t
+			JOIN cards c ON c.id = t.card_id
+			WHERE t.created_at < $1
+				AND t.status IN ('confirmed', 'failed')
+				AND c.status IN ('redeemed', 'expired')
+			ORDER BY t.created_at
+			LIMIT $2
+			FOR UPDATE OF t SKIP LOCKED
+		)
+		RETURNING ` + transactionColumns + `
+	)
+	INSERT INTO transactions_archive (` + transactionColumns + `)
+	SELECT ` + transactionColumns + ` FROM moved`
+
+	commandTag, err := r.db.Exec(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive transactions before %s: %w", cutoff.Format(time.RFC3339), err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// ListByCardIDWithArchive retrieves all transactions for a card from both the live
+// and archive tables, ordered by creation date (newest first). Use it for full
+// history lookups (support, statements); hot paths should use ListByCardID.
+func (r *TransactionRepository) ListByCardIDWithArchive(ctx context.Context, cardID string) ([]*Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE card_id = $1
+		UNION ALL
+		SELECT ` + transactionColumns + ` FROM transactions_archive WHERE card_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions (with archive) of card %s: %w", cardID, err)
+	}
+	defer rows.Close()
+
+	var transactions []*Transaction
+	for rows.Next() {
+		var transaction Transaction
+
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.CardID,
+			&transaction.Type,
+			&transaction.RedemptionMethod,
+			&transaction.TxHash,
+			&transaction.PaymentHash,
+			&transaction.PaymentPreimage,
+			&transaction.LightningInvoice,
+			&transaction.FromAddress,
+			&transaction.ToAddress,
+			&transaction.BTCAmountSats,
+			&transaction.Status,
+			&transaction.Confirmations,
+			&transaction.CreatedAt,
+			&transaction.BroadcastAt,
+			&transaction.ConfirmedAt,
+			&transaction.FiatAmountCents,
+			&transaction.ExternalReference,
+			&transaction.RedemptionID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction row: %w", err)
+		}
+
+		transactions = append(transactions, &transaction)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return transactions, nil
+}
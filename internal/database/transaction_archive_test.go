@@ -0,0 +1,162 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createArchiveTestCard creates a card with the given status for archive tests.
+func createArchiveTestCard(t *testing.T, ctx context.Context, repo *CardRepository, code string, status CardStatus) string {
+	t.Helper()
+
+	cardID := uuid.New().String()
+	err := repo.Create(ctx, &Card{
+		ID:                 cardID,
+		PurchaseEmail:      "test@example.com",
+		OwnerEmail:         "test@example.com",
+		Code:               code,
+		BTCAmountSats:      100000,
+		FiatAmountCents:    5000,
+		FiatCurrency:       "USD",
+		PurchasePriceCents: 5150,
+		Status:             status,
+		CreatedAt:          time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	return cardID
+}
+
+// createArchiveTestTx creates a transaction with the given status and age.
+func createArchiveTestTx(t *testing.T, ctx context.Context, repo *TransactionRepository, cardID string, status TransactionStatus, createdAt time.Time) string {
+	t.Helper()
+
+	txID := uuid.New().String()
+	err := repo.Create(ctx, &Transaction{
+		ID:            txID,
+		CardID:        cardID,
+		Type:          Fund,
+		BTCAmountSats: 100000,
+		Status:        status,
+		CreatedAt:     createdAt,
+	})
+	require.NoError(t, err)
+	return txID
+}
+
+func TestTransactionRepository_ArchiveBefore(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	cardRepo := NewCardRepository(db)
+	txRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	old := time.Now().UTC().AddDate(-2, 0, 0)
+	recent := time.Now().UTC().AddDate(0, -1, 0)
+	cutoff := time.Now().UTC().AddDate(-1, 0, 0)
+
+	redeemedCard := createArchiveTestCard(t, ctx, cardRepo, "ARCHIVE-REDEEMED", Redeemed)
+	activeCard := createArchiveTestCard(t, ctx, cardRepo, "ARCHIVE-ACTIVE", Active)
+
+	archivedTx := createArchiveTestTx(t, ctx, txRepo, redeemedCard, Confirmed, old)
+	recentTx := createArchiveTestTx(t, ctx, txRepo, redeemedCard, Confirmed, recent)
+	pendingTx := createArchiveTestTx(t, ctx, txRepo, redeemedCard, Pending, old)
+	activeCardTx := createArchiveTestTx(t, ctx, txRepo, activeCard, Confirmed, old)
+
+	archived, err := txRepo.ArchiveBefore(ctx, cutoff, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), archived, "only old, settled transactions of terminal cards are archived")
+
+	_, err = txRepo.GetByID(ctx, archivedTx)
+	assert.ErrorIs(t, err, ErrTransactionNotFound, "archived transaction should leave the live table")
+
+	for _, id := range []string{recentTx, pendingTx, activeCardTx} {
+		_, err := txRepo.GetByID(ctx, id)
+		assert.NoError(t, err)
+	}
+
+	// Full history includes the archived row
+	live, err := txRepo.ListByCardID(ctx, redeemedCard)
+	require.NoError(t, err)
+	assert.Len(t, live, 2)
+
+	all, err := txRepo.ListByCardIDWithArchive(ctx, redeemedCard)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, archivedTx, all[len(all)-1].ID, "oldest transaction should be last")
+
+	// Nothing left to archive
+	archived, err = txRepo.ArchiveBefore(ctx, cutoff, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), archived)
+}
+
+func TestTransactionRepository_ArchiveBefore_Limit(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	cardRepo := NewCardRepository(db)
+	txRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	cardID := createArchiveTestCard(t, ctx, cardRepo, "ARCHIVE-LIMIT", Expired)
+	old := time.Now().UTC().AddDate(-2, 0, 0)
+	for i := 0; i < 3; i++ {
+		createArchiveTestTx(t, ctx, txRepo, cardID, Failed, old.Add(time.Duration(i)*time.Minute))
+	}
+
+	archived, err := txRepo.ArchiveBefore(ctx, time.Now().UTC(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), archived)
+
+	archived, err = txRepo.ArchiveBefore(ctx, time.Now().UTC(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+}
+
+func TestTransactionRepository_RedactInvoicesBefore(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	cardRepo := NewCardRepository(db)
+	txRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	cardID := createArchiveTestCard(t, ctx, cardRepo, "REDACT-INVOICES", Redeemed)
+	create := func(invoice string, createdAt time.Time) string {
+		id := uuid.New().String()
+		require.NoError(t, txRepo.Create(ctx, &Transaction{
+			ID: id, CardID: cardID, Type: Redeem, LightningInvoice: &invoice,
+			BTCAmountSats: 1000, Status: Confirmed, CreatedAt: createdAt,
+		}))
+		return id
+	}
+	old := create("lntb10u1pold", time.Now().UTC().AddDate(0, 0, -60))
+	recent := create("lntb10u1precent", time.Now().UTC())
+
+	redacted, err := txRepo.RedactInvoicesBefore(ctx, time.Now().UTC().AddDate(0, 0, -30), 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), redacted)
+
+	tx, err := txRepo.GetByID(ctx, old)
+	require.NoError(t, err)
+	assert.Equal(t, HashInvoice("lntb10u1pold"), *tx.LightningInvoice, "SQL and Go hash alike")
+	tx, err = txRepo.GetByID(ctx, recent)
+	require.NoError(t, err)
+	assert.Equal(t, "lntb10u1precent", *tx.LightningInvoice)
+
+	// Already redacted rows are left alone
+	redacted, err = txRepo.RedactInvoicesBefore(ctx, time.Now().UTC().AddDate(0, 0, -30), 100)
+	require.NoError(t, err)
+	assert.Zero(t, redacted)
+}
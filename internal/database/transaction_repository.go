@@ -46,9 +46,12 @@ func (r *TransactionRepository) Create(ctx context.Context, tx *Transaction) err
 		confirmations,
 		created_at,
 		broadcast_at,
-		confirmed_at
+		confirmed_at,
+		fiat_amount_cents,
+		external_reference,
+		redemption_id
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
 
 	_, err := r.db.Exec(
 		ctx,
@@ -69,6 +72,9 @@ func (r *TransactionRepository) Create(ctx context.Context, tx *Transaction) err
 		tx.CreatedAt,
 		tx.BroadcastAt,
 		tx.ConfirmedAt,
+		tx.FiatAmountCents,
+		tx.ExternalReference,
+		tx.RedemptionID,
 	)
 
 	if err != nil {
@@ -85,7 +91,7 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*Transa
 		id, card_id, type, redemption_method, tx_hash, payment_hash, payment_preimage,
 		lightning_invoice, from_address, to_address,
 		btc_amount_sats, status, confirmations, created_at,
-		broadcast_at, confirmed_at
+		broadcast_at, confirmed_at, fiat_amount_cents, external_reference, redemption_id
     FROM transactions WHERE id = $1`
 
 	var transaction Transaction
@@ -107,6 +113,9 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*Transa
 		&transaction.CreatedAt,
 		&transaction.BroadcastAt,
 		&transaction.ConfirmedAt,
+		&transaction.FiatAmountCents,
+		&transaction.ExternalReference,
+		&transaction.RedemptionID,
 	)
 
 	if err != nil {
@@ -126,7 +135,7 @@ func (r *TransactionRepository) GetByTxHash(ctx context.Context, txHash string)
 		id, card_id, type, redemption_method, tx_hash, payment_hash, payment_preimage,
 		lightning_invoice, from_address, to_address,
 		btc_amount_sats, status, confirmations, created_at,
-		broadcast_at, confirmed_at
+		broadcast_at, confirmed_at, fiat_amount_cents, external_reference, redemption_id
     FROM transactions WHERE tx_hash = $1`
 
 	var transaction Transaction
@@ -148,6 +157,9 @@ func (r *TransactionRepository) GetByTxHash(ctx context.Context, txHash string)
 		&transaction.CreatedAt,
 		&transaction.BroadcastAt,
 		&transaction.ConfirmedAt,
+		&transaction.FiatAmountCents,
+		&transaction.ExternalReference,
+		&transaction.RedemptionID,
 	)
 
 	if err != nil {
@@ -167,7 +179,7 @@ func (r *TransactionRepository) ListByCardID(ctx context.Context, cardID string)
 		id, card_id, type, redemption_method, tx_hash, payment_hash, payment_preimage,
 		lightning_invoice, from_address, to_address,
 		btc_amount_sats, status, confirmations, created_at,
-		broadcast_at, confirmed_at
+		broadcast_at, confirmed_at, fiat_amount_cents, external_reference, redemption_id
     FROM transactions WHERE card_id = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, cardID)
@@ -197,6 +209,9 @@ func (r *TransactionRepository) ListByCardID(ctx context.Context, cardID string)
 			&transaction.CreatedAt,
 			&transaction.BroadcastAt,
 			&transaction.ConfirmedAt,
+			&transaction.FiatAmountCents,
+			&transaction.ExternalReference,
+			&transaction.RedemptionID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction row: %w", err)
@@ -235,3 +250,82 @@ func (r *TransactionRepository) Update(ctx context.Context, id string, status Tr
 
 	return nil
 }
+
+// SetExternalReference records the external provider reference (e.g., a
+// payment provider refund ID) on a transaction.
+// Returns ErrTransactionNotFound if the transaction ID does not exist.
+func (r *TransactionRepository) SetExternalReference(ctx context.Context, id string, reference string) error {
+	query := `UPDATE transactions SET external_reference = $2 WHERE id = $1`
+
+	commandTag, err := r.db.Exec(ctx, query, id, reference)
+	if err != nil {
+		return fmt.Errorf("failed to set external reference on transaction %s: %w", id, err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrTransactionNotFound
+	}
+
+	return nil
+}
+
+// FundedSats returns the total of a card's Fund transactions (failed ones
+// excluded), i.e. how much of its funded balance is recorded in the ledger.
+func (r *TransactionRepository) FundedSats(ctx context.Context, cardID string) (int64, error) {
+	query := `SELECT COALESCE(SUM(btc_amount_sats), 0) FROM transactions
+		WHERE card_id = $1 AND type = 'fund' AND status <> 'failed'`
+
+	var total int64
+	if err := r.db.QueryRow(ctx, query, cardID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to get funded sats of card %s: %w", cardID, err)
+	}
+
+	return total, nil
+}
+
+// LedgerDiscrepancies returns up to limit cards whose balance doesn't match
+// their transactions (fund tranches minus redemptions and refunds, failed
+// transactions excluded, archived ones included) or is negative. Cards with a
+// refund in progress are skipped: the pending refund is already in the ledger
+// but the balance is only zeroed once the provider accepts it. An empty
+// result means card balances reconcile with the transaction ledger.
+func (r *TransactionRepository) LedgerDiscrepancies(ctx context.Context, limit int) ([]*LedgerDiscrepancy, error) {
+	query := `WITH ledger AS (
+		SELECT card_id,
+			SUM(CASE WHEN type = 'fund' THEN btc_amount_sats ELSE -btc_amount_sats END) AS sats
+		FROM (
+			SELECT card_id, type, btc_amount_sats, status FROM transactions
+			UNION ALL
+			SELECT card_id, type, btc_amount_sats, status FROM transactions_archive
+		) t
+		WHERE status <> 'failed'
+		GROUP BY card_id
+	)
+	SELECT c.id, c.btc_amount_sats, COALESCE(l.sats, 0)
+	FROM cards c
+	LEFT JOIN ledger l ON l.card_id = c.id
+	WHERE c.status <> 'refunding' AND (c.btc_amount_sats <> COALESCE(l.sats, 0) OR c.btc_amount_sats < 0)
+	ORDER BY c.id
+	LIMIT $1`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ledger: %w", err)
+	}
+	defer rows.Close()
+
+	var discrepancies []*LedgerDiscrepancy
+	for rows.Next() {
+		var d LedgerDiscrepancy
+		if err := rows.Scan(&d.CardID, &d.BalanceSats, &d.LedgerSats); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger row: %w", err)
+		}
+		discrepancies = append(discrepancies, &d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", err)
+	}
+
+	return discrepancies, nil
+}
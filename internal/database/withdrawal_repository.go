@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrWithdrawalNotFound is returned when no withdrawal matches the lookup
+var ErrWithdrawalNotFound = errors.New("withdrawal not found")
+
+const withdrawalColumns = `id, amount_sats, destination_address, reason,
+	requested_by, decided_by, status, tx_hash,
+	created_at, decided_at, sent_at`
+
+// WithdrawalRepository handles all database operations for owner withdrawals
+// and the treasury ledger debits they produce
+type WithdrawalRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWithdrawalRepository creates a new withdrawal repository instance
+func NewWithdrawalRepository(db *DB) *WithdrawalRepository {
+	return &WithdrawalRepository{
+		db: db.pool,
+	}
+}
+
+// Create inserts a requested withdrawal.
+func (r *WithdrawalRepository) Create(ctx context.Context, w *TreasuryWithdrawal) error {
+	query := `INSERT INTO treasury_withdrawals (
+		id,
+		amount_sats,
+		destination_address,
+		reason,
+		requested_by,
+		status,
+		created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.Exec(
+		ctx,
+		query,
+		w.ID,
+		w.AmountSats,
+		w.DestinationAddress,
+		w.Reason,
+		w.RequestedBy,
+		w.Status,
+		w.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create withdrawal: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a withdrawal by ID. Returns ErrWithdrawalNotFound if missing.
+func (r *WithdrawalRepository) GetByID(ctx context.Context, id string) (*TreasuryWithdrawal, error) {
+	w, err := scanWithdrawal(r.db.QueryRow(ctx, `SELECT `+withdrawalColumns+` FROM treasury_withdrawals WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWithdrawalNotFound
+		}
+		return nil, fmt.Errorf("failed to get withdrawal: %w", err)
+	}
+	return w, nil
+}
+
+// List returns the most recent withdrawals, newest first.
+func (r *WithdrawalRepository) List(ctx context.Context, limit int) ([]*TreasuryWithdrawal, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+withdrawalColumns+` FROM treasury_withdrawals ORDER BY created_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list withdrawals: %w", err)
+	}
+	defer rows.Close()
+
+	var withdrawals []*TreasuryWithdrawal
+	for rows.Next() {
+		w, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan withdrawal: %w", err)
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list withdrawals: %w", err)
+	}
+
+	return withdrawals, nil
+}
+
+func scanWithdrawal(row pgx.Row) (*TreasuryWithdrawal, error) {
+	var w TreasuryWithdrawal
+	err := row.Scan(
+		&w.ID,
+		&w.AmountSats,
+		&w.DestinationAddress,
+		&w.Reason,
+		&w.RequestedBy,
+		&w.DecidedBy,
+		&w.Status,
+		&w.TxHash,
+		&w.CreatedAt,
+		&w.DecidedAt,
+		&w.SentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Decide moves a requested withdrawal to approved or rejected. Returns false
+// without error if the withdrawal is no longer requested or decidedBy is the
+// requester (two-person rule), so only one decision ever wins.
+func (r *WithdrawalRepository) Decide(ctx context.Context, id string, status WithdrawalStatus, decidedBy string, decidedAt time.Time) (bool, error) {
+	query := `UPDATE treasury_withdrawals
+		SET status = $2, decided_by = $3, decided_at = $4
+		WHERE id = $1 AND status = 'requested' AND requested_by <> $3`
+
+	commandTag, err := r.db.Exec(ctx, query, id, status, decidedBy, decidedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to decide withdrawal %s: %w", id, err)
+	}
+
+	return commandTag.RowsAffected() == 1, nil
+}
+
+// MarkSent marks an approved withdrawal sent and debits the house treasury in
+// treasury_ledger, in a single statement. Returns false without error if the
+// withdrawal was not approved (e.g. already marked sent).
+func (r *WithdrawalRepository) MarkSent(ctx context.Context, id, txHash string, sentAt time.Time) (bool, error) {
+	query := `WITH sent AS (
+		UPDATE treasury_withdrawals
+		SET status = 'sent', tx_hash = $2, sent_at = $3
+		WHERE id = $1 AND status = 'approved'
+		RETURNING id, amount_sats
+	)
+	INSERT INTO treasury_ledger (id, account_id, withdrawal_id, amount_sats, entry_type, created_at)
+	SELECT $4, NULL, id, -amount_sats, $5, $3 FROM sent`
+
+	commandTag, err := r.db.Exec(ctx, query, id, txHash, sentAt, uuid.New().String(), LedgerWithdrawal)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark withdrawal %s sent: %w", id, err)
+	}
+
+	return commandTag.RowsAffected() == 1, nil
+}
+
+// MarkFailed marks an approved withdrawal failed (the custodian refused the send).
+func (r *WithdrawalRepository) MarkFailed(ctx context.Context, id string) error {
+	query := `UPDATE treasury_withdrawals SET status = 'failed' WHERE id = $1 AND status = 'approved'`
+
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark withdrawal %s failed: %w", id, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithdrawalRepository_Lifecycle(t *testing.T) {
+	db := SetupTestDB(t)
+	defer db.Close()
+	defer CleanupTestDB(t, db)
+
+	repo := NewWithdrawalRepository(db)
+	deposits := NewDepositRepository(db)
+	ctx := context.Background()
+
+	w := &TreasuryWithdrawal{
+		ID:                 uuid.New().String(),
+		AmountSats:         2_000_000,
+		DestinationAddress: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+		Reason:             "Q1 profit",
+		RequestedBy:        "alice",
+		Status:             WithdrawalRequested,
+		CreatedAt:          time.Now().UTC(),
+	}
+	require.NoError(t, repo.Create(ctx, w))
+
+	// The requester cannot approve their own withdrawal
+	decided, err := repo.Decide(ctx, w.ID, WithdrawalApproved, "alice", time.Now().UTC())
+	require.NoError(t, err)
+	assert.False(t, decided)
+
+	decided, err = repo.Decide(ctx, w.ID, WithdrawalApproved, "bob", time.Now().UTC())
+	require.NoError(t, err)
+	assert.True(t, decided)
+
+	// Only one decision wins
+	decided, err = repo.Decide(ctx, w.ID, WithdrawalRejected, "carol", time.Now().UTC())
+	require.NoError(t, err)
+	assert.False(t, decided)
+
+	sent, err := repo.MarkSent(ctx, w.ID, "ab12", time.Now().UTC())
+	require.NoError(t, err)
+	assert.True(t, sent)
+
+	sent, err = repo.MarkSent(ctx, w.ID, "ab12", time.Now().UTC())
+	require.NoError(t, err)
+	assert.False(t, sent, "already sent, no second ledger entry")
+
+	got, err := repo.GetByID(ctx, w.ID)
+	require.NoError(t, err)
+	assert.Equal(t, WithdrawalSent, got.Status)
+	require.NotNil(t, got.DecidedBy)
+	assert.Equal(t, "bob", *got.DecidedBy)
+	require.NotNil(t, got.TxHash)
+
+	balance, err := deposits.LedgerBalance(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-2_000_000), balance, "house treasury debited")
+
+	list, err := repo.List(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	_, err = repo.GetByID(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, ErrWithdrawalNotFound)
+}
@@ -0,0 +1,190 @@
+// Package deposit handles incoming Lightning payments to the treasury.
+//
+// A deposit starts as an invoice we issue (CreateInvoice), recorded pending
+// with a "deposit:<id>" memo. The invoice settlement worker
+// (cmd/worker/invoice_settlement) feeds every settled invoice to
+// HandleSettled, which matches it to its deposit — by payment hash, falling
+// back to the memo ID — and credits the deposit's account in treasury_ledger.
+// Settled invoices that match nothing are still recorded and credited to the
+// house treasury, so every sat received is accounted for.
+package deposit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/lnd"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoPattern extracts the deposit ID from an invoice memo.
+var memoPattern = regexp.MustCompile(`deposit:([0-9a-fA-F-]{36})`)
+
+// Service issues deposit invoices and credits them once settled.
+type Service struct {
+	repo   *database.DepositRepository
+	client lnd.LightningClient
+	expiry time.Duration // Invoice expiry
+}
+
+// NewService creates a deposit service. client may be nil for a settlement-only service.
+func NewService(repo *database.DepositRepository, client lnd.LightningClient, expiry time.Duration) *Service {
+	return &Service{
+		repo:   repo,
+		client: client,
+		expiry: expiry,
+	}
+}
+
+// Memo returns the invoice memo that correlates an invoice with a deposit ID.
+func Memo(depositID string) string {
+	return "btc-giftcard deposit:" + depositID
+}
+
+// ParseMemo returns the deposit ID carried in an invoice memo, or "" if none.
+func ParseMemo(memo string) string {
+	m := memoPattern.FindStringSubmatch(memo)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// CreateInvoice issues an invoice for amountSats and records a pending deposit
+// that credits accountID (nil = house treasury) once paid.
+func (s *Service) CreateInvoice(ctx context.Context, accountID *string, amountSats int64) (*database.Deposit, error) {
+	if s.client == nil {
+		return nil, errors.New("deposit service has no LND client")
+	}
+	if amountSats <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	id := uuid.New().String()
+	invoice, err := s.client.AddInvoice(ctx, amountSats, Memo(id), int64(s.expiry.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(s.expiry)
+	deposit := &database.Deposit{
+		ID:             id,
+		AccountID:      accountID,
+		PaymentHash:    invoice.PaymentHash,
+		PaymentRequest: invoice.PaymentRequest,
+		Memo:           Memo(id),
+		ExpectedSats:   amountSats,
+		Status:         database.DepositPending,
+		CreatedAt:      now,
+		ExpiresAt:      &expiresAt,
+	}
+	if err := s.repo.Create(ctx, deposit); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Deposit invoice created",
+		zap.String("deposit_id", id),
+		zap.Int64("amount_sats", amountSats),
+		zap.String("payment_hash", invoice.PaymentHash),
+	)
+	return deposit, nil
+}
+
+// HandleSettled records a settled invoice and credits the matching deposit's
+// account, or the house treasury if nothing matches. Safe to call more than
+// once for the same invoice (subscriptions replay on reconnect): the invoice's
+// payment hash is recorded on the deposit it settles, so a replay matches by
+// hash and finds it already settled.
+func (s *Service) HandleSettled(ctx context.Context, inv *lnd.SettledInvoice) error {
+	deposit, err := s.match(ctx, inv)
+	if err != nil {
+		return err
+	}
+
+	if deposit == nil {
+		receivedSats := inv.AmountPaidSats
+		settleIndex := int64(inv.SettleIndex)
+		credited, err := s.repo.RecordUnmatched(ctx, &database.Deposit{
+			ID:             uuid.New().String(),
+			PaymentHash:    inv.PaymentHash,
+			PaymentRequest: inv.PaymentRequest,
+			Memo:           inv.Memo,
+			ReceivedSats:   &receivedSats,
+			SettleIndex:    &settleIndex,
+			SettledAt:      &inv.SettledAt,
+		})
+		if err != nil {
+			return err
+		}
+		if credited {
+			logger.Warn("Settled invoice matches no deposit, credited to house treasury",
+				zap.String("payment_hash", inv.PaymentHash),
+				zap.String("memo", inv.Memo),
+				zap.Int64("amount_sats", inv.AmountPaidSats),
+			)
+		}
+		return nil
+	}
+
+	credited, err := s.repo.Settle(ctx, deposit.ID, inv.PaymentHash, inv.AmountPaidSats, int64(inv.SettleIndex), inv.SettledAt)
+	if err != nil {
+		return err
+	}
+	if !credited {
+		logger.Debug("Deposit already settled", zap.String("deposit_id", deposit.ID))
+		return nil
+	}
+
+	if inv.AmountPaidSats != deposit.ExpectedSats {
+		logger.Warn("Deposit amount differs from invoice",
+			zap.String("deposit_id", deposit.ID),
+			zap.Int64("expected_sats", deposit.ExpectedSats),
+			zap.Int64("received_sats", inv.AmountPaidSats),
+		)
+	}
+	logger.Info("Deposit settled",
+		zap.String("deposit_id", deposit.ID),
+		zap.Stringp("account_id", deposit.AccountID),
+		zap.Int64("amount_sats", inv.AmountPaidSats),
+		zap.Uint64("settle_index", inv.SettleIndex),
+	)
+	return nil
+}
+
+// match finds the deposit for a settled invoice: by payment hash, then by the
+// deposit ID in its memo. A memo match only counts while the deposit is still
+// pending — a second invoice paid for an already settled deposit is credited
+// as unmatched instead of being dropped. Returns nil if nothing matches.
+func (s *Service) match(ctx context.Context, inv *lnd.SettledInvoice) (*database.Deposit, error) {
+	deposit, err := s.repo.GetByPaymentHash(ctx, inv.PaymentHash)
+	if err == nil {
+		return deposit, nil
+	}
+	if !errors.Is(err, database.ErrDepositNotFound) {
+		return nil, err
+	}
+
+	id := ParseMemo(inv.Memo)
+	if id == "" {
+		return nil, nil
+	}
+	deposit, err = s.repo.GetByID(ctx, id)
+	if errors.Is(err, database.ErrDepositNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to match deposit from memo: %w", err)
+	}
+	if deposit.Status != database.DepositPending {
+		return nil, nil
+	}
+	return deposit, nil
+}
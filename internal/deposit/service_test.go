@@ -0,0 +1,16 @@
+package deposit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMemo(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+
+	assert.Equal(t, id, ParseMemo(Memo(id)), "round-trips the memo we issue")
+	assert.Equal(t, id, ParseMemo("ACME top-up deposit:"+id+" (March)"), "found anywhere in the memo")
+	assert.Equal(t, "", ParseMemo("coffee"))
+	assert.Equal(t, "", ParseMemo("deposit:not-a-uuid"))
+}
@@ -0,0 +1,316 @@
+// Package doctor validates a deployment's configuration against the services
+// it points to (used by `giftcardctl doctor`). Every check produces a Result
+// with a remedy the operator can act on, instead of the first error a service
+// would fail with at startup.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/card"
+	"btc-giftcard/internal/exchange"
+	"btc-giftcard/internal/guardrail"
+	"btc-giftcard/internal/lnd"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusOK   Status = "OK"
+	StatusWarn Status = "WARN" // Works, but something is likely to bite later
+	StatusFail Status = "FAIL" // A service will not start or not work
+)
+
+// Result is one check outcome.
+type Result struct {
+	Check  string // e.g. "database.schema"
+	Status Status
+	Detail string // What was found
+	Remedy string // What to do about it (empty when OK)
+}
+
+// Report collects check results in the order they ran.
+type Report struct {
+	Results []Result
+}
+
+// OK records a passing check.
+func (r *Report) OK(check, detail string) {
+	r.Results = append(r.Results, Result{Check: check, Status: StatusOK, Detail: detail})
+}
+
+// Warn records a check that passed with a caveat.
+func (r *Report) Warn(check, detail, remedy string) {
+	r.Results = append(r.Results, Result{Check: check, Status: StatusWarn, Detail: detail, Remedy: remedy})
+}
+
+// Fail records a failed check.
+func (r *Report) Fail(check, detail, remedy string) {
+	r.Results = append(r.Results, Result{Check: check, Status: StatusFail, Detail: detail, Remedy: remedy})
+}
+
+// Failed reports whether any check failed.
+func (r *Report) Failed() bool {
+	for _, res := range r.Results {
+		if res.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints the report as a table, with the remedy under each check that
+// is not OK, followed by a summary line.
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tCHECK\tDETAIL")
+	counts := map[Status]int{}
+	for _, res := range r.Results {
+		counts[res.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", res.Status, res.Check, res.Detail)
+		if res.Remedy != "" {
+			fmt.Fprintf(tw, "\t\t→ %s\n", res.Remedy)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d ok, %d warnings, %d failures\n", counts[StatusOK], counts[StatusWarn], counts[StatusFail])
+	return err
+}
+
+// ============================================================================
+// Checks
+// ============================================================================
+
+// CheckConfig validates settings that can be checked without connecting
+// anywhere.
+func CheckConfig(r *Report, cfg config.ApiConfig) {
+	if err := guardrail.Validate(cfg); err != nil {
+		r.Fail("config.network", strings.ReplaceAll(err.Error(), "\n", "; "),
+			"services refuse to start with this network config, see the [guardrails] section")
+	} else {
+		r.OK("config.network", cfg.LND.Network)
+	}
+
+	for _, f := range []struct{ check, path, key string }{
+		{"config.lnd_tls_cert", cfg.LND.TLSCertPath, "tls_cert_path"},
+		{"config.lnd_macaroon", cfg.LND.MacaroonPath, "macaroon_path"},
+	} {
+		if f.path == "" {
+			r.Fail(f.check, "not set", fmt.Sprintf("set [lnd] %s", f.key))
+		} else if _, err := os.Stat(f.path); err != nil {
+			r.Fail(f.check, err.Error(), fmt.Sprintf("fix [lnd] %s or mount the file from the LND data directory", f.key))
+		} else {
+			r.OK(f.check, f.path)
+		}
+	}
+
+	if hold := cfg.Redemption.InFlightHoldSeconds; hold <= cfg.LND.PaymentTimeoutSeconds {
+		r.Warn("config.inflight_hold", fmt.Sprintf("inflight_hold_seconds (%d) <= payment_timeout_seconds (%d)", hold, cfg.LND.PaymentTimeoutSeconds),
+			"raise [redemption] inflight_hold_seconds above the payment timeout, or liquidity holds expire before payments settle")
+	}
+
+	if _, ok := card.ParseMemoPolicy(cfg.Retention.InvoiceMemoPolicy); !ok {
+		r.Warn("config.invoice_memo_policy", fmt.Sprintf("unknown policy %q, invoices are hashed", cfg.Retention.InvoiceMemoPolicy),
+			`set [retention] invoice_memo_policy to "keep", "hash" or "strip"`)
+	}
+
+	if cfg.Payouts.BaseURL != "" {
+		if cfg.Payouts.APIKey == "" || cfg.Payouts.WebhookSecret == "" {
+			r.Fail("config.payouts", "base_url is set but api_key or webhook_secret is empty",
+				"set [payouts] api_key and webhook_secret, or clear base_url to disable bank payouts")
+		} else {
+			r.OK("config.payouts", cfg.Payouts.BaseURL)
+		}
+	}
+
+	if s := cfg.Support.RedemptionSecret; s != "" && len(s) < 32 {
+		r.Warn("config.support", fmt.Sprintf("redemption_secret is only %d characters", len(s)),
+			"use a random secret of at least 32 characters (e.g. openssl rand -hex 32)")
+	}
+}
+
+// SchemaChecker is the part of database.DB the schema check needs.
+type SchemaChecker interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+	LegacyCardColumns(ctx context.Context) ([]string, error)
+}
+
+// CheckDatabase verifies the database answers and is migrated to latest, the
+// highest migration shipped with this build.
+func CheckDatabase(ctx context.Context, r *Report, db SchemaChecker, latest uint) {
+	if err := db.Ping(ctx); err != nil {
+		r.Fail("database.connection", err.Error(), "check [database] host, port and credentials, and that PostgreSQL is running")
+		return
+	}
+	r.OK("database.connection", "reachable")
+
+	version, dirty, err := db.SchemaVersion(ctx)
+	switch {
+	case err != nil:
+		r.Fail("database.schema", err.Error(), "start the API once (it runs migrations) or run golang-migrate against migrations/")
+	case dirty:
+		r.Fail("database.schema", fmt.Sprintf("version %d is dirty (a migration failed halfway)", version),
+			"fix the schema by hand, then `migrate force <last good version>` and rerun the migrations")
+	case version < latest:
+		r.Fail("database.schema", fmt.Sprintf("version %d, expected %d", version, latest),
+			"start the API once (it runs migrations) or run golang-migrate up")
+	case version > latest:
+		r.Warn("database.schema", fmt.Sprintf("version %d is newer than this build (%d)", version, latest),
+			"deploy the release that matches the database, this build may not know newer columns")
+	default:
+		r.OK("database.schema", fmt.Sprintf("version %d", version))
+	}
+
+	// Before 000017 has run the legacy columns are expected
+	if err != nil || dirty || version < latest {
+		return
+	}
+	legacy, err := db.LegacyCardColumns(ctx)
+	switch {
+	case err != nil:
+		r.Warn("database.legacy_columns", err.Error(), "")
+	case len(legacy) > 0:
+		r.Warn("database.legacy_columns", fmt.Sprintf("cards still has the pre-custodial columns %s", strings.Join(legacy, ", ")),
+			"migration 000017 moves them to legacy_card_wallets: `migrate force 16` and rerun the migrations")
+	}
+}
+
+// Consumer is a stream consumer group read by a worker.
+type Consumer struct {
+	Stream string
+	Group  string
+	Worker string // Command that declares the group on startup
+}
+
+// Consumers are the consumer groups the workers in cmd/worker read from.
+var Consumers = []Consumer{
+	{Stream: "fund_card", Group: "fund_workers", Worker: "cmd/worker/fund_card"},
+}
+
+// AnalyticsConsumer is checked in addition to Consumers when [analytics] is enabled.
+var AnalyticsConsumer = Consumer{Stream: "analytics_events", Group: "analytics_workers", Worker: "cmd/worker/analytics"}
+
+// GroupChecker is the part of queue.StreamQueue the stream check needs.
+type GroupChecker interface {
+	GroupExists(ctx context.Context, stream string, group string) (bool, error)
+}
+
+// CheckStreams verifies every consumer group exists. A group only exists once
+// its worker has started, until then published messages just accumulate.
+func CheckStreams(ctx context.Context, r *Report, q GroupChecker, consumers []Consumer) {
+	for _, c := range consumers {
+		check := "redis.stream." + c.Stream
+		exists, err := q.GroupExists(ctx, c.Stream, c.Group)
+		switch {
+		case err != nil:
+			r.Fail(check, err.Error(), "check [redis] host, port and password, and that Redis is running")
+		case !exists:
+			r.Fail(check, fmt.Sprintf("consumer group %q missing", c.Group),
+				fmt.Sprintf("start %s (it declares the group), messages are not processed until then", c.Worker))
+		default:
+			r.OK(check, "group "+c.Group)
+		}
+	}
+}
+
+// NodeChecker is the part of lnd.Client the LND check needs.
+type NodeChecker interface {
+	GetInfo(ctx context.Context) (*lnd.NodeInfo, error)
+	MissingPermissions(ctx context.Context, methods []string) ([]string, error)
+}
+
+// CheckLND verifies the node answers, runs on the configured network, is
+// synced, and that the macaroon grants every RPC in methods.
+func CheckLND(ctx context.Context, r *Report, node NodeChecker, network string, methods []string) {
+	info, err := node.GetInfo(ctx)
+	if err != nil {
+		r.Fail("lnd.connection", err.Error(), "check [lnd] grpc_host and port, and that the wallet is unlocked")
+		return
+	}
+	r.OK("lnd.connection", fmt.Sprintf("%s (%s) at height %d", info.Alias, truncate(info.PubKey, 16), info.BlockHeight))
+
+	if err := guardrail.CheckNode(network, info); err != nil {
+		r.Fail("lnd.network", err.Error(), "point [lnd] at a node on the configured network, or fix [lnd] network")
+	} else {
+		r.OK("lnd.network", info.Network)
+	}
+
+	switch {
+	case !info.SyncedToChain:
+		r.Fail("lnd.sync", "not synced to chain", "wait for LND to catch up with the chain backend (lncli getinfo)")
+	case !info.SyncedToGraph:
+		r.Warn("lnd.sync", "not synced to graph", "wait for graph sync, route finding may fail until then")
+	default:
+		r.OK("lnd.sync", "synced to chain and graph")
+	}
+
+	if info.NumChannels == 0 {
+		r.Warn("lnd.channels", "no active channels", "open channels or Lightning redemptions will fail")
+	}
+
+	missing, err := node.MissingPermissions(ctx, methods)
+	switch {
+	case err != nil:
+		r.Warn("lnd.macaroon", err.Error(), "could not verify macaroon permissions, LND too old for CheckMacaroonPermissions?")
+	case len(missing) > 0:
+		r.Fail("lnd.macaroon", "not allowed: "+strings.Join(missing, ", "),
+			"use admin.macaroon or bake one covering these RPCs (lncli bakemacaroon --allow_external_permissions ... or lncli listpermissions)")
+	default:
+		r.OK("lnd.macaroon", fmt.Sprintf("grants all %d required RPCs", len(methods)))
+	}
+}
+
+// CheckPrices fetches the BTC price in currency from every provider. The
+// configured provider failing is fatal, the others only matter as fallbacks.
+func CheckPrices(ctx context.Context, r *Report, providers map[string]exchange.PriceProvider, configured, currency string) {
+	if _, ok := providers[configured]; !ok {
+		r.Fail("price."+configured, "unknown provider", `set [quotes] provider to "coinbase", "coingecko" or "bitstamp"`)
+	}
+
+	for _, name := range sortedKeys(providers) {
+		check := "price." + name
+		price, err := providers[name].GetPrice(ctx, currency)
+		switch {
+		case err == nil && price > 0:
+			r.OK(check, fmt.Sprintf("%.2f %s", price, currency))
+		case name == configured:
+			r.Fail(check, describe(err, price), "check outbound HTTPS access, or switch [quotes] provider")
+		default:
+			r.Warn(check, describe(err, price), "check outbound HTTPS access to the provider")
+		}
+	}
+}
+
+func describe(err error, price float64) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("invalid price %v", price)
+}
+
+func sortedKeys(m map[string]exchange.PriceProvider) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,147 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"btc-giftcard/internal/exchange"
+	"btc-giftcard/internal/lnd"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDB struct {
+	pingErr error
+	version uint
+	dirty   bool
+	legacy  []string
+}
+
+func (f *fakeDB) Ping(ctx context.Context) error { return f.pingErr }
+func (f *fakeDB) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return f.version, f.dirty, nil
+}
+func (f *fakeDB) LegacyCardColumns(ctx context.Context) ([]string, error) { return f.legacy, nil }
+
+type fakeGroups map[string]bool
+
+func (f fakeGroups) GroupExists(ctx context.Context, stream, group string) (bool, error) {
+	return f[stream+"/"+group], nil
+}
+
+type fakeNode struct {
+	info    *lnd.NodeInfo
+	missing []string
+}
+
+func (f *fakeNode) GetInfo(ctx context.Context) (*lnd.NodeInfo, error) { return f.info, nil }
+func (f *fakeNode) MissingPermissions(ctx context.Context, methods []string) ([]string, error) {
+	return f.missing, nil
+}
+
+type fakePrice struct {
+	price float64
+	err   error
+}
+
+func (f fakePrice) GetPrice(ctx context.Context, currency string) (float64, error) {
+	return f.price, f.err
+}
+
+func statuses(r *Report) map[string]Status {
+	out := map[string]Status{}
+	for _, res := range r.Results {
+		out[res.Check] = res.Status
+	}
+	return out
+}
+
+func TestCheckDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	var r Report
+	CheckDatabase(ctx, &r, &fakeDB{version: 12}, 12)
+	assert.False(t, r.Failed())
+
+	r = Report{}
+	CheckDatabase(ctx, &r, &fakeDB{version: 11}, 12)
+	assert.Equal(t, StatusFail, statuses(&r)["database.schema"], "behind")
+
+	r = Report{}
+	CheckDatabase(ctx, &r, &fakeDB{version: 12, dirty: true}, 12)
+	assert.Equal(t, StatusFail, statuses(&r)["database.schema"], "dirty")
+
+	r = Report{}
+	CheckDatabase(ctx, &r, &fakeDB{version: 17, legacy: []string{"wallet_address"}}, 17)
+	assert.Equal(t, StatusWarn, statuses(&r)["database.legacy_columns"], "migrated past 000017 with legacy columns left")
+
+	r = Report{}
+	CheckDatabase(ctx, &r, &fakeDB{version: 16, legacy: []string{"wallet_address"}}, 17)
+	assert.NotContains(t, statuses(&r), "database.legacy_columns", "expected before 000017 runs")
+
+	r = Report{}
+	CheckDatabase(ctx, &r, &fakeDB{pingErr: errors.New("connection refused")}, 12)
+	assert.Len(t, r.Results, 1, "no schema check without a connection")
+	assert.True(t, r.Failed())
+}
+
+func TestCheckStreams(t *testing.T) {
+	consumers := []Consumer{
+		{Stream: "fund_card", Group: "fund_workers", Worker: "cmd/worker/fund_card"},
+		{Stream: "other", Group: "other_workers", Worker: "cmd/worker/other"},
+	}
+
+	var r Report
+	CheckStreams(context.Background(), &r, fakeGroups{"fund_card/fund_workers": true}, consumers)
+
+	assert.Equal(t, map[string]Status{
+		"redis.stream.fund_card": StatusOK,
+		"redis.stream.other":     StatusFail,
+	}, statuses(&r))
+	assert.Contains(t, r.Results[1].Remedy, "cmd/worker/other")
+}
+
+func TestCheckLND(t *testing.T) {
+	var r Report
+	CheckLND(context.Background(), &r, &fakeNode{
+		info:    &lnd.NodeInfo{Alias: "node", Network: "mainnet", SyncedToChain: true, SyncedToGraph: true, NumChannels: 3},
+		missing: []string{"/lnrpc.Lightning/SendCoins"},
+	}, "testnet", lnd.RequiredRPCs)
+
+	assert.Equal(t, map[string]Status{
+		"lnd.connection": StatusOK,
+		"lnd.network":    StatusFail,
+		"lnd.sync":       StatusOK,
+		"lnd.macaroon":   StatusFail,
+	}, statuses(&r))
+}
+
+func TestCheckPrices(t *testing.T) {
+	providers := map[string]exchange.PriceProvider{
+		"coinbase":  fakePrice{price: 60_000},
+		"coingecko": fakePrice{err: errors.New("rate limited")},
+	}
+
+	var r Report
+	CheckPrices(context.Background(), &r, providers, "coinbase", "EUR")
+	assert.Equal(t, StatusWarn, statuses(&r)["price.coingecko"], "fallback failing is a warning")
+	assert.False(t, r.Failed())
+
+	r = Report{}
+	CheckPrices(context.Background(), &r, providers, "coingecko", "EUR")
+	assert.Equal(t, StatusFail, statuses(&r)["price.coingecko"], "configured provider failing is fatal")
+}
+
+func TestReport_Write(t *testing.T) {
+	var r Report
+	r.OK("database.connection", "reachable")
+	r.Fail("database.schema", "version 11, expected 12", "run migrations")
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Write(&buf))
+	assert.Contains(t, buf.String(), "FAIL")
+	assert.Contains(t, buf.String(), "→ run migrations")
+	assert.Contains(t, buf.String(), "1 ok, 0 warnings, 1 failures")
+}
@@ -0,0 +1,161 @@
+// Package featureflag gates risky features so they can ship dark and be
+// enabled gradually.
+//
+// Each flag has a Rule: a default from config.toml ([features.<name>]) that
+// can be overridden at runtime in Redis (key "feature:<name>", JSON-encoded
+// Rule) without a deploy:
+//
+//	go run ./cmd/admin flags set instant_refunds -enabled -percentage 10
+//
+// A flag is on for a subject when the rule is enabled AND either the
+// subject's merchant is allow-listed or the subject falls inside the
+// percentage rollout. Unknown flags are off.
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	"btc-giftcard/config"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Flag names a gated feature.
+type Flag string
+
+// Risky features that must be rolled out gradually. Only define a flag
+// together with the code path that checks it.
+const (
+	InstantRefunds Flag = "instant_refunds" // Refund remaining balance without manual review (card.RefundCard)
+)
+
+// Known lists every defined flag (for admin listing).
+var Known = []Flag{InstantRefunds}
+
+const overrideKeyPrefix = "feature:"
+
+// Rule decides who a flag is enabled for.
+type Rule struct {
+	Enabled    bool     `json:"enabled"`    // Kill switch: false disables the flag for everyone
+	Percentage int      `json:"percentage"` // 0-100: share of subjects enabled (stable per subject key)
+	Merchants  []string `json:"merchants"`  // Merchants always enabled (while Enabled)
+}
+
+// Subject is who a flag is evaluated for.
+type Subject struct {
+	MerchantID string // Merchant the request belongs to ("" if none)
+	Key        string // Stable rollout key (e.g., card ID) for percentage bucketing
+}
+
+// Flags evaluates feature flags against config defaults and Redis overrides.
+// A nil *Flags reports every flag as disabled.
+type Flags struct {
+	defaults map[Flag]Rule
+}
+
+// New creates a Flags with the given default rules.
+func New(defaults map[Flag]Rule) *Flags {
+	return &Flags{defaults: defaults}
+}
+
+// FromConfig creates a Flags from the config.toml [features] section.
+func FromConfig(features map[string]config.FeatureFlag) *Flags {
+	defaults := make(map[Flag]Rule, len(features))
+	for name, f := range features {
+		defaults[Flag(name)] = Rule{
+			Enabled:    f.Enabled,
+			Percentage: f.Percentage,
+			Merchants:  f.Merchants,
+		}
+	}
+	return New(defaults)
+}
+
+// Enabled reports whether flag is on for subject.
+// Redis errors fall back to the config default (logged, never returned).
+func (f *Flags) Enabled(ctx context.Context, flag Flag, subject Subject) bool {
+	if f == nil {
+		return false
+	}
+	rule, err := f.Rule(ctx, flag)
+	if err != nil {
+		logger.Warn("feature flag override lookup failed, using default",
+			zap.String("flag", string(flag)),
+			zap.Error(err),
+		)
+		rule = f.defaults[flag]
+	}
+	return rule.Allows(flag, subject)
+}
+
+// Rule returns the effective rule for flag: the Redis override if one is set,
+// otherwise the config default.
+func (f *Flags) Rule(ctx context.Context, flag Flag) (Rule, error) {
+	rule := f.defaults[flag]
+	if cache.Client == nil {
+		return rule, nil
+	}
+
+	raw, err := cache.Get(ctx, overrideKeyPrefix+string(flag))
+	if err != nil {
+		return rule, err
+	}
+	if raw == "" {
+		return rule, nil
+	}
+
+	var override Rule
+	if err := json.Unmarshal([]byte(raw), &override); err != nil {
+		return rule, fmt.Errorf("invalid override for flag %s: %w", flag, err)
+	}
+	return override, nil
+}
+
+// SetOverride stores a runtime override for flag in Redis (no expiry).
+func SetOverride(ctx context.Context, flag Flag, rule Rule) error {
+	if rule.Percentage < 0 || rule.Percentage > 100 {
+		return fmt.Errorf("percentage must be between 0 and 100, got %d", rule.Percentage)
+	}
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rule: %w", err)
+	}
+	return cache.Set(ctx, overrideKeyPrefix+string(flag), data, 0)
+}
+
+// ClearOverride removes the Redis override so flag reverts to its config default.
+func ClearOverride(ctx context.Context, flag Flag) error {
+	_, err := cache.Delete(ctx, overrideKeyPrefix+string(flag))
+	return err
+}
+
+// Allows reports whether the rule enables flag for subject.
+func (r Rule) Allows(flag Flag, subject Subject) bool {
+	if !r.Enabled {
+		return false
+	}
+	if subject.MerchantID != "" && slices.Contains(r.Merchants, subject.MerchantID) {
+		return true
+	}
+	if r.Percentage >= 100 {
+		return true
+	}
+	if r.Percentage <= 0 || subject.Key == "" {
+		return false
+	}
+	return bucket(flag, subject.Key) < r.Percentage
+}
+
+// bucket maps (flag, key) to a stable value in [0, 100). Hashing the flag name
+// too means the same subjects are not always first in every rollout.
+func bucket(flag Flag, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(string(flag) + ":" + key))
+	return int(h.Sum32() % 100)
+}
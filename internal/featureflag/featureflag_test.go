@@ -0,0 +1,63 @@
+package featureflag
+
+import (
+	"context"
+	"testing"
+
+	"btc-giftcard/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		subject  Subject
+		expected bool
+	}{
+		{"Disabled", Rule{Enabled: false, Percentage: 100}, Subject{Key: "card-1"}, false},
+		{"Fully rolled out", Rule{Enabled: true, Percentage: 100}, Subject{Key: "card-1"}, true},
+		{"Enabled with 0%", Rule{Enabled: true}, Subject{Key: "card-1"}, false},
+		{"Allow-listed merchant", Rule{Enabled: true, Merchants: []string{"m1"}}, Subject{MerchantID: "m1"}, true},
+		{"Other merchant", Rule{Enabled: true, Merchants: []string{"m1"}}, Subject{MerchantID: "m2"}, false},
+		{"Allow-list ignored when disabled", Rule{Enabled: false, Merchants: []string{"m1"}}, Subject{MerchantID: "m1"}, false},
+		{"Partial rollout without key", Rule{Enabled: true, Percentage: 50}, Subject{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.rule.Allows(InstantRefunds, tt.subject))
+		})
+	}
+}
+
+func TestRule_Allows_PercentageIsStable(t *testing.T) {
+	rule := Rule{Enabled: true, Percentage: 30}
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		subject := Subject{Key: string(rune('a'+i%26)) + string(rune(i))}
+		first := rule.Allows(InstantRefunds, subject)
+		assert.Equal(t, first, rule.Allows(InstantRefunds, subject), "same subject must get the same answer")
+		if first {
+			enabled++
+		}
+	}
+
+	// Roughly 30% of subjects (loose bounds, hash distribution)
+	assert.InDelta(t, 300, enabled, 80)
+}
+
+func TestFlags_Enabled_Defaults(t *testing.T) {
+	flags := FromConfig(map[string]config.FeatureFlag{
+		"instant_refunds": {Enabled: true, Percentage: 100},
+	})
+
+	ctx := context.Background()
+	assert.True(t, flags.Enabled(ctx, InstantRefunds, Subject{Key: "card-1"}))
+	assert.False(t, flags.Enabled(ctx, Flag("not_defined"), Subject{Key: "card-1"}), "unknown flags are off")
+
+	var nilFlags *Flags
+	assert.False(t, nilFlags.Enabled(ctx, InstantRefunds, Subject{Key: "card-1"}))
+}
@@ -0,0 +1,77 @@
+// Package guardrail refuses to start a service with a configuration that
+// could lose real money: a config/LND network mismatch, or mainnet without an
+// explicit opt-in and the minimum production setup. The API and every worker
+// (internal/worker) run these checks at startup, giftcardctl doctor reports
+// them.
+package guardrail
+
+import (
+	"errors"
+	"fmt"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/lnd"
+	"btc-giftcard/internal/wallet"
+)
+
+var (
+	// ErrNetworkMismatch is returned when LND runs on another network than configured
+	ErrNetworkMismatch = errors.New("LND network does not match config")
+
+	// ErrMainnetNotAllowed is returned for mainnet without [guardrails] allow_mainnet
+	ErrMainnetNotAllowed = errors.New("mainnet requires [guardrails] allow_mainnet = true")
+
+	// ErrMainnetConfig is returned when mainnet is allowed but the minimum production config is missing
+	ErrMainnetConfig = errors.New("incomplete mainnet config")
+
+	// ErrUnsupportedNetwork is returned for a network LND knows but this service doesn't
+	ErrUnsupportedNetwork = errors.New("unsupported [lnd] network")
+)
+
+// networks are the [lnd] network values, named as LND reports them (GetInfo chains).
+var networks = map[string]bool{"mainnet": true, "testnet": true, "regtest": true}
+
+// unsupportedNetworks are networks LND runs on that this service can't serve:
+// on-chain addresses and wallets (internal/wallet) only know mainnet and
+// testnet3 parameters, so signet or testnet4 funds would be mishandled.
+var unsupportedNetworks = map[string]bool{"signet": true, "testnet4": true}
+
+// Validate checks the configuration alone: a known network and, on mainnet,
+// the explicit opt-in, an alerting destination and a cold storage address.
+// All problems are returned at once (errors.Join).
+func Validate(cfg config.ApiConfig) error {
+	network := cfg.LND.Network
+	if unsupportedNetworks[network] {
+		return fmt.Errorf("%w %q: only mainnet, testnet (testnet3) and regtest are supported", ErrUnsupportedNetwork, network)
+	}
+	if !networks[network] {
+		return fmt.Errorf("unknown [lnd] network %q (mainnet, testnet or regtest)", network)
+	}
+	if network != "mainnet" {
+		return nil
+	}
+
+	if !cfg.Guardrails.AllowMainnet {
+		return ErrMainnetNotAllowed
+	}
+
+	var errs []error
+	if cfg.Guardrails.AlertWebhookURL == "" {
+		errs = append(errs, fmt.Errorf("%w: [guardrails] alert_webhook_url is not set", ErrMainnetConfig))
+	}
+	if addr := cfg.Guardrails.ColdStorageAddress; addr == "" {
+		errs = append(errs, fmt.Errorf("%w: [guardrails] cold_storage_address is not set", ErrMainnetConfig))
+	} else if ok, _ := wallet.ValidateAddress(addr, "mainnet"); !ok {
+		errs = append(errs, fmt.Errorf("%w: [guardrails] cold_storage_address is not a mainnet address", ErrMainnetConfig))
+	}
+	return errors.Join(errs...)
+}
+
+// CheckNode refuses an LND node running on another network than configured,
+// e.g. a testnet deployment pointed at a mainnet node.
+func CheckNode(network string, info *lnd.NodeInfo) error {
+	if info.Network != network {
+		return fmt.Errorf("%w: config says %q, LND %s reports %q", ErrNetworkMismatch, network, info.Alias, info.Network)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package guardrail
+
+import (
+	"testing"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/lnd"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// Initialize logger for tests
+	_ = logger.Init("development")
+}
+
+func mainnetConfig() config.ApiConfig {
+	var cfg config.ApiConfig
+	cfg.LND.Network = "mainnet"
+	cfg.Guardrails.AllowMainnet = true
+	cfg.Guardrails.AlertWebhookURL = "https://alerts.example.com/hook"
+	cfg.Guardrails.ColdStorageAddress = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	var cfg config.ApiConfig
+	cfg.LND.Network = "testnet"
+	assert.NoError(t, Validate(cfg), "testnet needs no opt-in")
+
+	cfg.LND.Network = "testnett"
+	assert.Error(t, Validate(cfg))
+
+	for _, network := range []string{"signet", "testnet4"} {
+		cfg.LND.Network = network
+		assert.ErrorIs(t, Validate(cfg), ErrUnsupportedNetwork, network)
+	}
+
+	assert.NoError(t, Validate(mainnetConfig()))
+
+	cfg = mainnetConfig()
+	cfg.Guardrails.AllowMainnet = false
+	assert.ErrorIs(t, Validate(cfg), ErrMainnetNotAllowed)
+
+	cfg = mainnetConfig()
+	cfg.Guardrails.AlertWebhookURL = ""
+	cfg.Guardrails.ColdStorageAddress = "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"
+	err := Validate(cfg)
+	assert.ErrorIs(t, err, ErrMainnetConfig)
+	assert.Contains(t, err.Error(), "alert_webhook_url")
+	assert.Contains(t, err.Error(), "cold_storage_address is not a mainnet address")
+}
+
+func TestCheckNode(t *testing.T) {
+	assert.NoError(t, CheckNode("testnet", &lnd.NodeInfo{Network: "testnet"}))
+	assert.ErrorIs(t, CheckNode("testnet", &lnd.NodeInfo{Alias: "prod", Network: "mainnet"}), ErrNetworkMismatch)
+}
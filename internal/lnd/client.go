@@ -39,6 +39,7 @@
 //	internal/lnd/
 //	├── client.go         ← THIS FILE: interface + Config + constructor
 //	├── lightning.go       ← Lightning payment methods (SendPayment, DecodeInvoice)
+//	├── invoices.go        ← Incoming payments (AddInvoice, SubscribeInvoices)
 //	├── onchain.go         ← On-chain methods (SendCoins, NewAddress, WalletBalance)
 //	└── treasury.go        ← Treasury balance aggregation (channel + on-chain)
 package lnd
@@ -48,6 +49,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
@@ -68,6 +70,16 @@ type Config struct {
 	Network               string // "mainnet", "testnet", "regtest"
 	PaymentTimeoutSeconds int    // Max time for Lightning payment settlement (default: 30)
 	MaxPaymentFeeSats     int64  // Max routing fee in sats (default: 100)
+
+	// Multi-path payment tuning (SendPaymentV2). Zero values use LND defaults.
+	// The final CLTV delta is taken from the invoice — LND rejects overriding
+	// it when paying a payment request — so CltvLimit is the timelock knob.
+	MaxParts         uint32 // Max HTLC shards per payment (LND default: 16, 1 disables MPP)
+	MaxShardSizeSats int64  // Largest single shard in sats (0 = no limit)
+	AllowAMP         bool   // Pay AMP invoices (atomic multi-path)
+	CltvLimit        int32  // Max total timelock in blocks (0 = LND default)
+
+	ProbeTimeoutSeconds uint32 // Max time for a route probe (EstimateRouteFee, default: 10)
 }
 
 // ============================================================================
@@ -96,6 +108,32 @@ type LightningClient interface {
 	//   - Validate: invoice not expired, amount > 0, correct network
 	DecodeInvoice(ctx context.Context, bolt11 string) (*Invoice, error)
 
+	// TrackPayment waits for a payment sent earlier (e.g. one PayInvoice gave up
+	// on while HTLCs were still in flight) to succeed or fail.
+	//   - Call routerrpc.Router.TrackPaymentV2() with the payment hash
+	//   - Return ErrPaymentNotFound if the payment was never sent
+	TrackPayment(ctx context.Context, paymentHash string) (*PaymentResult, error)
+
+	// EstimateRouteFee probes the route to a BOLT11 invoice's destination
+	// without settling a payment. Used as a pre-flight check for large redemptions.
+	//   - Call routerrpc.Router.EstimateRouteFee() with the payment request
+	//   - Return the routing fee and whether a route with enough liquidity exists
+	EstimateRouteFee(ctx context.Context, bolt11 string) (*RouteFeeEstimate, error)
+
+	// ---- Incoming payments ----
+
+	// AddInvoice creates an invoice for receiving amountSats (treasury deposits).
+	//   - Call lnrpc.Lightning.AddInvoice() with memo and expiry
+	//   - Return the payment hash, BOLT11 payment request and add index
+	AddInvoice(ctx context.Context, amountSats int64, memo string, expirySeconds int64) (*CreatedInvoice, error)
+
+	// SubscribeSettledInvoices calls handle for every invoice settled after
+	// settleIndex, blocking until ctx is cancelled or the stream fails.
+	// Used by the invoice settlement worker to credit deposits.
+	//   - Call lnrpc.Lightning.SubscribeInvoices() with settle_index
+	//   - Skip non-settled updates
+	SubscribeSettledInvoices(ctx context.Context, settleIndex uint64, handle func(*SettledInvoice) error) error
+
 	// ---- On-chain transactions ----
 
 	// SendOnChain sends BTC from the LND wallet to a destination address.
@@ -164,6 +202,38 @@ type Invoice struct {
 	IsExpired   bool   // true if invoice has expired
 }
 
+// CreatedInvoice is an invoice we issued to receive a payment.
+type CreatedInvoice struct {
+	PaymentHash    string // Hex-encoded payment hash
+	PaymentRequest string // BOLT11 invoice to hand to the payer
+	AddIndex       uint64 // LND's invoice add index
+}
+
+// SettledInvoice is an incoming invoice that has been paid.
+type SettledInvoice struct {
+	PaymentHash    string // Hex-encoded payment hash
+	PaymentRequest string // BOLT11 invoice
+	Memo           string // Memo set at creation (carries the deposit correlation ID)
+	AmountPaidSats int64  // Amount actually received (may exceed the invoice value)
+	SettleIndex    uint64 // Monotonic settlement index (resume point for subscriptions)
+	SettledAt      time.Time
+}
+
+// RouteFeeEstimate is the result of a route probe.
+// FailureReason is empty when the probe reached the destination.
+type RouteFeeEstimate struct {
+	RoutingFeeSats int64  // Lower bound of the routing fee (rounded up to whole sats)
+	TimeLockDelay  int64  // Worst-case timelock delay in blocks
+	FailureReason  string // e.g. "FAILURE_REASON_NO_ROUTE" (empty on success)
+}
+
+// NoLiquidity reports whether the probe failed because no route could carry
+// the amount (no route, or not enough local balance).
+func (e *RouteFeeEstimate) NoLiquidity() bool {
+	return e.FailureReason == lnrpc.PaymentFailureReason_FAILURE_REASON_NO_ROUTE.String() ||
+		e.FailureReason == lnrpc.PaymentFailureReason_FAILURE_REASON_INSUFFICIENT_BALANCE.String()
+}
+
 type OnChainResult struct {
 	TxHash string // Hex-encoded transaction hash (64 chars)
 }
@@ -186,6 +256,7 @@ type NodeInfo struct {
 	SyncedToGraph bool
 	BlockHeight   uint32
 	NumChannels   uint32
+	Network       string // "mainnet", "testnet", "regtest", ...
 }
 
 // ============================================================================
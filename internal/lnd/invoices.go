@@ -0,0 +1,79 @@
+package lnd
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+)
+
+// AddInvoice creates a BOLT11 invoice for receiving amountSats on our node.
+// Used for treasury deposits (e.g., B2B top-ups); memo carries the correlation
+// ID the invoice settlement worker matches on.
+func (c *Client) AddInvoice(ctx context.Context, amountSats int64, memo string, expirySeconds int64) (*CreatedInvoice, error) {
+	resp, err := c.lnClient.AddInvoice(ctx, &lnrpc.Invoice{
+		Memo:   memo,
+		Value:  amountSats,
+		Expiry: expirySeconds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add invoice: %w", err)
+	}
+
+	return &CreatedInvoice{
+		PaymentHash:    hex.EncodeToString(resp.RHash),
+		PaymentRequest: resp.PaymentRequest,
+		AddIndex:       resp.AddIndex,
+	}, nil
+}
+
+// SubscribeSettledInvoices streams invoices settled after settleIndex and
+// calls handle for each, in settlement order. LND replays every settlement
+// with a higher index first, so passing the last processed index on restart
+// means no settlement is missed.
+//
+// Blocks until ctx is cancelled, the stream fails, or handle returns an error.
+// Non-settled updates (open, accepted, canceled) are skipped.
+func (c *Client) SubscribeSettledInvoices(ctx context.Context, settleIndex uint64, handle func(*SettledInvoice) error) error {
+	stream, err := c.lnClient.SubscribeInvoices(ctx, &lnrpc.InvoiceSubscription{SettleIndex: settleIndex})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to invoices: %w", err)
+	}
+
+	for {
+		inv, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("invoice subscription closed by LND")
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("invoice subscription failed: %w", err)
+		}
+
+		if inv.State != lnrpc.Invoice_SETTLED {
+			continue
+		}
+
+		if err := handle(toSettledInvoice(inv)); err != nil {
+			return err
+		}
+	}
+}
+
+// toSettledInvoice converts a settled lnrpc.Invoice.
+func toSettledInvoice(inv *lnrpc.Invoice) *SettledInvoice {
+	return &SettledInvoice{
+		PaymentHash:    hex.EncodeToString(inv.RHash),
+		PaymentRequest: inv.PaymentRequest,
+		Memo:           inv.Memo,
+		AmountPaidSats: inv.AmtPaidSat,
+		SettleIndex:    inv.SettleIndex,
+		SettledAt:      time.Unix(inv.SettleDate, 0).UTC(),
+	}
+}
@@ -0,0 +1,149 @@
+package lnd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ============================================================================
+// Mocks — stub the lnrpc.LightningClient methods used by invoices.go
+// ============================================================================
+
+type mockInvoiceLNClient struct {
+	lnrpc.LightningClient // embed for interface compliance
+
+	addInvoiceFn        func(ctx context.Context, in *lnrpc.Invoice, opts ...grpc.CallOption) (*lnrpc.AddInvoiceResponse, error)
+	subscribeInvoicesFn func(ctx context.Context, in *lnrpc.InvoiceSubscription, opts ...grpc.CallOption) (lnrpc.Lightning_SubscribeInvoicesClient, error)
+}
+
+func (m *mockInvoiceLNClient) AddInvoice(ctx context.Context, in *lnrpc.Invoice, opts ...grpc.CallOption) (*lnrpc.AddInvoiceResponse, error) {
+	return m.addInvoiceFn(ctx, in, opts...)
+}
+
+func (m *mockInvoiceLNClient) SubscribeInvoices(ctx context.Context, in *lnrpc.InvoiceSubscription, opts ...grpc.CallOption) (lnrpc.Lightning_SubscribeInvoicesClient, error) {
+	return m.subscribeInvoicesFn(ctx, in, opts...)
+}
+
+// mockInvoiceStream implements lnrpc.Lightning_SubscribeInvoicesClient.
+type mockInvoiceStream struct {
+	grpc.ClientStream
+	invoices []*lnrpc.Invoice
+	idx      int
+}
+
+func (s *mockInvoiceStream) Recv() (*lnrpc.Invoice, error) {
+	if s.idx >= len(s.invoices) {
+		return nil, io.EOF
+	}
+	inv := s.invoices[s.idx]
+	s.idx++
+	return inv, nil
+}
+
+func (s *mockInvoiceStream) Header() (metadata.MD, error) { return nil, nil }
+func (s *mockInvoiceStream) Trailer() metadata.MD         { return nil }
+func (s *mockInvoiceStream) CloseSend() error             { return nil }
+func (s *mockInvoiceStream) Context() context.Context     { return context.Background() }
+func (s *mockInvoiceStream) SendMsg(m interface{}) error  { return nil }
+func (s *mockInvoiceStream) RecvMsg(m interface{}) error  { return nil }
+
+// ============================================================================
+// AddInvoice tests
+// ============================================================================
+
+func TestAddInvoice_Success(t *testing.T) {
+	mock := &mockInvoiceLNClient{
+		addInvoiceFn: func(_ context.Context, in *lnrpc.Invoice, _ ...grpc.CallOption) (*lnrpc.AddInvoiceResponse, error) {
+			assert.Equal(t, int64(250000), in.Value)
+			assert.Equal(t, "deposit:abc", in.Memo)
+			assert.Equal(t, int64(3600), in.Expiry)
+			return &lnrpc.AddInvoiceResponse{
+				RHash:          []byte{0xab, 0xcd},
+				PaymentRequest: "lntb2500u1...",
+				AddIndex:       7,
+			}, nil
+		},
+	}
+	client := &Client{lnClient: mock}
+
+	inv, err := client.AddInvoice(context.Background(), 250000, "deposit:abc", 3600)
+	require.NoError(t, err)
+	assert.Equal(t, "abcd", inv.PaymentHash)
+	assert.Equal(t, "lntb2500u1...", inv.PaymentRequest)
+	assert.Equal(t, uint64(7), inv.AddIndex)
+}
+
+func TestAddInvoice_RPCError(t *testing.T) {
+	mock := &mockInvoiceLNClient{
+		addInvoiceFn: func(_ context.Context, _ *lnrpc.Invoice, _ ...grpc.CallOption) (*lnrpc.AddInvoiceResponse, error) {
+			return nil, errors.New("wallet locked")
+		},
+	}
+	client := &Client{lnClient: mock}
+
+	_, err := client.AddInvoice(context.Background(), 1000, "", 3600)
+	assert.ErrorContains(t, err, "failed to add invoice")
+}
+
+// ============================================================================
+// SubscribeSettledInvoices tests
+// ============================================================================
+
+func TestSubscribeSettledInvoices_SkipsUnsettled(t *testing.T) {
+	mock := &mockInvoiceLNClient{
+		subscribeInvoicesFn: func(_ context.Context, in *lnrpc.InvoiceSubscription, _ ...grpc.CallOption) (lnrpc.Lightning_SubscribeInvoicesClient, error) {
+			assert.Equal(t, uint64(41), in.SettleIndex)
+			return &mockInvoiceStream{invoices: []*lnrpc.Invoice{
+				{RHash: []byte{0x01}, State: lnrpc.Invoice_OPEN},
+				{RHash: []byte{0x02}, Memo: "deposit:x", AmtPaidSat: 5000, SettleIndex: 42, SettleDate: 1700000000, State: lnrpc.Invoice_SETTLED},
+				{RHash: []byte{0x03}, State: lnrpc.Invoice_CANCELED},
+			}}, nil
+		},
+	}
+	client := &Client{lnClient: mock}
+
+	var settled []*SettledInvoice
+	err := client.SubscribeSettledInvoices(context.Background(), 41, func(inv *SettledInvoice) error {
+		settled = append(settled, inv)
+		return nil
+	})
+
+	// The mock stream ends with EOF, which is reported as a closed subscription
+	assert.ErrorContains(t, err, "closed by LND")
+	require.Len(t, settled, 1)
+	assert.Equal(t, "02", settled[0].PaymentHash)
+	assert.Equal(t, "deposit:x", settled[0].Memo)
+	assert.Equal(t, int64(5000), settled[0].AmountPaidSats)
+	assert.Equal(t, uint64(42), settled[0].SettleIndex)
+	assert.Equal(t, int64(1700000000), settled[0].SettledAt.Unix())
+}
+
+func TestSubscribeSettledInvoices_HandlerError(t *testing.T) {
+	mock := &mockInvoiceLNClient{
+		subscribeInvoicesFn: func(_ context.Context, _ *lnrpc.InvoiceSubscription, _ ...grpc.CallOption) (lnrpc.Lightning_SubscribeInvoicesClient, error) {
+			return &mockInvoiceStream{invoices: []*lnrpc.Invoice{
+				{RHash: []byte{0x01}, SettleIndex: 1, State: lnrpc.Invoice_SETTLED},
+				{RHash: []byte{0x02}, SettleIndex: 2, State: lnrpc.Invoice_SETTLED},
+			}}, nil
+		},
+	}
+	client := &Client{lnClient: mock}
+
+	handlerErr := errors.New("database down")
+	calls := 0
+	err := client.SubscribeSettledInvoices(context.Background(), 0, func(*SettledInvoice) error {
+		calls++
+		return handlerErr
+	})
+
+	assert.ErrorIs(t, err, handlerErr)
+	assert.Equal(t, 1, calls, "stops at the first failed invoice so it's replayed on resubscribe")
+}
@@ -2,14 +2,24 @@ package lnd
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
 
+	"btc-giftcard/pkg/logger"
+
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ErrPaymentNotFound is returned by TrackPayment when LND has no payment for
+// the hash, i.e. it was never sent.
+var ErrPaymentNotFound = errors.New("payment not found")
+
 // PayInvoice pays a BOLT11 invoice using the Router sub-server's SendPaymentV2
 // streaming RPC. It validates the invoice first, then sends the payment and
 // waits for a terminal state (SUCCEEDED or FAILED).
@@ -28,9 +38,13 @@ func (c *Client) PayInvoice(ctx context.Context, bolt11 string, maxFeeSats int64
 	}
 
 	req := &routerrpc.SendPaymentRequest{
-		PaymentRequest: bolt11,
-		TimeoutSeconds: int32(c.Cfg.PaymentTimeoutSeconds),
-		FeeLimitSat:    maxFeeSats,
+		PaymentRequest:   bolt11,
+		TimeoutSeconds:   int32(c.Cfg.PaymentTimeoutSeconds),
+		FeeLimitSat:      maxFeeSats,
+		MaxParts:         c.Cfg.MaxParts,
+		MaxShardSizeMsat: uint64(c.Cfg.MaxShardSizeSats) * 1000,
+		Amp:              c.Cfg.AllowAMP,
+		CltvLimit:        c.Cfg.CltvLimit,
 	}
 
 	payCtx, cancel := context.WithTimeout(ctx, time.Duration(c.Cfg.PaymentTimeoutSeconds)*time.Second)
@@ -42,12 +56,15 @@ func (c *Client) PayInvoice(ctx context.Context, bolt11 string, maxFeeSats int64
 	}
 
 	// Read payment status updates from the stream until we reach a terminal state.
+	loggedAttempts := make(map[uint64]bool)
 	for {
 		payment, err := stream.Recv()
 		if err != nil {
 			return nil, fmt.Errorf("payment stream error: %w", err)
 		}
 
+		logHTLCAttempts(payment, loggedAttempts)
+
 		switch payment.Status {
 		case lnrpc.Payment_SUCCEEDED:
 			return &PaymentResult{
@@ -73,6 +90,126 @@ func (c *Client) PayInvoice(ctx context.Context, bolt11 string, maxFeeSats int64
 	}
 }
 
+// TrackPayment waits for a previously sent payment to reach a terminal state
+// using the Router sub-server's TrackPaymentV2 streaming RPC. A FAILED payment
+// is returned with Status Failed and no error. Returns ErrPaymentNotFound if
+// LND never sent a payment for the hash.
+func (c *Client) TrackPayment(ctx context.Context, paymentHash string) (*PaymentResult, error) {
+	hash, err := hex.DecodeString(paymentHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment hash: %w", err)
+	}
+
+	stream, err := c.routerClient.TrackPaymentV2(ctx, &routerrpc.TrackPaymentRequest{
+		PaymentHash:       hash,
+		NoInflightUpdates: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to track payment: %w", err)
+	}
+
+	for {
+		payment, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil, ErrPaymentNotFound
+			}
+			return nil, fmt.Errorf("payment stream error: %w", err)
+		}
+
+		switch payment.Status {
+		case lnrpc.Payment_SUCCEEDED:
+			return &PaymentResult{
+				PaymentHash:     payment.PaymentHash,
+				PaymentPreimage: payment.PaymentPreimage,
+				FeeSats:         payment.FeeSat,
+				Status:          Succeeded,
+			}, nil
+
+		case lnrpc.Payment_FAILED:
+			return &PaymentResult{
+				PaymentHash: payment.PaymentHash,
+				Status:      Failed,
+			}, nil
+
+		case lnrpc.Payment_IN_FLIGHT, lnrpc.Payment_INITIATED:
+			continue
+
+		default:
+			return nil, fmt.Errorf("unexpected payment status: %s", payment.Status)
+		}
+	}
+}
+
+// logHTLCAttempts logs each HTLC attempt once it resolves (succeeded or failed),
+// so failed multi-path payments show which shards failed, where, and why.
+// logged tracks attempt IDs already reported across stream updates.
+func logHTLCAttempts(payment *lnrpc.Payment, logged map[uint64]bool) {
+	for _, htlc := range payment.Htlcs {
+		if htlc.Status == lnrpc.HTLCAttempt_IN_FLIGHT || logged[htlc.AttemptId] {
+			continue
+		}
+		logged[htlc.AttemptId] = true
+
+		fields := []zap.Field{
+			zap.String("payment_hash", payment.PaymentHash),
+			zap.Uint64("attempt_id", htlc.AttemptId),
+			zap.String("status", htlc.Status.String()),
+		}
+		if htlc.Route != nil {
+			fields = append(fields,
+				zap.Int64("amount_msat", htlc.Route.TotalAmtMsat),
+				zap.Int64("fee_msat", htlc.Route.TotalFeesMsat),
+				zap.Int("hops", len(htlc.Route.Hops)),
+			)
+		}
+
+		if htlc.Status == lnrpc.HTLCAttempt_FAILED {
+			if htlc.Failure != nil {
+				fields = append(fields,
+					zap.String("failure_code", htlc.Failure.Code.String()),
+					zap.Uint32("failure_source_index", htlc.Failure.FailureSourceIndex),
+				)
+			}
+			logger.Warn("Lightning HTLC attempt failed", fields...)
+			continue
+		}
+		logger.Info("Lightning HTLC attempt settled", fields...)
+	}
+}
+
+// defaultProbeTimeoutSeconds bounds a route probe when ProbeTimeoutSeconds is unset.
+const defaultProbeTimeoutSeconds = 10
+
+// EstimateRouteFee sends probe payments toward the invoice's destination (via
+// the Router sub-server) to estimate the routing fee without settling anything.
+// A failed probe is not an error: the reason is returned in FailureReason so
+// callers can tell "no route/liquidity" apart from RPC failures.
+func (c *Client) EstimateRouteFee(ctx context.Context, bolt11 string) (*RouteFeeEstimate, error) {
+	timeout := c.Cfg.ProbeTimeoutSeconds
+	if timeout == 0 {
+		timeout = defaultProbeTimeoutSeconds
+	}
+
+	resp, err := c.routerClient.EstimateRouteFee(ctx, &routerrpc.RouteFeeRequest{
+		PaymentRequest: bolt11,
+		Timeout:        timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate route fee: %w", err)
+	}
+
+	estimate := &RouteFeeEstimate{
+		RoutingFeeSats: (resp.RoutingFeeMsat + 999) / 1000,
+		TimeLockDelay:  resp.TimeLockDelay,
+	}
+	if resp.FailureReason != lnrpc.PaymentFailureReason_FAILURE_REASON_NONE {
+		estimate.FailureReason = resp.FailureReason.String()
+	}
+
+	return estimate, nil
+}
+
 // DecodeInvoice decodes a BOLT11 invoice string without paying it.
 // Used to validate invoice amount, expiry, and network before payment.
 func (c *Client) DecodeInvoice(ctx context.Context, bolt11 string) (*Invoice, error) {
@@ -7,14 +7,23 @@ import (
 	"testing"
 	"time"
 
+	"btc-giftcard/pkg/logger"
+
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+func init() {
+	// Initialize logger for tests (PayInvoice logs HTLC attempts)
+	_ = logger.Init("development")
+}
+
 // ============================================================================
 // Mocks
 // ============================================================================
@@ -35,22 +44,37 @@ func (m *mockLightningClient) DecodePayReq(ctx context.Context, in *lnrpc.PayReq
 type mockRouterClient struct {
 	routerrpc.RouterClient
 
-	sendPaymentV2Fn func(ctx context.Context, in *routerrpc.SendPaymentRequest, opts ...grpc.CallOption) (routerrpc.Router_SendPaymentV2Client, error)
+	sendPaymentV2Fn    func(ctx context.Context, in *routerrpc.SendPaymentRequest, opts ...grpc.CallOption) (routerrpc.Router_SendPaymentV2Client, error)
+	estimateRouteFeeFn func(ctx context.Context, in *routerrpc.RouteFeeRequest, opts ...grpc.CallOption) (*routerrpc.RouteFeeResponse, error)
+	trackPaymentV2Fn   func(ctx context.Context, in *routerrpc.TrackPaymentRequest, opts ...grpc.CallOption) (routerrpc.Router_TrackPaymentV2Client, error)
 }
 
 func (m *mockRouterClient) SendPaymentV2(ctx context.Context, in *routerrpc.SendPaymentRequest, opts ...grpc.CallOption) (routerrpc.Router_SendPaymentV2Client, error) {
 	return m.sendPaymentV2Fn(ctx, in, opts...)
 }
 
-// mockPaymentStream implements routerrpc.Router_SendPaymentV2Client.
+func (m *mockRouterClient) EstimateRouteFee(ctx context.Context, in *routerrpc.RouteFeeRequest, opts ...grpc.CallOption) (*routerrpc.RouteFeeResponse, error) {
+	return m.estimateRouteFeeFn(ctx, in, opts...)
+}
+
+func (m *mockRouterClient) TrackPaymentV2(ctx context.Context, in *routerrpc.TrackPaymentRequest, opts ...grpc.CallOption) (routerrpc.Router_TrackPaymentV2Client, error) {
+	return m.trackPaymentV2Fn(ctx, in, opts...)
+}
+
+// mockPaymentStream implements routerrpc.Router_SendPaymentV2Client and
+// Router_TrackPaymentV2Client. err (default io.EOF) ends the stream.
 type mockPaymentStream struct {
 	grpc.ClientStream
 	payments []*lnrpc.Payment
 	idx      int
+	err      error
 }
 
 func (s *mockPaymentStream) Recv() (*lnrpc.Payment, error) {
 	if s.idx >= len(s.payments) {
+		if s.err != nil {
+			return nil, s.err
+		}
 		return nil, io.EOF
 	}
 	p := s.payments[s.idx]
@@ -423,3 +447,215 @@ func TestPayInvoice_RequestFieldsPassedCorrectly(t *testing.T) {
 	assert.Equal(t, int32(45), capturedReq.TimeoutSeconds)
 	assert.Equal(t, int64(250), capturedReq.FeeLimitSat)
 }
+
+func TestPayInvoice_MPPFieldsFromConfig(t *testing.T) {
+	var capturedReq *routerrpc.SendPaymentRequest
+
+	mockLN := &mockLightningClient{
+		decodePayReqFn: func(_ context.Context, _ *lnrpc.PayReqString, _ ...grpc.CallOption) (*lnrpc.PayReq, error) {
+			return &lnrpc.PayReq{
+				NumSatoshis: 2_000_000,
+				Expiry:      3600,
+				Timestamp:   time.Now().Unix(),
+			}, nil
+		},
+	}
+
+	mockRouter := &mockRouterClient{
+		sendPaymentV2Fn: func(_ context.Context, in *routerrpc.SendPaymentRequest, _ ...grpc.CallOption) (routerrpc.Router_SendPaymentV2Client, error) {
+			capturedReq = in
+			return &mockPaymentStream{
+				payments: []*lnrpc.Payment{
+					{Status: lnrpc.Payment_SUCCEEDED, PaymentHash: "h1", PaymentPreimage: "p1"},
+				},
+			}, nil
+		},
+	}
+
+	client := newTestClient(mockLN, mockRouter)
+	client.Cfg.MaxParts = 8
+	client.Cfg.MaxShardSizeSats = 500_000
+	client.Cfg.AllowAMP = true
+	client.Cfg.CltvLimit = 1008
+
+	_, err := client.PayInvoice(context.Background(), "lntb20m1bolt11here", 500)
+	require.NoError(t, err)
+
+	require.NotNil(t, capturedReq)
+	assert.Equal(t, uint32(8), capturedReq.MaxParts)
+	assert.Equal(t, uint64(500_000_000), capturedReq.MaxShardSizeMsat)
+	assert.True(t, capturedReq.Amp)
+	assert.Equal(t, int32(1008), capturedReq.CltvLimit)
+	assert.Zero(t, capturedReq.FinalCltvDelta, "final_cltv_delta must not be set with a payment request")
+}
+
+func TestPayInvoice_FailedWithHTLCAttempts(t *testing.T) {
+	mockLN := &mockLightningClient{
+		decodePayReqFn: func(_ context.Context, _ *lnrpc.PayReqString, _ ...grpc.CallOption) (*lnrpc.PayReq, error) {
+			return &lnrpc.PayReq{
+				NumSatoshis: 2_000_000,
+				Expiry:      3600,
+				Timestamp:   time.Now().Unix(),
+			}, nil
+		},
+	}
+
+	failedAttempt := &lnrpc.HTLCAttempt{
+		AttemptId: 1,
+		Status:    lnrpc.HTLCAttempt_FAILED,
+		Route:     &lnrpc.Route{TotalAmtMsat: 1_000_000_000, Hops: []*lnrpc.Hop{{}, {}}},
+		Failure: &lnrpc.Failure{
+			Code:               lnrpc.Failure_TEMPORARY_CHANNEL_FAILURE,
+			FailureSourceIndex: 1,
+		},
+	}
+
+	mockRouter := &mockRouterClient{
+		sendPaymentV2Fn: func(_ context.Context, _ *routerrpc.SendPaymentRequest, _ ...grpc.CallOption) (routerrpc.Router_SendPaymentV2Client, error) {
+			return &mockPaymentStream{
+				payments: []*lnrpc.Payment{
+					{Status: lnrpc.Payment_IN_FLIGHT, PaymentHash: "h1", Htlcs: []*lnrpc.HTLCAttempt{failedAttempt}},
+					{
+						Status:        lnrpc.Payment_FAILED,
+						PaymentHash:   "h1",
+						FailureReason: lnrpc.PaymentFailureReason_FAILURE_REASON_NO_ROUTE,
+						Htlcs:         []*lnrpc.HTLCAttempt{failedAttempt},
+					},
+				},
+			}, nil
+		},
+	}
+
+	client := newTestClient(mockLN, mockRouter)
+
+	result, err := client.PayInvoice(context.Background(), "lntb20m1bolt11here", 500)
+	require.Error(t, err)
+	assert.Equal(t, Failed, result.Status)
+}
+
+// ============================================================================
+// TrackPayment tests
+// ============================================================================
+
+func TestTrackPayment_WaitsForTerminalState(t *testing.T) {
+	mockRouter := &mockRouterClient{
+		trackPaymentV2Fn: func(_ context.Context, in *routerrpc.TrackPaymentRequest, _ ...grpc.CallOption) (routerrpc.Router_TrackPaymentV2Client, error) {
+			assert.Equal(t, []byte{0xab, 0xcd}, in.PaymentHash)
+			return &mockPaymentStream{
+				payments: []*lnrpc.Payment{
+					{Status: lnrpc.Payment_IN_FLIGHT, PaymentHash: "abcd"},
+					{Status: lnrpc.Payment_SUCCEEDED, PaymentHash: "abcd", PaymentPreimage: "preimage1", FeeSat: 3},
+				},
+			}, nil
+		},
+	}
+
+	result, err := newTestClient(nil, mockRouter).TrackPayment(context.Background(), "abcd")
+	require.NoError(t, err)
+	assert.Equal(t, Succeeded, result.Status)
+	assert.Equal(t, "preimage1", result.PaymentPreimage)
+}
+
+func TestTrackPayment_FailedIsNotAnError(t *testing.T) {
+	mockRouter := &mockRouterClient{
+		trackPaymentV2Fn: func(_ context.Context, _ *routerrpc.TrackPaymentRequest, _ ...grpc.CallOption) (routerrpc.Router_TrackPaymentV2Client, error) {
+			return &mockPaymentStream{payments: []*lnrpc.Payment{{Status: lnrpc.Payment_FAILED, PaymentHash: "abcd"}}}, nil
+		},
+	}
+
+	result, err := newTestClient(nil, mockRouter).TrackPayment(context.Background(), "abcd")
+	require.NoError(t, err)
+	assert.Equal(t, Failed, result.Status)
+}
+
+func TestTrackPayment_NotFound(t *testing.T) {
+	mockRouter := &mockRouterClient{
+		trackPaymentV2Fn: func(_ context.Context, _ *routerrpc.TrackPaymentRequest, _ ...grpc.CallOption) (routerrpc.Router_TrackPaymentV2Client, error) {
+			return &mockPaymentStream{err: status.Error(codes.NotFound, "payment isn't initiated")}, nil
+		},
+	}
+
+	_, err := newTestClient(nil, mockRouter).TrackPayment(context.Background(), "abcd")
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+}
+
+func TestLogHTLCAttempts_LogsEachAttemptOnce(t *testing.T) {
+	logged := make(map[uint64]bool)
+	payment := &lnrpc.Payment{
+		PaymentHash: "h1",
+		Htlcs: []*lnrpc.HTLCAttempt{
+			{AttemptId: 1, Status: lnrpc.HTLCAttempt_FAILED},
+			{AttemptId: 2, Status: lnrpc.HTLCAttempt_IN_FLIGHT},
+			{AttemptId: 3, Status: lnrpc.HTLCAttempt_SUCCEEDED},
+		},
+	}
+
+	logHTLCAttempts(payment, logged)
+	logHTLCAttempts(payment, logged)
+
+	assert.True(t, logged[1])
+	assert.False(t, logged[2], "in-flight attempts are logged once resolved")
+	assert.True(t, logged[3])
+	assert.Len(t, logged, 2)
+}
+
+// ============================================================================
+// EstimateRouteFee tests
+// ============================================================================
+
+func TestEstimateRouteFee_Success(t *testing.T) {
+	mockRouter := &mockRouterClient{
+		estimateRouteFeeFn: func(_ context.Context, in *routerrpc.RouteFeeRequest, _ ...grpc.CallOption) (*routerrpc.RouteFeeResponse, error) {
+			assert.Equal(t, "lntb1...", in.PaymentRequest)
+			assert.Equal(t, uint32(defaultProbeTimeoutSeconds), in.Timeout, "default timeout when not configured")
+			return &routerrpc.RouteFeeResponse{
+				RoutingFeeMsat: 2001,
+				TimeLockDelay:  144,
+				FailureReason:  lnrpc.PaymentFailureReason_FAILURE_REASON_NONE,
+			}, nil
+		},
+	}
+
+	client := newTestClient(nil, mockRouter)
+	estimate, err := client.EstimateRouteFee(context.Background(), "lntb1...")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), estimate.RoutingFeeSats, "msat should round up to whole sats")
+	assert.Equal(t, int64(144), estimate.TimeLockDelay)
+	assert.Empty(t, estimate.FailureReason)
+	assert.False(t, estimate.NoLiquidity())
+}
+
+func TestEstimateRouteFee_NoRoute(t *testing.T) {
+	mockRouter := &mockRouterClient{
+		estimateRouteFeeFn: func(_ context.Context, in *routerrpc.RouteFeeRequest, _ ...grpc.CallOption) (*routerrpc.RouteFeeResponse, error) {
+			assert.Equal(t, uint32(3), in.Timeout)
+			return &routerrpc.RouteFeeResponse{
+				FailureReason: lnrpc.PaymentFailureReason_FAILURE_REASON_NO_ROUTE,
+			}, nil
+		},
+	}
+
+	client := newTestClient(nil, mockRouter)
+	client.Cfg.ProbeTimeoutSeconds = 3
+	estimate, err := client.EstimateRouteFee(context.Background(), "lntb1...")
+
+	require.NoError(t, err)
+	assert.Equal(t, "FAILURE_REASON_NO_ROUTE", estimate.FailureReason)
+	assert.True(t, estimate.NoLiquidity())
+}
+
+func TestEstimateRouteFee_RPCError(t *testing.T) {
+	mockRouter := &mockRouterClient{
+		estimateRouteFeeFn: func(_ context.Context, _ *routerrpc.RouteFeeRequest, _ ...grpc.CallOption) (*routerrpc.RouteFeeResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	client := newTestClient(nil, mockRouter)
+	estimate, err := client.EstimateRouteFee(context.Background(), "lntb1...")
+
+	assert.Nil(t, estimate)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to estimate route fee")
+}
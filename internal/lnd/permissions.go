@@ -0,0 +1,63 @@
+package lnd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+)
+
+// RequiredRPCs are the LND RPCs this codebase calls. A custom-baked macaroon
+// must grant the permissions of all of them.
+var RequiredRPCs = []string{
+	"/lnrpc.Lightning/GetInfo",
+	"/lnrpc.Lightning/DecodePayReq",
+	"/lnrpc.Lightning/AddInvoice",
+	"/lnrpc.Lightning/SubscribeInvoices",
+	"/lnrpc.Lightning/WalletBalance",
+	"/lnrpc.Lightning/ChannelBalance",
+	"/lnrpc.Lightning/NewAddress",
+	"/lnrpc.Lightning/SendCoins",
+	"/routerrpc.Router/SendPaymentV2",
+	"/routerrpc.Router/EstimateRouteFee",
+}
+
+// MissingPermissions returns the methods (full gRPC names, see RequiredRPCs)
+// the configured macaroon is not allowed to call. LND reports the permissions
+// each method needs (ListPermissions) and checks them against the macaroon
+// (CheckMacaroonPermissions), so nothing is actually executed.
+func (c *Client) MissingPermissions(ctx context.Context, methods []string) ([]string, error) {
+	macaroon, err := os.ReadFile(c.Cfg.MacaroonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read macaroon file %s: %w", c.Cfg.MacaroonPath, err)
+	}
+
+	perms, err := c.lnClient.ListPermissions(ctx, &lnrpc.ListPermissionsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list RPC permissions: %w", err)
+	}
+
+	var missing []string
+	for _, method := range methods {
+		required, ok := perms.MethodPermissions[method]
+		if !ok {
+			// Unknown to this LND version (e.g. sub-server not compiled in)
+			missing = append(missing, method)
+			continue
+		}
+
+		resp, err := c.lnClient.CheckMacaroonPermissions(ctx, &lnrpc.CheckMacPermRequest{
+			Macaroon:    macaroon,
+			Permissions: required.Permissions,
+			FullMethod:  method,
+		})
+		if err != nil || !resp.Valid {
+			missing = append(missing, method)
+		}
+	}
+
+	sort.Strings(missing)
+	return missing, nil
+}
@@ -41,6 +41,11 @@ func (c *Client) GetInfo(ctx context.Context) (*NodeInfo, error) {
 		return nil, fmt.Errorf("failed to get node info: %w", err)
 	}
 
+	var network string
+	if len(resp.Chains) > 0 {
+		network = resp.Chains[0].Network
+	}
+
 	return &NodeInfo{
 		Alias:         resp.Alias,
 		PubKey:        resp.IdentityPubkey,
@@ -48,5 +53,6 @@ func (c *Client) GetInfo(ctx context.Context) (*NodeInfo, error) {
 		SyncedToGraph: resp.SyncedToGraph,
 		BlockHeight:   resp.BlockHeight,
 		NumChannels:   resp.NumActiveChannels,
+		Network:       network,
 	}, nil
 }
@@ -0,0 +1,133 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"btc-giftcard/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ProcessorClient is a Provider for card processors exposing the common REST
+// shape: POST {base}/v1/refunds and POST {base}/v1/authorizations/{id}/capture
+// with a bearer API key and an Idempotency-Key header. Charges carry the card
+// ID as metadata, which is how a refund finds the purchase charge.
+type ProcessorClient struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+}
+
+// NewProcessorClient creates a processor client (nil httpClient uses a 15s timeout).
+func NewProcessorClient(baseURL, apiKey string, httpClient *http.Client) *ProcessorClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &ProcessorClient{httpClient: httpClient, baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey}
+}
+
+type processorRefundRequest struct {
+	ChargeMetadata map[string]string `json:"charge_metadata"` // Selects the charge to refund
+	AmountInMinor  int64             `json:"amount_in_minor"`
+	Currency       string            `json:"currency"`
+	Reason         string            `json:"reason,omitempty"`
+}
+
+type processorCaptureRequest struct {
+	AmountInMinor int64             `json:"amount_in_minor"`
+	Currency      string            `json:"currency"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+type processorResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// Refund implements Provider.
+func (c *ProcessorClient) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	out, err := c.post(ctx, "/v1/refunds", req.IdempotencyKey, processorRefundRequest{
+		ChargeMetadata: map[string]string{"card_id": req.CardID},
+		AmountInMinor:  req.AmountCents,
+		Currency:       req.Currency,
+		Reason:         req.Reason,
+	})
+	if err != nil {
+		if errors.Is(err, errDeclined) {
+			logger.Warn("Refund declined by payment provider", zap.String("card_id", req.CardID))
+			return nil, ErrRefundDeclined
+		}
+		return nil, fmt.Errorf("failed to refund: %w", err)
+	}
+
+	status := RefundPending
+	if RefundStatus(out.Status) == RefundSucceeded {
+		status = RefundSucceeded
+	}
+	return &Refund{ID: out.ID, Status: status}, nil
+}
+
+// Capture implements Provider.
+func (c *ProcessorClient) Capture(ctx context.Context, req CaptureRequest) (*Capture, error) {
+	out, err := c.post(ctx, "/v1/authorizations/"+req.AuthorizationID+"/capture", req.IdempotencyKey, processorCaptureRequest{
+		AmountInMinor: req.AmountCents,
+		Currency:      req.Currency,
+		Metadata:      map[string]string{"card_id": req.CardID},
+	})
+	if err != nil {
+		if errors.Is(err, errDeclined) {
+			logger.Warn("Capture declined by payment provider", zap.String("card_id", req.CardID))
+			return nil, ErrCaptureDeclined
+		}
+		return nil, fmt.Errorf("failed to capture: %w", err)
+	}
+	return &Capture{ID: out.ID}, nil
+}
+
+// errDeclined is returned by post when the processor refuses the request
+// (402 or 422), mapped to the operation's declined error by the caller.
+var errDeclined = errors.New("declined")
+
+func (c *ProcessorClient) post(ctx context.Context, path, idempotencyKey string, body any) (*processorResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPaymentRequired || resp.StatusCode == http.StatusUnprocessableEntity:
+		return nil, errDeclined
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated:
+		return nil, fmt.Errorf("processor error: status %d", resp.StatusCode)
+	}
+
+	var out processorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if out.ID == "" {
+		return nil, fmt.Errorf("processor returned no ID")
+	}
+	return &out, nil
+}
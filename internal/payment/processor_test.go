@@ -0,0 +1,92 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"btc-giftcard/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Initialize logger for tests
+	_ = logger.Init("development")
+}
+
+var _ Provider = (*ProcessorClient)(nil)
+
+func TestProcessorClient_Refund(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/refunds", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		assert.Equal(t, "tx-1", r.Header.Get("Idempotency-Key"))
+
+		var body processorRefundRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "card-1", body.ChargeMetadata["card_id"])
+		assert.Equal(t, int64(5_830), body.AmountInMinor)
+		assert.Equal(t, "EUR", body.Currency)
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "re_123", "status": "succeeded"}`))
+	}))
+	defer server.Close()
+
+	refund, err := NewProcessorClient(server.URL+"/", "key", nil).Refund(context.Background(), RefundRequest{
+		CardID: "card-1", AmountCents: 5_830, Currency: "EUR", IdempotencyKey: "tx-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Refund{ID: "re_123", Status: RefundSucceeded}, refund)
+}
+
+func TestProcessorClient_Refund_Declined(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	_, err := NewProcessorClient(server.URL, "key", nil).Refund(context.Background(), RefundRequest{CardID: "card-1"})
+	assert.ErrorIs(t, err, ErrRefundDeclined)
+}
+
+func TestProcessorClient_Refund_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := NewProcessorClient(server.URL, "key", nil).Refund(context.Background(), RefundRequest{CardID: "card-1"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRefundDeclined)
+}
+
+func TestProcessorClient_Capture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/authorizations/auth_9/capture", r.URL.Path)
+		assert.Equal(t, "card-1", r.Header.Get("Idempotency-Key"))
+
+		var body processorCaptureRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "card-1", body.Metadata["card_id"])
+
+		_, _ = w.Write([]byte(`{"id": "ch_1"}`))
+	}))
+	defer server.Close()
+
+	capture, err := NewProcessorClient(server.URL, "key", nil).Capture(context.Background(), CaptureRequest{
+		CardID: "card-1", AuthorizationID: "auth_9", AmountCents: 5_000, Currency: "EUR", IdempotencyKey: "card-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ch_1", capture.ID)
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+	})
+	_, err = NewProcessorClient(server.URL, "key", nil).Capture(context.Background(), CaptureRequest{AuthorizationID: "auth_9"})
+	assert.ErrorIs(t, err, ErrCaptureDeclined)
+}
@@ -0,0 +1,74 @@
+// Package payment abstracts the fiat payment provider cards are purchased with
+// (card processor, bank transfer, ...).
+//
+// card.Service only depends on the Provider interface. A provider locates
+// the original purchase charge from the card ID, which is attached to the
+// charge as metadata at checkout, so refunds always go back to the payment
+// method that bought the card. ProcessorClient implements it over a card
+// processor's REST API ([payments] in config.toml).
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrRefundDeclined is returned when the provider rejects a refund
+	// (e.g., charge too old, disputed, or already fully refunded).
+	ErrRefundDeclined = errors.New("refund declined by payment provider")
+
+	// ErrCaptureDeclined is returned when the provider rejects a capture
+	// (e.g., authorization expired, voided, or for a different amount).
+	ErrCaptureDeclined = errors.New("capture declined by payment provider")
+)
+
+// Provider is a fiat payment provider.
+type Provider interface {
+	// Refund returns AmountCents to the payment method that purchased the card.
+	// Providers must treat IdempotencyKey as a unique request ID, so retrying
+	// the same refund never pays out twice.
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+
+	// Capture collects a payment authorized at a retail point of sale and
+	// attaches the card ID to the charge, so later refunds find it like an
+	// online purchase. IdempotencyKey has the same guarantee as for Refund.
+	Capture(ctx context.Context, req CaptureRequest) (*Capture, error)
+}
+
+// RefundRequest is a refund to the card's original payment method.
+type RefundRequest struct {
+	CardID         string // Card whose purchase charge is refunded
+	AmountCents    int64  // Amount to refund, in the currency's minor unit
+	Currency       string // ISO 4217 code (must match the purchase currency)
+	IdempotencyKey string // Unique per refund attempt (the refund transaction ID)
+	Reason         string // Free-text reason shown in the provider dashboard
+}
+
+// RefundStatus is the provider-side state of a refund.
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"   // Accepted, funds not yet returned
+	RefundSucceeded RefundStatus = "succeeded" // Funds returned
+)
+
+// Refund is an accepted refund.
+type Refund struct {
+	ID     string // Provider refund ID
+	Status RefundStatus
+}
+
+// CaptureRequest captures a POS authorization for a retail card activation.
+type CaptureRequest struct {
+	CardID          string // Card being activated (attached to the charge as metadata)
+	AuthorizationID string // Authorization returned by the POS terminal
+	AmountCents     int64  // Amount to capture, in the currency's minor unit
+	Currency        string // ISO 4217 code
+	IdempotencyKey  string // Unique per activation (the card ID)
+}
+
+// Capture is a captured charge.
+type Capture struct {
+	ID string // Provider charge ID
+}
@@ -0,0 +1,60 @@
+package payout
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sepaIBANLengths is the IBAN length of every SEPA country (EPC list).
+// Payouts to IBANs from other countries are refused.
+var sepaIBANLengths = map[string]int{
+	"AD": 24, "AT": 20, "BE": 16, "BG": 22, "CH": 21, "CY": 28, "CZ": 24,
+	"DE": 22, "DK": 18, "EE": 20, "ES": 24, "FI": 18, "FR": 27, "GB": 22,
+	"GI": 23, "GR": 27, "HR": 21, "HU": 28, "IE": 22, "IS": 26, "IT": 27,
+	"LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MT": 31, "NL": 18,
+	"NO": 15, "PL": 28, "PT": 25, "RO": 24, "SE": 24, "SI": 19, "SK": 24,
+	"SM": 27, "VA": 22,
+}
+
+// NormalizeIBAN removes spaces, upper-cases and validates an IBAN: SEPA
+// country, country-specific length and the ISO 7064 mod-97 check digits.
+func NormalizeIBAN(iban string) (string, error) {
+	iban = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(iban), " ", ""))
+
+	if len(iban) < 5 {
+		return "", ErrInvalidIBAN
+	}
+	want, ok := sepaIBANLengths[iban[:2]]
+	if !ok {
+		return "", fmt.Errorf("%w: country %q is not in SEPA", ErrInvalidIBAN, iban[:2])
+	}
+	if len(iban) != want {
+		return "", fmt.Errorf("%w: %s IBANs have %d characters", ErrInvalidIBAN, iban[:2], want)
+	}
+
+	// Move the country code and check digits to the end, letters become 10..35
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		default:
+			return "", fmt.Errorf("%w: unexpected character %q", ErrInvalidIBAN, r)
+		}
+	}
+	if remainder != 1 {
+		return "", fmt.Errorf("%w: check digits do not match", ErrInvalidIBAN)
+	}
+
+	return iban, nil
+}
+
+// MaskIBAN keeps the country code and last four characters for logs.
+func MaskIBAN(iban string) string {
+	if len(iban) <= 6 {
+		return "****"
+	}
+	return iban[:2] + strings.Repeat("*", len(iban)-6) + iban[len(iban)-4:]
+}
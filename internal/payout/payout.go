@@ -0,0 +1,67 @@
+// Package payout abstracts the Open Banking payment service provider (PSP)
+// that pays card redemptions out in fiat over SEPA Instant.
+//
+// card.Service only depends on the Provider interface. A payout is
+// asynchronous: Send only means the PSP accepted it, the final outcome arrives
+// as a webhook (see ParseWebhook) referencing the transaction ID passed as
+// Request.Reference.
+package payout
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrPayoutRejected is returned when the PSP refuses a payout outright
+	// (e.g., beneficiary bank not reachable over SEPA Instant).
+	ErrPayoutRejected = errors.New("payout rejected by provider")
+
+	// ErrInvalidIBAN is returned for a malformed IBAN or one outside SEPA.
+	ErrInvalidIBAN = errors.New("invalid IBAN")
+
+	// ErrInvalidWebhook is returned for a webhook with a missing or wrong signature.
+	ErrInvalidWebhook = errors.New("invalid payout webhook")
+)
+
+// Provider is an Open Banking PSP able to send SEPA Instant credit transfers.
+type Provider interface {
+	// Send pays AmountCents to the beneficiary account. Providers must treat
+	// IdempotencyKey as a unique request ID, so retrying the same payout
+	// never pays twice.
+	Send(ctx context.Context, req Request) (*Payout, error)
+}
+
+// Request is a SEPA Instant payout.
+type Request struct {
+	IBAN           string // Beneficiary IBAN (normalized, see NormalizeIBAN)
+	AccountHolder  string // Beneficiary name, checked by the receiving bank
+	AmountCents    int64  // Amount in euro cents
+	Currency       string // Always "EUR" for SEPA
+	Reference      string // Our transaction ID, echoed in webhooks
+	IdempotencyKey string // Unique per payout attempt (the transaction ID)
+	Description    string // Remittance information shown on the bank statement
+}
+
+// Status is the provider-side state of a payout.
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // Accepted, not yet settled
+	StatusSucceeded Status = "succeeded" // Credited to the beneficiary
+	StatusFailed    Status = "failed"    // Returned or rejected after acceptance
+)
+
+// Payout is an accepted payout.
+type Payout struct {
+	ID     string // Provider payout ID
+	Status Status
+}
+
+// Event is a payout status webhook.
+type Event struct {
+	PayoutID      string `json:"payout_id"`
+	Reference     string `json:"reference"` // Request.Reference of the payout
+	Status        Status `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
@@ -0,0 +1,98 @@
+package payout
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"btc-giftcard/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Initialize logger for tests
+	_ = logger.Init("development")
+}
+
+func TestNormalizeIBAN(t *testing.T) {
+	iban, err := NormalizeIBAN(" de89 3704 0044 0532 0130 00 ")
+	require.NoError(t, err)
+	assert.Equal(t, "DE89370400440532013000", iban)
+
+	_, err = NormalizeIBAN("FR1420041010050500013M02606")
+	assert.NoError(t, err, "letters in the BBAN")
+
+	for _, bad := range []string{
+		"DE89370400440532013001", // wrong check digits
+		"DE8937040044053201300",  // too short for DE
+		"US12345678901234567890", // outside SEPA
+		"DE89-3704-0044-0532-0130-00",
+		"",
+	} {
+		_, err := NormalizeIBAN(bad)
+		assert.ErrorIs(t, err, ErrInvalidIBAN, bad)
+	}
+}
+
+func TestMaskIBAN(t *testing.T) {
+	assert.Equal(t, "DE****************3000", MaskIBAN("DE89370400440532013000"))
+}
+
+func TestPSPClient_Send(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/payouts", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		assert.Equal(t, "tx-1", r.Header.Get("Idempotency-Key"))
+
+		var body pspPayoutRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, int64(4_250), body.AmountInMinor)
+		assert.Equal(t, "sepa_credit_transfer_instant", body.Scheme)
+		assert.Equal(t, "DE89370400440532013000", body.Beneficiary.IBAN)
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"id": "po_123", "status": "executing"}`))
+	}))
+	defer server.Close()
+
+	client := NewPSPClient(server.URL+"/", "key", nil)
+	payout, err := client.Send(context.Background(), Request{
+		IBAN: "DE89370400440532013000", AccountHolder: "Jane Doe",
+		AmountCents: 4_250, Currency: "EUR", Reference: "tx-1", IdempotencyKey: "tx-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Payout{ID: "po_123", Status: StatusPending}, payout)
+}
+
+func TestPSPClient_Send_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	_, err := NewPSPClient(server.URL, "key", nil).Send(context.Background(), Request{IBAN: "DE89370400440532013000"})
+	assert.ErrorIs(t, err, ErrPayoutRejected)
+}
+
+func TestParseWebhook(t *testing.T) {
+	body := []byte(`{"payout_id": "po_123", "reference": "tx-1", "status": "failed", "failure_reason": "account_closed"}`)
+	mac := hmac.New(sha256.New, []byte("whsec"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	event, err := ParseWebhook("whsec", body, signature)
+	require.NoError(t, err)
+	assert.Equal(t, &Event{PayoutID: "po_123", Reference: "tx-1", Status: StatusFailed, FailureReason: "account_closed"}, event)
+
+	_, err = ParseWebhook("other", body, signature)
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	_, err = ParseWebhook("whsec", body, "zz")
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+}
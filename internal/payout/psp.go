@@ -0,0 +1,137 @@
+package payout
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"btc-giftcard/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// WebhookSignatureHeader carries hex(HMAC-SHA256(webhook secret, body)).
+const WebhookSignatureHeader = "X-Webhook-Signature"
+
+// PSPClient is a Provider for PSPs exposing the common Open Banking payout
+// REST shape: POST {base}/v1/payouts with a bearer API key and an
+// Idempotency-Key header, and HMAC-signed status webhooks.
+type PSPClient struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+}
+
+// NewPSPClient creates a PSP client (nil httpClient uses a 15s timeout).
+func NewPSPClient(baseURL, apiKey string, httpClient *http.Client) *PSPClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &PSPClient{httpClient: httpClient, baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey}
+}
+
+type pspPayoutRequest struct {
+	AmountInMinor int64  `json:"amount_in_minor"`
+	Currency      string `json:"currency"`
+	Scheme        string `json:"scheme"`
+	Reference     string `json:"reference"`
+	Description   string `json:"description,omitempty"`
+	Beneficiary   struct {
+		IBAN              string `json:"iban"`
+		AccountHolderName string `json:"account_holder_name"`
+	} `json:"beneficiary"`
+}
+
+type pspPayoutResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// Send implements Provider.
+func (c *PSPClient) Send(ctx context.Context, req Request) (*Payout, error) {
+	body := pspPayoutRequest{
+		AmountInMinor: req.AmountCents,
+		Currency:      req.Currency,
+		Scheme:        "sepa_credit_transfer_instant",
+		Reference:     req.Reference,
+		Description:   req.Description,
+	}
+	body.Beneficiary.IBAN = req.IBAN
+	body.Beneficiary.AccountHolderName = req.AccountHolder
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payout request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/payouts", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	httpReq.Header.Set("Idempotency-Key", req.IdempotencyKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send payout: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnprocessableEntity:
+		logger.Warn("Payout rejected by PSP", zap.String("reference", req.Reference), zap.String("iban", MaskIBAN(req.IBAN)))
+		return nil, ErrPayoutRejected
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted:
+		return nil, fmt.Errorf("PSP error: status %d", resp.StatusCode)
+	}
+
+	var out pspPayoutResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse payout response: %w", err)
+	}
+	if out.ID == "" {
+		return nil, fmt.Errorf("PSP returned a payout without an ID")
+	}
+
+	status := Status(out.Status)
+	if status != StatusSucceeded && status != StatusFailed {
+		status = StatusPending
+	}
+	return &Payout{ID: out.ID, Status: status}, nil
+}
+
+// ParseWebhook verifies a payout webhook's signature (WebhookSignatureHeader)
+// and decodes it. Returns ErrInvalidWebhook if the signature doesn't match.
+func ParseWebhook(secret string, body []byte, signature string) (*Event, error) {
+	given, err := hex.DecodeString(signature)
+	if err != nil || secret == "" {
+		return nil, ErrInvalidWebhook
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(given, mac.Sum(nil)) {
+		return nil, ErrInvalidWebhook
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	if event.Reference == "" || event.PayoutID == "" {
+		return nil, fmt.Errorf("%w: missing payout ID or reference", ErrInvalidWebhook)
+	}
+	switch event.Status {
+	case StatusPending, StatusSucceeded, StatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidWebhook, event.Status)
+	}
+	return &event, nil
+}
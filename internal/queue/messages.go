@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // FundCardMessage represents a request to fund a gift card with BTC
@@ -12,6 +13,7 @@ type FundCardMessage struct {
 	CardID          string `json:"card_id"`
 	FiatAmountCents int64  `json:"fiat_amount_cents"`
 	FiatCurrency    string `json:"fiat_currency"`
+	QuoteID         string `json:"quote_id,omitempty"` // Checkout rate quote to honor, if still valid
 }
 
 // ToJSON serializes the FundCardMessage to JSON bytes.
@@ -107,3 +109,159 @@ func (m *MonitorTransactionMessage) Validate() error {
 	}
 	return nil
 }
+
+// FundingProgressMessage notifies the purchaser of a card's funding progress.
+// Published to the "card_notifications" stream after every funding tranche.
+type FundingProgressMessage struct {
+	CardID        string `json:"card_id"`
+	PurchaseEmail string `json:"purchase_email"`
+	FundedSats    int64  `json:"funded_sats"`
+	TargetSats    int64  `json:"target_sats"`
+	Complete      bool   `json:"complete"` // true when the card is fully funded and spendable
+}
+
+// ToJSON serializes the FundingProgressMessage to JSON bytes.
+func (m *FundingProgressMessage) ToJSON() ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal funding progress message: %w", err)
+	}
+	return data, nil
+}
+
+// FromJSONFundingProgress deserializes JSON bytes into a FundingProgressMessage and validates it.
+func FromJSONFundingProgress(data []byte) (*FundingProgressMessage, error) {
+	msg := &FundingProgressMessage{}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal funding progress message: %w", err)
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// Validate checks if the FundingProgressMessage has all required fields with valid values.
+func (m *FundingProgressMessage) Validate() error {
+	if m.CardID == "" {
+		return errors.New("card_id is required")
+	}
+	if m.PurchaseEmail == "" {
+		return errors.New("purchase_email is required")
+	}
+	if m.TargetSats <= 0 {
+		return errors.New("target_sats must be greater than 0")
+	}
+	if m.FundedSats < 0 || m.FundedSats > m.TargetSats {
+		return fmt.Errorf("funded_sats must be between 0 and target_sats (got %d of %d)", m.FundedSats, m.TargetSats)
+	}
+	if m.Complete != (m.FundedSats == m.TargetSats) {
+		return errors.New("complete must be true exactly when funded_sats equals target_sats")
+	}
+	return nil
+}
+
+// SupportRedemptionMessage emails a card owner the link confirming a
+// support-initiated redemption. Published to the "card_notifications" stream.
+type SupportRedemptionMessage struct {
+	CardID        string    `json:"card_id"`
+	OwnerEmail    string    `json:"owner_email"`
+	Token         string    `json:"token"` // Signed, single-use link token
+	Method        string    `json:"method"`
+	MaxAmountSats int64     `json:"max_amount_sats"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// ToJSON serializes the SupportRedemptionMessage to JSON bytes.
+func (m *SupportRedemptionMessage) ToJSON() ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal support redemption message: %w", err)
+	}
+	return data, nil
+}
+
+// FromJSONSupportRedemption deserializes JSON bytes into a SupportRedemptionMessage and validates it.
+func FromJSONSupportRedemption(data []byte) (*SupportRedemptionMessage, error) {
+	msg := &SupportRedemptionMessage{}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal support redemption message: %w", err)
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// Validate checks if the SupportRedemptionMessage has all required fields with valid values.
+func (m *SupportRedemptionMessage) Validate() error {
+	if m.CardID == "" {
+		return errors.New("card_id is required")
+	}
+	if m.OwnerEmail == "" {
+		return errors.New("owner_email is required")
+	}
+	if m.Token == "" {
+		return errors.New("token is required")
+	}
+	if m.MaxAmountSats <= 0 {
+		return errors.New("max_amount_sats must be greater than 0")
+	}
+	if m.ExpiresAt.IsZero() {
+		return errors.New("expires_at is required")
+	}
+	return nil
+}
+
+// CodeRotatedMessage emails a card owner the new code that replaced a code
+// suspected to be exposed. Published to the "card_notifications" stream.
+type CodeRotatedMessage struct {
+	CardID     string    `json:"card_id"`
+	OwnerEmail string    `json:"owner_email"`
+	Code       string    `json:"code"` // The new code; the old one no longer redeems
+	RotatedAt  time.Time `json:"rotated_at"`
+}
+
+// ToJSON serializes the CodeRotatedMessage to JSON bytes.
+func (m *CodeRotatedMessage) ToJSON() ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal code rotated message: %w", err)
+	}
+	return data, nil
+}
+
+// FromJSONCodeRotated deserializes JSON bytes into a CodeRotatedMessage and validates it.
+func FromJSONCodeRotated(data []byte) (*CodeRotatedMessage, error) {
+	msg := &CodeRotatedMessage{}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal code rotated message: %w", err)
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// Validate checks if the CodeRotatedMessage has all required fields with valid values.
+func (m *CodeRotatedMessage) Validate() error {
+	if m.CardID == "" {
+		return errors.New("card_id is required")
+	}
+	if m.OwnerEmail == "" {
+		return errors.New("owner_email is required")
+	}
+	if m.Code == "" {
+		return errors.New("code is required")
+	}
+	if m.RotatedAt.IsZero() {
+		return errors.New("rotated_at is required")
+	}
+	return nil
+}
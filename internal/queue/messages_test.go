@@ -3,6 +3,7 @@ package queue
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -124,6 +125,21 @@ func TestFundCardMessage_RoundTrip(t *testing.T) {
 	assert.Equal(t, original.FiatCurrency, msg.FiatCurrency)
 }
 
+func TestFundCardMessage_QuoteID(t *testing.T) {
+	// quote_id is optional and omitted when empty
+	data, err := (&FundCardMessage{CardID: "card-1", FiatAmountCents: 5000, FiatCurrency: "EUR"}).ToJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "quote_id")
+
+	original := &FundCardMessage{CardID: "card-1", FiatAmountCents: 5000, FiatCurrency: "EUR", QuoteID: "quote-1"}
+	data, err = original.ToJSON()
+	require.NoError(t, err)
+
+	msg, err := FromJSONFundCard(data)
+	require.NoError(t, err)
+	assert.Equal(t, "quote-1", msg.QuoteID)
+}
+
 func TestFundCardMessage_Validate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -476,3 +492,170 @@ func TestMonitorTransactionMessage_Validate(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// FundingProgressMessage Tests
+// =============================================================================
+
+func TestFundingProgressMessage_RoundTrip(t *testing.T) {
+	msg := &FundingProgressMessage{
+		CardID:        "550e8400-e29b-41d4-a716-446655440000",
+		PurchaseEmail: "corp@example.com",
+		FundedSats:    40_000_000,
+		TargetSats:    75_000_000,
+		Complete:      false,
+	}
+
+	data, err := msg.ToJSON()
+	require.NoError(t, err)
+
+	decoded, err := FromJSONFundingProgress(data)
+	require.NoError(t, err)
+	assert.Equal(t, msg, decoded)
+}
+
+func TestFromJSONFundingProgress_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		jsonData    string
+		expectError string
+	}{
+		{
+			name:        "Missing card_id",
+			jsonData:    `{"purchase_email": "a@b.c", "funded_sats": 1, "target_sats": 2}`,
+			expectError: "card_id is required",
+		},
+		{
+			name:        "Missing purchase_email",
+			jsonData:    `{"card_id": "123", "funded_sats": 1, "target_sats": 2}`,
+			expectError: "purchase_email is required",
+		},
+		{
+			name:        "Funded above target",
+			jsonData:    `{"card_id": "123", "purchase_email": "a@b.c", "funded_sats": 3, "target_sats": 2}`,
+			expectError: "funded_sats must be between 0 and target_sats",
+		},
+		{
+			name:        "Complete mismatch",
+			jsonData:    `{"card_id": "123", "purchase_email": "a@b.c", "funded_sats": 1, "target_sats": 2, "complete": true}`,
+			expectError: "complete must be true exactly when",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := FromJSONFundingProgress([]byte(tt.jsonData))
+			assert.Error(t, err)
+			assert.Nil(t, msg)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+// =============================================================================
+// SupportRedemptionMessage Tests
+// =============================================================================
+
+func TestSupportRedemptionMessage_RoundTrip(t *testing.T) {
+	msg := &SupportRedemptionMessage{
+		CardID:        "550e8400-e29b-41d4-a716-446655440000",
+		OwnerEmail:    "owner@example.com",
+		Token:         "eyJpZCI6IjEifQ.c2lnbmF0dXJl",
+		Method:        "onchain",
+		MaxAmountSats: 250_000,
+		ExpiresAt:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	data, err := msg.ToJSON()
+	require.NoError(t, err)
+
+	decoded, err := FromJSONSupportRedemption(data)
+	require.NoError(t, err)
+	assert.Equal(t, msg, decoded)
+}
+
+func TestFromJSONSupportRedemption_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		jsonData    string
+		expectError string
+	}{
+		{
+			name:        "Missing owner_email",
+			jsonData:    `{"card_id": "123", "token": "t", "max_amount_sats": 1, "expires_at": "2026-01-02T03:04:05Z"}`,
+			expectError: "owner_email is required",
+		},
+		{
+			name:        "Missing token",
+			jsonData:    `{"card_id": "123", "owner_email": "a@b.c", "max_amount_sats": 1, "expires_at": "2026-01-02T03:04:05Z"}`,
+			expectError: "token is required",
+		},
+		{
+			name:        "Missing expiry",
+			jsonData:    `{"card_id": "123", "owner_email": "a@b.c", "token": "t", "max_amount_sats": 1}`,
+			expectError: "expires_at is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := FromJSONSupportRedemption([]byte(tt.jsonData))
+			assert.Error(t, err)
+			assert.Nil(t, msg)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+// =============================================================================
+// CodeRotatedMessage Tests
+// =============================================================================
+
+func TestCodeRotatedMessage_RoundTrip(t *testing.T) {
+	msg := &CodeRotatedMessage{
+		CardID:     "550e8400-e29b-41d4-a716-446655440000",
+		OwnerEmail: "owner@example.com",
+		Code:       "GIFT-ABCD-EFGH-JKMN",
+		RotatedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	data, err := msg.ToJSON()
+	require.NoError(t, err)
+
+	decoded, err := FromJSONCodeRotated(data)
+	require.NoError(t, err)
+	assert.Equal(t, msg, decoded)
+}
+
+func TestFromJSONCodeRotated_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		jsonData    string
+		expectError string
+	}{
+		{
+			name:        "Missing owner_email",
+			jsonData:    `{"card_id": "123", "code": "GIFT-A", "rotated_at": "2026-01-02T03:04:05Z"}`,
+			expectError: "owner_email is required",
+		},
+		{
+			name:        "Missing code",
+			jsonData:    `{"card_id": "123", "owner_email": "a@b.c", "rotated_at": "2026-01-02T03:04:05Z"}`,
+			expectError: "code is required",
+		},
+		{
+			name:        "Missing rotated_at",
+			jsonData:    `{"card_id": "123", "owner_email": "a@b.c", "code": "GIFT-A"}`,
+			expectError: "rotated_at is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := FromJSONCodeRotated([]byte(tt.jsonData))
+			assert.Error(t, err)
+			assert.Nil(t, msg)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
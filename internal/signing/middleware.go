@@ -0,0 +1,84 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"btc-giftcard/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// maxBodyBytes bounds how much of the body is read for signature verification.
+const maxBodyBytes = 1 << 20 // 1 MiB
+
+type contextKey struct{}
+
+// Middleware rejects requests that are not signed by a known merchant, or
+// that replay a nonce. The verified merchant ID is available to handlers via
+// MerchantID(r.Context()).
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if len(body) > maxBodyBytes {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		merchantID := r.Header.Get(HeaderMerchantID)
+		err = v.Verify(r.Context(), Request{
+			MerchantID: merchantID,
+			Timestamp:  r.Header.Get(HeaderTimestamp),
+			Nonce:      r.Header.Get(HeaderNonce),
+			Signature:  r.Header.Get(HeaderSignature),
+			Method:     r.Method,
+			Path:       r.URL.RequestURI(),
+			Body:       body,
+		})
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrReplayedNonce) {
+				status = http.StatusConflict
+			} else if !isVerificationError(err) {
+				status = http.StatusServiceUnavailable
+			}
+			logger.Warn("Rejected signed request",
+				zap.String("merchant_id", merchantID),
+				zap.String("path", r.URL.Path),
+				zap.Error(err),
+			)
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, merchantID)))
+	})
+}
+
+// MerchantID returns the authenticated merchant ID set by Middleware ("" if none).
+func MerchantID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// isVerificationError reports whether err is a client error (vs. an
+// infrastructure failure such as Redis being down).
+func isVerificationError(err error) bool {
+	for _, target := range []error{
+		ErrMissingHeaders, ErrUnknownMerchant, ErrStaleTimestamp,
+		ErrInvalidNonce, ErrReplayedNonce, ErrInvalidSignature,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"btc-giftcard/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Initialize logger for tests (middleware logs rejections)
+	_ = logger.Init("development")
+}
+
+// memoryNonces is an in-memory NonceStore for tests.
+type memoryNonces struct {
+	seen map[string]bool
+	err  error
+}
+
+func (m *memoryNonces) Claim(_ context.Context, merchantID, nonce string, _ time.Duration) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+	key := merchantID + ":" + nonce
+	if m.seen[key] {
+		return false, nil
+	}
+	m.seen[key] = true
+	return true, nil
+}
+
+var testNow = time.Unix(1_700_000_000, 0)
+
+func newTestVerifier(nonces NonceStore) *Verifier {
+	v := NewVerifier(StaticSecrets{"merchant-1": "s3cret"}, nonces, 5*time.Minute)
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func signedRequest(ts time.Time, nonce string, body []byte) Request {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	return Request{
+		MerchantID: "merchant-1",
+		Timestamp:  timestamp,
+		Nonce:      nonce,
+		Signature:  hex.EncodeToString(Sign("s3cret", timestamp, nonce, "POST", "/v1/cards/redeem", body)),
+		Method:     "POST",
+		Path:       "/v1/cards/redeem",
+		Body:       body,
+	}
+}
+
+func TestVerify_Valid(t *testing.T) {
+	v := newTestVerifier(&memoryNonces{seen: map[string]bool{}})
+
+	err := v.Verify(context.Background(), signedRequest(testNow, "n-1", []byte(`{"code":"GIFT-1"}`)))
+	assert.NoError(t, err)
+}
+
+func TestVerify_Rejections(t *testing.T) {
+	body := []byte(`{"code":"GIFT-1"}`)
+
+	tests := []struct {
+		name     string
+		mutate   func(r *Request)
+		expected error
+	}{
+		{"Missing nonce", func(r *Request) { r.Nonce = "" }, ErrMissingHeaders},
+		{"Nonce too long", func(r *Request) { r.Nonce = string(bytes.Repeat([]byte("a"), 65)) }, ErrInvalidNonce},
+		{"Unknown merchant", func(r *Request) { r.MerchantID = "merchant-2" }, ErrUnknownMerchant},
+		{"Tampered body", func(r *Request) { r.Body = []byte(`{"code":"GIFT-2"}`) }, ErrInvalidSignature},
+		{"Tampered path", func(r *Request) { r.Path = "/v1/cards/other" }, ErrInvalidSignature},
+		{"Non-hex signature", func(r *Request) { r.Signature = "zz" }, ErrInvalidSignature},
+		{"Bad timestamp", func(r *Request) { r.Timestamp = "yesterday" }, ErrStaleTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(&memoryNonces{seen: map[string]bool{}})
+			req := signedRequest(testNow, "n-1", body)
+			tt.mutate(&req)
+			assert.ErrorIs(t, v.Verify(context.Background(), req), tt.expected)
+		})
+	}
+}
+
+func TestVerify_StaleTimestamp(t *testing.T) {
+	v := newTestVerifier(&memoryNonces{seen: map[string]bool{}})
+
+	assert.ErrorIs(t, v.Verify(context.Background(), signedRequest(testNow.Add(-6*time.Minute), "n-1", nil)), ErrStaleTimestamp)
+	assert.ErrorIs(t, v.Verify(context.Background(), signedRequest(testNow.Add(6*time.Minute), "n-2", nil)), ErrStaleTimestamp)
+	assert.NoError(t, v.Verify(context.Background(), signedRequest(testNow.Add(-4*time.Minute), "n-3", nil)))
+}
+
+func TestVerify_ReplayedNonce(t *testing.T) {
+	nonces := &memoryNonces{seen: map[string]bool{}}
+	v := newTestVerifier(nonces)
+	req := signedRequest(testNow, "n-1", []byte(`{}`))
+
+	require.NoError(t, v.Verify(context.Background(), req))
+	assert.ErrorIs(t, v.Verify(context.Background(), req), ErrReplayedNonce)
+}
+
+func TestVerify_BadSignatureDoesNotClaimNonce(t *testing.T) {
+	nonces := &memoryNonces{seen: map[string]bool{}}
+	v := newTestVerifier(nonces)
+
+	forged := signedRequest(testNow, "n-1", nil)
+	forged.Signature = hex.EncodeToString(Sign("wrong", forged.Timestamp, "n-1", "POST", forged.Path, nil))
+	assert.ErrorIs(t, v.Verify(context.Background(), forged), ErrInvalidSignature)
+	assert.Empty(t, nonces.seen)
+}
+
+func TestMiddleware(t *testing.T) {
+	nonces := &memoryNonces{seen: map[string]bool{}}
+	v := newTestVerifier(nonces)
+
+	var gotMerchant string
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMerchant = MerchantID(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	body := []byte(`{"code":"GIFT-1"}`)
+	newRequest := func() *http.Request {
+		signed := signedRequest(testNow, "n-1", body)
+		r := httptest.NewRequest(http.MethodPost, "/v1/cards/redeem", bytes.NewReader(body))
+		r.Header.Set(HeaderMerchantID, signed.MerchantID)
+		r.Header.Set(HeaderTimestamp, signed.Timestamp)
+		r.Header.Set(HeaderNonce, signed.Nonce)
+		r.Header.Set(HeaderSignature, signed.Signature)
+		return r
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "merchant-1", gotMerchant)
+
+	// Replay of the captured request
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest())
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Unsigned request
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/cards/redeem", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Nonce store down
+	nonces.err = errors.New("redis down")
+	nonces.seen = map[string]bool{}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest())
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
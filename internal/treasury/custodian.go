@@ -0,0 +1,106 @@
+// Package treasury defines how the platform custodies the BTC backing card balances.
+//
+// card.Service depends on the Custodian interface, never on a specific node or
+// custody provider. The first backend is LND (Lightning channels + LND's
+// on-chain wallet); institutional custodians (Fireblocks, BitGo, ...) can be
+// added later as further Custodian implementations without touching card logic.
+//
+//	┌──────────┐     ┌───────────────────┐
+//	│ card pkg │────▶│ treasury.Custodian │ (interface)
+//	└──────────┘     └─────────┬─────────┘
+//	                           │
+//	          ┌────────────────┼──────────────────┐
+//	          ▼                ▼                  ▼
+//	   LNDCustodian     (Fireblocks — TODO)  (BitGo — TODO)
+package treasury
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotSupported is returned by backends that cannot perform an operation
+	// (e.g., an on-chain-only custodian asked to pay a Lightning invoice).
+	ErrNotSupported = errors.New("operation not supported by custodian")
+	// ErrInsufficientLiquidity is returned when no route has enough outbound
+	// liquidity to carry a Lightning payment.
+	ErrInsufficientLiquidity = errors.New("insufficient outbound liquidity")
+	// ErrFeeTooHigh is returned when the estimated routing fee exceeds the fee limit.
+	ErrFeeTooHigh = errors.New("estimated routing fee exceeds limit")
+	// ErrPaymentFailed is returned when a Lightning payment definitively
+	// failed or was never sent: no HTLC is left in flight.
+	ErrPaymentFailed = errors.New("lightning payment failed")
+)
+
+// Custodian is the custody backend holding treasury funds.
+type Custodian interface {
+	// GetBalances returns the funds currently held, split by rail.
+	GetBalances(ctx context.Context) (*Balances, error)
+
+	// SendOnChain sends amountSats to a Bitcoin address.
+	// targetConf controls fee rate: 2=next block, 6=~1h, 144=~1day.
+	SendOnChain(ctx context.Context, address string, amountSats int64, targetConf int32) (*OnChainPayment, error)
+
+	// DecodeInvoice decodes a BOLT11 invoice without paying it.
+	DecodeInvoice(ctx context.Context, bolt11 string) (*Invoice, error)
+
+	// EstimateLightningFee probes the route to a BOLT11 invoice's destination
+	// without paying it. Returns ErrInsufficientLiquidity if no route can carry
+	// the amount and ErrFeeTooHigh if the fee would exceed the payment fee limit.
+	EstimateLightningFee(ctx context.Context, bolt11 string) (*FeeEstimate, error)
+
+	// PayLightning pays a BOLT11 invoice. It returns an error unless the
+	// payment reached a succeeded state; the error wraps ErrPaymentFailed only
+	// if the payment can no longer succeed. Any other error (e.g. a timeout)
+	// means HTLCs may still be in flight, see TrackLightning.
+	PayLightning(ctx context.Context, bolt11 string) (*LightningPayment, error)
+
+	// TrackLightning waits for an earlier payment to resolve. It returns the
+	// payment once it succeeded and ErrPaymentFailed once it failed or if it
+	// was never sent.
+	TrackLightning(ctx context.Context, paymentHash string) (*LightningPayment, error)
+
+	// NewDepositAddress returns a fresh on-chain address for treasury deposits
+	// (e.g., receiving OTC-purchased BTC).
+	NewDepositAddress(ctx context.Context) (string, error)
+}
+
+// Balances is the treasury's holdings in satoshis.
+type Balances struct {
+	LightningSats          int64 // Spendable via Lightning (local channel balance)
+	OnChainConfirmedSats   int64 // Confirmed on-chain wallet balance
+	OnChainUnconfirmedSats int64 // Pending on-chain deposits (not spendable yet)
+}
+
+// SpendableSats returns the balance that can back card redemptions right now.
+func (b *Balances) SpendableSats() int64 {
+	return b.LightningSats + b.OnChainConfirmedSats
+}
+
+// Invoice is a decoded BOLT11 invoice.
+type Invoice struct {
+	Destination string // Recipient node public key
+	AmountSats  int64  // Invoice amount in satoshis (0 = any amount)
+	PaymentHash string // Hex-encoded payment hash
+	Description string // Invoice description/memo
+	IsExpired   bool   // true if invoice has expired
+}
+
+// FeeEstimate is an upfront Lightning routing fee quote.
+type FeeEstimate struct {
+	FeeSats       int64 // Estimated routing fee in satoshis (lower bound)
+	TimeLockDelay int64 // Worst-case timelock delay in blocks
+}
+
+// LightningPayment is the result of a settled Lightning payment.
+type LightningPayment struct {
+	PaymentHash     string // Hex-encoded payment hash
+	PaymentPreimage string // Hex-encoded preimage (proof of payment)
+	FeeSats         int64  // Routing fee paid in satoshis
+}
+
+// OnChainPayment is the result of a broadcast on-chain send.
+type OnChainPayment struct {
+	TxHash string // Hex-encoded transaction hash (64 chars)
+}
@@ -0,0 +1,144 @@
+package treasury
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"btc-giftcard/internal/chaos"
+	"btc-giftcard/internal/lnd"
+)
+
+// LNDCustodian custodies treasury funds in an LND node: Lightning channels
+// for instant redemptions and LND's on-chain wallet for on-chain sends.
+type LNDCustodian struct {
+	client     lnd.LightningClient
+	maxFeeSats int64 // Max Lightning routing fee per payment
+}
+
+// NewLNDCustodian creates a Custodian backed by an LND client.
+func NewLNDCustodian(client lnd.LightningClient, maxFeeSats int64) *LNDCustodian {
+	return &LNDCustodian{
+		client:     client,
+		maxFeeSats: maxFeeSats,
+	}
+}
+
+// GetBalances combines LND's channel and wallet balances.
+func (c *LNDCustodian) GetBalances(ctx context.Context) (*Balances, error) {
+	channelBal, err := c.client.GetChannelBalance(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel balance: %w", err)
+	}
+
+	walletBal, err := c.client.GetWalletBalance(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet balance: %w", err)
+	}
+
+	return &Balances{
+		LightningSats:          channelBal.LocalSats,
+		OnChainConfirmedSats:   walletBal.ConfirmedSats,
+		OnChainUnconfirmedSats: walletBal.UnconfirmedSats,
+	}, nil
+}
+
+// SendOnChain sends from LND's on-chain wallet.
+func (c *LNDCustodian) SendOnChain(ctx context.Context, address string, amountSats int64, targetConf int32) (*OnChainPayment, error) {
+	if err := chaos.Fail(chaos.LNDDisconnect); err != nil {
+		return nil, err // Connection dropped before sending: nothing was broadcast
+	}
+	result, err := c.client.SendOnChain(ctx, address, amountSats, targetConf)
+	if err != nil {
+		return nil, err
+	}
+	return &OnChainPayment{TxHash: result.TxHash}, nil
+}
+
+// DecodeInvoice decodes a BOLT11 invoice via LND.
+func (c *LNDCustodian) DecodeInvoice(ctx context.Context, bolt11 string) (*Invoice, error) {
+	decoded, err := c.client.DecodeInvoice(ctx, bolt11)
+	if err != nil {
+		return nil, err
+	}
+	return &Invoice{
+		Destination: decoded.Destination,
+		AmountSats:  decoded.AmountSats,
+		PaymentHash: decoded.PaymentHash,
+		Description: decoded.Description,
+		IsExpired:   decoded.IsExpired,
+	}, nil
+}
+
+// EstimateLightningFee probes the invoice route through LND.
+func (c *LNDCustodian) EstimateLightningFee(ctx context.Context, bolt11 string) (*FeeEstimate, error) {
+	estimate, err := c.client.EstimateRouteFee(ctx, bolt11)
+	if err != nil {
+		return nil, err
+	}
+
+	if estimate.NoLiquidity() {
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientLiquidity, estimate.FailureReason)
+	}
+	if estimate.FailureReason != "" {
+		return nil, fmt.Errorf("route probe failed: %s", estimate.FailureReason)
+	}
+	if c.maxFeeSats > 0 && estimate.RoutingFeeSats > c.maxFeeSats {
+		return nil, fmt.Errorf("%w: %d sats (limit %d sats)", ErrFeeTooHigh, estimate.RoutingFeeSats, c.maxFeeSats)
+	}
+
+	return &FeeEstimate{
+		FeeSats:       estimate.RoutingFeeSats,
+		TimeLockDelay: estimate.TimeLockDelay,
+	}, nil
+}
+
+// PayLightning pays a BOLT11 invoice from LND's channels using the configured fee limit.
+func (c *LNDCustodian) PayLightning(ctx context.Context, bolt11 string) (*LightningPayment, error) {
+	if err := chaos.Fail(chaos.LNDDisconnect); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPaymentFailed, err) // Connection dropped before paying: no HTLC was sent
+	}
+	result, err := c.client.PayInvoice(ctx, bolt11, c.maxFeeSats)
+	if err != nil {
+		if result != nil && result.Status == lnd.Failed {
+			return nil, fmt.Errorf("%w: %w", ErrPaymentFailed, err)
+		}
+		return nil, err
+	}
+
+	// PayInvoice could return non-error with a non-terminal status
+	if result.Status != lnd.Succeeded {
+		return nil, fmt.Errorf("lightning payment did not succeed: status=%s", result.Status)
+	}
+
+	return &LightningPayment{
+		PaymentHash:     result.PaymentHash,
+		PaymentPreimage: result.PaymentPreimage,
+		FeeSats:         result.FeeSats,
+	}, nil
+}
+
+// TrackLightning waits for LND to settle or fail an earlier payment.
+func (c *LNDCustodian) TrackLightning(ctx context.Context, paymentHash string) (*LightningPayment, error) {
+	result, err := c.client.TrackPayment(ctx, paymentHash)
+	if err != nil {
+		if errors.Is(err, lnd.ErrPaymentNotFound) {
+			return nil, fmt.Errorf("%w: %w", ErrPaymentFailed, err)
+		}
+		return nil, err
+	}
+	if result.Status != lnd.Succeeded {
+		return nil, fmt.Errorf("%w: status=%s", ErrPaymentFailed, result.Status)
+	}
+
+	return &LightningPayment{
+		PaymentHash:     result.PaymentHash,
+		PaymentPreimage: result.PaymentPreimage,
+		FeeSats:         result.FeeSats,
+	}, nil
+}
+
+// NewDepositAddress derives a fresh bech32 address from LND's wallet.
+func (c *LNDCustodian) NewDepositAddress(ctx context.Context) (string, error) {
+	return c.client.NewAddress(ctx)
+}
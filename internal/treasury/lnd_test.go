@@ -0,0 +1,316 @@
+package treasury
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"btc-giftcard/internal/lnd"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Mock — stubs lnd.LightningClient
+// ============================================================================
+
+type mockLightningClient struct {
+	lnd.LightningClient // embed for interface compliance
+
+	payInvoiceFn       func(ctx context.Context, bolt11 string, maxFeeSats int64) (*lnd.PaymentResult, error)
+	decodeInvoiceFn    func(ctx context.Context, bolt11 string) (*lnd.Invoice, error)
+	sendOnChainFn      func(ctx context.Context, address string, amountSats int64, targetConf int32) (*lnd.OnChainResult, error)
+	newAddressFn       func(ctx context.Context) (string, error)
+	walletBalanceFn    func(ctx context.Context) (*lnd.WalletBalance, error)
+	channelBalanceFn   func(ctx context.Context) (*lnd.ChannelBalance, error)
+	estimateRouteFeeFn func(ctx context.Context, bolt11 string) (*lnd.RouteFeeEstimate, error)
+	trackPaymentFn     func(ctx context.Context, paymentHash string) (*lnd.PaymentResult, error)
+}
+
+func (m *mockLightningClient) PayInvoice(ctx context.Context, bolt11 string, maxFeeSats int64) (*lnd.PaymentResult, error) {
+	return m.payInvoiceFn(ctx, bolt11, maxFeeSats)
+}
+
+func (m *mockLightningClient) DecodeInvoice(ctx context.Context, bolt11 string) (*lnd.Invoice, error) {
+	return m.decodeInvoiceFn(ctx, bolt11)
+}
+
+func (m *mockLightningClient) SendOnChain(ctx context.Context, address string, amountSats int64, targetConf int32) (*lnd.OnChainResult, error) {
+	return m.sendOnChainFn(ctx, address, amountSats, targetConf)
+}
+
+func (m *mockLightningClient) NewAddress(ctx context.Context) (string, error) {
+	return m.newAddressFn(ctx)
+}
+
+func (m *mockLightningClient) GetWalletBalance(ctx context.Context) (*lnd.WalletBalance, error) {
+	return m.walletBalanceFn(ctx)
+}
+
+func (m *mockLightningClient) GetChannelBalance(ctx context.Context) (*lnd.ChannelBalance, error) {
+	return m.channelBalanceFn(ctx)
+}
+
+func (m *mockLightningClient) EstimateRouteFee(ctx context.Context, bolt11 string) (*lnd.RouteFeeEstimate, error) {
+	return m.estimateRouteFeeFn(ctx, bolt11)
+}
+
+func (m *mockLightningClient) TrackPayment(ctx context.Context, paymentHash string) (*lnd.PaymentResult, error) {
+	return m.trackPaymentFn(ctx, paymentHash)
+}
+
+var _ Custodian = (*LNDCustodian)(nil)
+
+// ============================================================================
+// GetBalances tests
+// ============================================================================
+
+func TestLNDCustodian_GetBalances(t *testing.T) {
+	mock := &mockLightningClient{
+		channelBalanceFn: func(_ context.Context) (*lnd.ChannelBalance, error) {
+			return &lnd.ChannelBalance{LocalSats: 500000, RemoteSats: 300000}, nil
+		},
+		walletBalanceFn: func(_ context.Context) (*lnd.WalletBalance, error) {
+			return &lnd.WalletBalance{ConfirmedSats: 200000, UnconfirmedSats: 10000, TotalSats: 210000}, nil
+		},
+	}
+
+	custodian := NewLNDCustodian(mock, 100)
+	bal, err := custodian.GetBalances(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(500000), bal.LightningSats)
+	assert.Equal(t, int64(200000), bal.OnChainConfirmedSats)
+	assert.Equal(t, int64(10000), bal.OnChainUnconfirmedSats)
+	assert.Equal(t, int64(700000), bal.SpendableSats(), "unconfirmed deposits are not spendable")
+}
+
+func TestLNDCustodian_GetBalances_ChannelError(t *testing.T) {
+	mock := &mockLightningClient{
+		channelBalanceFn: func(_ context.Context) (*lnd.ChannelBalance, error) {
+			return nil, errors.New("lnd unavailable")
+		},
+	}
+
+	custodian := NewLNDCustodian(mock, 100)
+	bal, err := custodian.GetBalances(context.Background())
+
+	assert.Nil(t, bal)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "channel balance")
+}
+
+// ============================================================================
+// PayLightning tests
+// ============================================================================
+
+func TestLNDCustodian_PayLightning_Success(t *testing.T) {
+	mock := &mockLightningClient{
+		payInvoiceFn: func(_ context.Context, bolt11 string, maxFeeSats int64) (*lnd.PaymentResult, error) {
+			assert.Equal(t, "lntb1...", bolt11)
+			assert.Equal(t, int64(100), maxFeeSats, "configured fee limit should be passed through")
+			return &lnd.PaymentResult{
+				PaymentHash:     "hash",
+				PaymentPreimage: "preimage",
+				FeeSats:         3,
+				Status:          lnd.Succeeded,
+			}, nil
+		},
+	}
+
+	custodian := NewLNDCustodian(mock, 100)
+	payment, err := custodian.PayLightning(context.Background(), "lntb1...")
+
+	require.NoError(t, err)
+	assert.Equal(t, "hash", payment.PaymentHash)
+	assert.Equal(t, "preimage", payment.PaymentPreimage)
+	assert.Equal(t, int64(3), payment.FeeSats)
+}
+
+func TestLNDCustodian_PayLightning_NotSucceeded(t *testing.T) {
+	mock := &mockLightningClient{
+		payInvoiceFn: func(_ context.Context, _ string, _ int64) (*lnd.PaymentResult, error) {
+			return &lnd.PaymentResult{PaymentHash: "hash", Status: lnd.InFlight}, nil
+		},
+	}
+
+	custodian := NewLNDCustodian(mock, 100)
+	payment, err := custodian.PayLightning(context.Background(), "lntb1...")
+
+	assert.Nil(t, payment)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "in_flight")
+	assert.NotErrorIs(t, err, ErrPaymentFailed, "an in-flight payment may still succeed")
+}
+
+func TestLNDCustodian_PayLightning_Failed(t *testing.T) {
+	mock := &mockLightningClient{
+		payInvoiceFn: func(_ context.Context, _ string, _ int64) (*lnd.PaymentResult, error) {
+			return &lnd.PaymentResult{PaymentHash: "hash", Status: lnd.Failed}, errors.New("payment failed: FAILURE_REASON_NO_ROUTE")
+		},
+	}
+
+	_, err := NewLNDCustodian(mock, 100).PayLightning(context.Background(), "lntb1...")
+	assert.ErrorIs(t, err, ErrPaymentFailed)
+}
+
+func TestLNDCustodian_PayLightning_Timeout(t *testing.T) {
+	mock := &mockLightningClient{
+		payInvoiceFn: func(_ context.Context, _ string, _ int64) (*lnd.PaymentResult, error) {
+			return nil, errors.New("payment stream error: context deadline exceeded")
+		},
+	}
+
+	_, err := NewLNDCustodian(mock, 100).PayLightning(context.Background(), "lntb1...")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrPaymentFailed, "HTLCs may still be in flight after a timeout")
+}
+
+// ============================================================================
+// TrackLightning tests
+// ============================================================================
+
+func TestLNDCustodian_TrackLightning(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     *lnd.PaymentResult
+		err        error
+		wantFailed bool
+	}{
+		{name: "succeeded", result: &lnd.PaymentResult{PaymentHash: "hash", PaymentPreimage: "preimage", Status: lnd.Succeeded}},
+		{name: "failed", result: &lnd.PaymentResult{PaymentHash: "hash", Status: lnd.Failed}, wantFailed: true},
+		{name: "never sent", err: lnd.ErrPaymentNotFound, wantFailed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockLightningClient{
+				trackPaymentFn: func(_ context.Context, paymentHash string) (*lnd.PaymentResult, error) {
+					assert.Equal(t, "hash", paymentHash)
+					return tt.result, tt.err
+				},
+			}
+
+			payment, err := NewLNDCustodian(mock, 100).TrackLightning(context.Background(), "hash")
+			if tt.wantFailed {
+				assert.ErrorIs(t, err, ErrPaymentFailed)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "preimage", payment.PaymentPreimage)
+		})
+	}
+}
+
+// ============================================================================
+// EstimateLightningFee tests
+// ============================================================================
+
+func TestLNDCustodian_EstimateLightningFee(t *testing.T) {
+	tests := []struct {
+		name        string
+		estimate    *lnd.RouteFeeEstimate
+		expectedErr error
+		expectedFee int64
+	}{
+		{"Route found", &lnd.RouteFeeEstimate{RoutingFeeSats: 12, TimeLockDelay: 80}, nil, 12},
+		{"No route", &lnd.RouteFeeEstimate{FailureReason: "FAILURE_REASON_NO_ROUTE"}, ErrInsufficientLiquidity, 0},
+		{"Insufficient balance", &lnd.RouteFeeEstimate{FailureReason: "FAILURE_REASON_INSUFFICIENT_BALANCE"}, ErrInsufficientLiquidity, 0},
+		{"Fee above limit", &lnd.RouteFeeEstimate{RoutingFeeSats: 101}, ErrFeeTooHigh, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockLightningClient{
+				estimateRouteFeeFn: func(_ context.Context, _ string) (*lnd.RouteFeeEstimate, error) {
+					return tt.estimate, nil
+				},
+			}
+
+			custodian := NewLNDCustodian(mock, 100)
+			estimate, err := custodian.EstimateLightningFee(context.Background(), "lntb1...")
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, estimate)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFee, estimate.FeeSats)
+		})
+	}
+}
+
+func TestLNDCustodian_EstimateLightningFee_OtherFailure(t *testing.T) {
+	mock := &mockLightningClient{
+		estimateRouteFeeFn: func(_ context.Context, _ string) (*lnd.RouteFeeEstimate, error) {
+			return &lnd.RouteFeeEstimate{FailureReason: "FAILURE_REASON_TIMEOUT"}, nil
+		},
+	}
+
+	custodian := NewLNDCustodian(mock, 100)
+	estimate, err := custodian.EstimateLightningFee(context.Background(), "lntb1...")
+
+	assert.Nil(t, estimate)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInsufficientLiquidity)
+	assert.Contains(t, err.Error(), "TIMEOUT")
+}
+
+// ============================================================================
+// DecodeInvoice / SendOnChain / NewDepositAddress tests
+// ============================================================================
+
+func TestLNDCustodian_DecodeInvoice(t *testing.T) {
+	mock := &mockLightningClient{
+		decodeInvoiceFn: func(_ context.Context, _ string) (*lnd.Invoice, error) {
+			return &lnd.Invoice{
+				Destination: "03abc",
+				AmountSats:  50000,
+				PaymentHash: "hash",
+				Description: "coffee",
+				IsExpired:   false,
+			}, nil
+		},
+	}
+
+	custodian := NewLNDCustodian(mock, 100)
+	invoice, err := custodian.DecodeInvoice(context.Background(), "lntb1...")
+
+	require.NoError(t, err)
+	assert.Equal(t, "03abc", invoice.Destination)
+	assert.Equal(t, int64(50000), invoice.AmountSats)
+	assert.Equal(t, "coffee", invoice.Description)
+}
+
+func TestLNDCustodian_SendOnChain(t *testing.T) {
+	mock := &mockLightningClient{
+		sendOnChainFn: func(_ context.Context, address string, amountSats int64, targetConf int32) (*lnd.OnChainResult, error) {
+			assert.Equal(t, "tb1qaddr", address)
+			assert.Equal(t, int64(20000), amountSats)
+			assert.Equal(t, int32(6), targetConf)
+			return &lnd.OnChainResult{TxHash: "txid"}, nil
+		},
+	}
+
+	custodian := NewLNDCustodian(mock, 100)
+	result, err := custodian.SendOnChain(context.Background(), "tb1qaddr", 20000, 6)
+
+	require.NoError(t, err)
+	assert.Equal(t, "txid", result.TxHash)
+}
+
+func TestLNDCustodian_NewDepositAddress(t *testing.T) {
+	mock := &mockLightningClient{
+		newAddressFn: func(_ context.Context) (string, error) {
+			return "tb1qdeposit", nil
+		},
+	}
+
+	custodian := NewLNDCustodian(mock, 100)
+	addr, err := custodian.NewDepositAddress(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "tb1qdeposit", addr)
+}
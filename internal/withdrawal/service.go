@@ -0,0 +1,233 @@
+// Package withdrawal handles owner withdrawals of profit from the treasury.
+//
+// A withdrawal is requested by one operator (Request) and sent only once a
+// different operator approves it (Approve). Both steps check that the
+// destination is allowlisted and that what stays in the treasury still covers
+// every card balance plus a safety buffer; the approval re-checks under the
+// treasury lock, so a concurrent card funding can't eat into the reserve. The
+// on-chain send is recorded as a 'withdrawal' debit in treasury_ledger.
+package withdrawal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/treasury"
+	"btc-giftcard/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrDestinationNotAllowed = errors.New("destination is not in the withdrawal allowlist")
+	ErrSelfApproval          = errors.New("a withdrawal must be approved by someone other than the requester")
+	ErrNotRequested          = errors.New("withdrawal is no longer waiting for approval")
+	ErrReserveShortfall      = errors.New("withdrawal would leave reserves below liabilities plus buffer")
+)
+
+// Config controls withdrawals (populated from config.toml [withdrawals] section).
+type Config struct {
+	AllowedDestinations  []string // On-chain addresses owners may withdraw to
+	ReserveBufferBps     int64    // Reserves must exceed liabilities by this share (1000 = 10%)...
+	MinReserveBufferSats int64    // ...and by at least this many sats
+	TargetConf           int32    // Confirmation target of the on-chain send
+}
+
+// TreasuryLock serializes treasury reservations (implemented by card.Service).
+type TreasuryLock interface {
+	AcquireTreasuryLock(ctx context.Context) (bool, error)
+	ReleaseTreasuryLock(ctx context.Context)
+	InvalidateTreasuryCache(ctx context.Context)
+}
+
+// Service requests, approves and sends withdrawals.
+type Service struct {
+	repo      *database.WithdrawalRepository
+	cards     *database.CardRepository // Liabilities: card and account balances
+	custodian treasury.Custodian
+	lock      TreasuryLock
+	cfg       Config
+}
+
+// NewService creates a withdrawal service.
+func NewService(repo *database.WithdrawalRepository, cards *database.CardRepository, custodian treasury.Custodian, lock TreasuryLock, cfg Config) *Service {
+	return &Service{
+		repo:      repo,
+		cards:     cards,
+		custodian: custodian,
+		lock:      lock,
+		cfg:       cfg,
+	}
+}
+
+// Request records a withdrawal waiting for a second operator's approval.
+// Fails early if the withdrawal would not pass the reserve check right now.
+func (s *Service) Request(ctx context.Context, amountSats int64, destination, requestedBy, reason string) (*database.TreasuryWithdrawal, error) {
+	if amountSats <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	if requestedBy == "" {
+		return nil, errors.New("requester is required")
+	}
+	if !s.allowed(destination) {
+		return nil, ErrDestinationNotAllowed
+	}
+	if err := s.checkReserves(ctx, amountSats); err != nil {
+		return nil, err
+	}
+
+	w := &database.TreasuryWithdrawal{
+		ID:                 uuid.New().String(),
+		AmountSats:         amountSats,
+		DestinationAddress: destination,
+		Reason:             reason,
+		RequestedBy:        requestedBy,
+		Status:             database.WithdrawalRequested,
+		CreatedAt:          time.Now().UTC(),
+	}
+	if err := s.repo.Create(ctx, w); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Treasury withdrawal requested",
+		zap.String("withdrawal_id", w.ID),
+		zap.Int64("amount_sats", amountSats),
+		zap.String("destination", destination),
+		zap.String("requested_by", requestedBy),
+	)
+	return w, nil
+}
+
+// Approve approves a requested withdrawal and sends it on-chain. approvedBy
+// must differ from the requester. The reserve check is repeated under the
+// treasury lock right before sending.
+func (s *Service) Approve(ctx context.Context, id, approvedBy string) (*database.TreasuryWithdrawal, error) {
+	w, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if w.Status != database.WithdrawalRequested {
+		return nil, ErrNotRequested
+	}
+	if approvedBy == "" || approvedBy == w.RequestedBy {
+		return nil, ErrSelfApproval
+	}
+	// The allowlist may have changed since the request
+	if !s.allowed(w.DestinationAddress) {
+		return nil, ErrDestinationNotAllowed
+	}
+
+	if _, err := s.lock.AcquireTreasuryLock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.lock.ReleaseTreasuryLock(ctx)
+
+	if err := s.checkReserves(ctx, w.AmountSats); err != nil {
+		return nil, err
+	}
+
+	decided, err := s.repo.Decide(ctx, id, database.WithdrawalApproved, approvedBy, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		return nil, ErrNotRequested
+	}
+
+	payment, err := s.custodian.SendOnChain(ctx, w.DestinationAddress, w.AmountSats, s.cfg.TargetConf)
+	if err != nil {
+		if markErr := s.repo.MarkFailed(ctx, id); markErr != nil {
+			logger.Error("Failed to mark withdrawal failed", zap.String("withdrawal_id", id), zap.Error(markErr))
+		}
+		return nil, fmt.Errorf("withdrawal send failed: %w", err)
+	}
+
+	if _, err := s.repo.MarkSent(ctx, id, payment.TxHash, time.Now().UTC()); err != nil {
+		// Funds left the treasury but the ledger doesn't show it — needs manual fix
+		logger.Error("CRITICAL: withdrawal sent but not recorded",
+			zap.String("withdrawal_id", id),
+			zap.String("tx_hash", payment.TxHash),
+			zap.Int64("amount_sats", w.AmountSats),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	s.lock.InvalidateTreasuryCache(ctx)
+
+	logger.Info("Treasury withdrawal sent",
+		zap.String("withdrawal_id", id),
+		zap.String("approved_by", approvedBy),
+		zap.String("tx_hash", payment.TxHash),
+		zap.Int64("amount_sats", w.AmountSats),
+	)
+	return s.repo.GetByID(ctx, id)
+}
+
+// Reject rejects a requested withdrawal. Like approval, it takes a second operator.
+func (s *Service) Reject(ctx context.Context, id, rejectedBy string) error {
+	w, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rejectedBy == "" || rejectedBy == w.RequestedBy {
+		return ErrSelfApproval
+	}
+	decided, err := s.repo.Decide(ctx, id, database.WithdrawalRejected, rejectedBy, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !decided {
+		return ErrNotRequested
+	}
+
+	logger.Info("Treasury withdrawal rejected", zap.String("withdrawal_id", id), zap.String("rejected_by", rejectedBy))
+	return nil
+}
+
+// List returns the most recent withdrawals, newest first.
+func (s *Service) List(ctx context.Context, limit int) ([]*database.TreasuryWithdrawal, error) {
+	return s.repo.List(ctx, limit)
+}
+
+func (s *Service) allowed(destination string) bool {
+	for _, addr := range s.cfg.AllowedDestinations {
+		if addr == destination {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReserves fails with ErrReserveShortfall unless the treasury still
+// covers liabilities plus buffer after sending amountSats.
+func (s *Service) checkReserves(ctx context.Context, amountSats int64) error {
+	balances, err := s.custodian.GetBalances(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get treasury balances: %w", err)
+	}
+	liabilities, err := s.cards.GetTotalReservedBalance(ctx)
+	if err != nil {
+		return err
+	}
+
+	required := RequiredReserve(liabilities, s.cfg.ReserveBufferBps, s.cfg.MinReserveBufferSats)
+	if remaining := balances.SpendableSats() - amountSats; remaining < required {
+		return fmt.Errorf("%w: %d sats would remain, %d required (liabilities %d)",
+			ErrReserveShortfall, remaining, required, liabilities)
+	}
+	return nil
+}
+
+// RequiredReserve is what the treasury must keep: liabilities plus the larger
+// of bufferBps of them and minBufferSats.
+func RequiredReserve(liabilities, bufferBps, minBufferSats int64) int64 {
+	buffer := liabilities * bufferBps / 10_000
+	if buffer < minBufferSats {
+		buffer = minBufferSats
+	}
+	return liabilities + buffer
+}
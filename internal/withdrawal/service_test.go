@@ -0,0 +1,20 @@
+package withdrawal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredReserve(t *testing.T) {
+	assert.Equal(t, int64(110_000_000), RequiredReserve(100_000_000, 1_000, 1_000_000), "10% buffer")
+	assert.Equal(t, int64(6_000_000), RequiredReserve(5_000_000, 1_000, 1_000_000), "minimum buffer")
+	assert.Equal(t, int64(1_000_000), RequiredReserve(0, 1_000, 1_000_000), "no liabilities")
+}
+
+func TestService_Allowed(t *testing.T) {
+	s := &Service{cfg: Config{AllowedDestinations: []string{"bc1qcold"}}}
+	assert.True(t, s.allowed("bc1qcold"))
+	assert.False(t, s.allowed("bc1qother"))
+	assert.False(t, s.allowed(""))
+}
@@ -0,0 +1,44 @@
+//go:build integration && chaos
+
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/chaos"
+	"btc-giftcard/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A worker that dies while its ACK is delayed leaves the message pending; the
+// redelivery is skipped by the idempotency check instead of handled twice.
+func TestChaos_AckDelay_RedeliverySkipped(t *testing.T) {
+	db := database.SetupTestDB(t)
+	defer db.Close()
+	defer database.CleanupTestDB(t, db)
+	defer chaos.Configure("")
+
+	require.NoError(t, chaos.Configure("worker.ack_delay=1h"))
+
+	calls := 0
+	handler := withAckDelay(withIdempotency("chaos_test", database.NewProcessedMessageRepository(db),
+		func(ctx context.Context, messageID string, data []byte) error {
+			calls++
+			return nil
+		}))
+
+	// First delivery: handled, then the worker "crashes" while waiting to ACK
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.NoError(t, handler(ctx, "1-0", []byte("{}")))
+	assert.Equal(t, 1, calls)
+
+	// Redelivery after the pending message is reclaimed
+	require.NoError(t, chaos.Configure(""))
+	require.NoError(t, handler(context.Background(), "1-0", []byte("{}")))
+	assert.Equal(t, 1, calls, "redelivered message must not be handled again")
+}
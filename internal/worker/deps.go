@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/database"
+	"btc-giftcard/internal/featureflag"
+	"btc-giftcard/internal/guardrail"
+	"btc-giftcard/internal/lnd"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+)
+
+// Deps holds the initialized clients a handler can use.
+// Fields for dependencies that were not requested are nil.
+type Deps struct {
+	Config config.ApiConfig
+	Queue  *streams.StreamQueue
+	Flags  *featureflag.Flags
+
+	// Database dependency
+	DB            *database.DB
+	CardRepo      *database.CardRepository
+	TxRepo        *database.TransactionRepository
+	ProcessedRepo *database.ProcessedMessageRepository
+	DepositRepo   *database.DepositRepository
+
+	// LND dependency
+	LND *lnd.Client
+}
+
+// newDeps connects to Redis and every dependency listed in opts.
+// On error, anything already opened is closed before returning.
+func newDeps(cfg config.ApiConfig, opts Options) (*Deps, error) {
+	deps := &Deps{Config: cfg, Flags: featureflag.FromConfig(cfg.Features)}
+	ready := false
+	defer func() {
+		if !ready {
+			deps.close()
+		}
+	}()
+
+	// Initialize Redis
+	var redisCfg cache.Config
+	if err := copier.Copy(&redisCfg, &cfg.Redis); err != nil {
+		return nil, fmt.Errorf("failed to copy cache config: %w", err)
+	}
+	if err := cache.Init(redisCfg); err != nil {
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	deps.Queue = newQueue()
+
+	// Initialize database
+	if opts.needs(Database) {
+		var dbCfg database.Config
+		if err := copier.Copy(&dbCfg, &cfg.Database); err != nil {
+			return nil, fmt.Errorf("failed to copy database config: %w", err)
+		}
+		db, err := database.NewDB(dbCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize database connection: %w", err)
+		}
+		deps.DB = db
+		deps.CardRepo = database.NewCardRepository(db)
+		deps.TxRepo = database.NewTransactionRepository(db)
+		deps.ProcessedRepo = database.NewProcessedMessageRepository(db)
+		deps.DepositRepo = database.NewDepositRepository(db)
+	}
+
+	// Initialize LND
+	if opts.needs(LND) {
+		lndClient, err := lnd.NewClient(LNDConfig(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to LND: %w", err)
+		}
+		deps.LND = lndClient
+
+		// Refuse a node on another network than configured
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		info, err := lndClient.GetInfo(ctx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get LND node info: %w", err)
+		}
+		if err := guardrail.CheckNode(cfg.LND.Network, info); err != nil {
+			return nil, fmt.Errorf("refusing to start: %w", err)
+		}
+	}
+
+	ready = true
+	return deps, nil
+}
+
+// LNDConfig maps the [lnd] config section onto lnd.Config.
+func LNDConfig(cfg config.ApiConfig) lnd.Config {
+	return lnd.Config{
+		GRPCHost:              cfg.LND.GRPCHost,
+		GRPCPort:              cfg.LND.Port,
+		TLSCertPath:           cfg.LND.TLSCertPath,
+		MacaroonPath:          cfg.LND.MacaroonPath,
+		Network:               cfg.LND.Network,
+		PaymentTimeoutSeconds: cfg.LND.PaymentTimeoutSeconds,
+		MaxPaymentFeeSats:     cfg.LND.MaxPaymentFeeSats,
+		MaxParts:              cfg.LND.MaxParts,
+		MaxShardSizeSats:      cfg.LND.MaxShardSizeSats,
+		AllowAMP:              cfg.LND.AllowAMP,
+		CltvLimit:             cfg.LND.CltvLimit,
+		ProbeTimeoutSeconds:   cfg.LND.ProbeTimeoutSeconds,
+	}
+}
+
+// close releases every opened dependency in reverse order of initialization.
+func (d *Deps) close() {
+	if d.LND != nil {
+		if err := d.LND.Close(); err != nil {
+			logger.Warn("Failed to close LND connection", zap.Error(err))
+		}
+	}
+	if d.DB != nil {
+		d.DB.Close()
+	}
+	if d.Queue != nil {
+		if err := cache.Close(); err != nil {
+			logger.Warn("Failed to close Redis connection", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// startHealthServer serves /healthz (dependency pings), /debug/vars (expvar
+// metrics) and, for stream workers, /scaling (consumer group backlog) on addr.
+// An empty addr disables the server.
+func startHealthServer(addr string, deps *Deps, opts Options) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", healthHandler(deps))
+	mux.Handle("/debug/vars", expvar.Handler())
+	if opts.Stream != "" {
+		mux.HandleFunc("/scaling", scalingHandler(deps.Queue, opts.Stream, opts.Group))
+	}
+
+	return serve("Health", addr, mux)
+}
+
+// startDebugServer serves /debug/loglevel (GET or PUT {"level":"debug"}) on
+// addr. The endpoint changes the worker's behavior and has no authentication,
+// so it is kept off the health port and addr must be a loopback address. An
+// empty addr disables the server.
+func startDebugServer(addr string) (*http.Server, error) {
+	if addr != "" {
+		if err := checkLoopback(addr); err != nil {
+			return nil, err
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/loglevel", logger.LevelHandler())
+
+	return serve("Debug", addr, mux), nil
+}
+
+// checkLoopback returns an error unless addr's host is a loopback address
+// (127.0.0.0/8, ::1 or "localhost").
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid debug address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("debug address %q must be a loopback address (e.g. 127.0.0.1:8082)", addr)
+}
+
+// serve starts srv in the background unless addr is empty. The returned
+// server can always be shut down.
+func serve(name, addr string, handler http.Handler) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if addr == "" {
+		return srv
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(name+" server error", zap.Error(err))
+		}
+	}()
+
+	return srv
+}
+
+// healthHandler returns 200 when Redis and every initialized dependency respond.
+func healthHandler(deps *Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		if err := cache.Ping(ctx); err != nil {
+			http.Error(w, "redis unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if deps.DB != nil {
+			if err := deps.DB.Ping(ctx); err != nil {
+				http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		if deps.LND != nil {
+			if _, err := deps.LND.GetInfo(ctx); err != nil {
+				http.Error(w, "lnd unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"time"
+
+	"btc-giftcard/internal/chaos"
+	"btc-giftcard/internal/database"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+	streams "btc-giftcard/pkg/queue"
+
+	"go.uber.org/zap"
+)
+
+// Metrics are published under "worker_messages" on /debug/vars.
+var metrics = expvar.NewMap("worker_messages")
+
+const (
+	metricProcessed    = "processed"
+	metricFailed       = "failed"
+	metricSkipped      = "skipped"
+	metricDeadLettered = "dead_lettered"
+)
+
+// Dead-letter policy constants
+const (
+	deadLetterSuffix      = ":dlq"
+	failureCountKeyPrefix = "worker:failures:"
+	failureCountTTL       = 24 * time.Hour
+)
+
+// DeadLetter is the payload published to "<stream>:dlq" when a message
+// exhausts its deliveries.
+type DeadLetter struct {
+	Stream    string    `json:"stream"`
+	MessageID string    `json:"message_id"`
+	Data      string    `json:"data"`
+	Error     string    `json:"error"`
+	Failures  int64     `json:"failures"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// DeadLetterStream returns the dead-letter stream name for a stream.
+func DeadLetterStream(stream string) string {
+	return stream + deadLetterSuffix
+}
+
+// withMetrics counts handler outcomes.
+func withMetrics(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, messageID string, data []byte) error {
+		err := next(ctx, messageID, data)
+		if err != nil {
+			metrics.Add(metricFailed, 1)
+		} else {
+			metrics.Add(metricProcessed, 1)
+		}
+		return err
+	}
+}
+
+// withAckDelay holds successful messages for the chaos.WorkerAckDelay
+// duration before they are ACKed, so a crash in that window leaves a message
+// that was handled (and recorded as processed) pending for redelivery.
+func withAckDelay(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, messageID string, data []byte) error {
+		if err := next(ctx, messageID, data); err != nil {
+			return err
+		}
+		chaos.Delay(ctx, chaos.WorkerAckDelay)
+		return nil
+	}
+}
+
+// withIdempotency skips messages already recorded in processed_messages and
+// records a message once the handler returns nil (i.e., it will be ACKed).
+func withIdempotency(stream string, repo *database.ProcessedMessageRepository, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, messageID string, data []byte) error {
+		processed, err := repo.IsProcessed(ctx, stream, messageID)
+		if err != nil {
+			return fmt.Errorf("error checking processed messages: %w", err)
+		}
+		if processed {
+			logger.Warn("Message already processed, skipping", zap.String("stream", stream), zap.String("messageID", messageID))
+			metrics.Add(metricSkipped, 1)
+			return nil
+		}
+
+		if err := next(ctx, messageID, data); err != nil {
+			return err
+		}
+
+		if err := repo.MarkProcessed(ctx, stream, messageID); err != nil {
+			return fmt.Errorf("failed to mark message as processed: %w", err)
+		}
+		return nil
+	}
+}
+
+// withDeadLetter counts failures per message in Redis. Once a message has
+// failed maxDeliveries times it is published to the dead-letter stream and
+// reported as handled so the queue ACKs it. maxDeliveries <= 0 disables the policy.
+func withDeadLetter(stream string, maxDeliveries int64, queue *streams.StreamQueue, next HandlerFunc) HandlerFunc {
+	if maxDeliveries <= 0 {
+		return next
+	}
+
+	return func(ctx context.Context, messageID string, data []byte) error {
+		handlerErr := next(ctx, messageID, data)
+		key := failureCountKeyPrefix + stream + ":" + messageID
+		if handlerErr == nil {
+			if _, err := cache.Delete(ctx, key); err != nil {
+				logger.Warn("Failed to clear failure count", zap.String("messageID", messageID), zap.Error(err))
+			}
+			return nil
+		}
+
+		failures, err := cache.Incr(ctx, key)
+		if err != nil {
+			// Can't count — leave the message pending for retry
+			return handlerErr
+		}
+		if err := cache.Expire(ctx, key, failureCountTTL); err != nil {
+			logger.Warn("Failed to set failure count expiry", zap.String("messageID", messageID), zap.Error(err))
+		}
+
+		if failures < maxDeliveries {
+			return handlerErr
+		}
+
+		letter := DeadLetter{
+			Stream:    stream,
+			MessageID: messageID,
+			Data:      string(data),
+			Error:     handlerErr.Error(),
+			Failures:  failures,
+			FailedAt:  time.Now().UTC(),
+		}
+		payload, err := json.Marshal(letter)
+		if err != nil {
+			return fmt.Errorf("failed to marshal dead letter: %w", err)
+		}
+		if _, err := queue.Publish(ctx, DeadLetterStream(stream), payload); err != nil {
+			// Keep the message pending rather than lose it
+			return handlerErr
+		}
+
+		logger.Error("Message moved to dead-letter stream",
+			zap.String("stream", stream),
+			zap.String("messageID", messageID),
+			zap.Int64("failures", failures),
+			zap.Error(handlerErr),
+		)
+		metrics.Add(metricDeadLettered, 1)
+		if _, err := cache.Delete(ctx, key); err != nil {
+			logger.Warn("Failed to clear failure count", zap.String("messageID", messageID), zap.Error(err))
+		}
+		return nil
+	}
+}
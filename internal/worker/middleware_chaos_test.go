@@ -0,0 +1,29 @@
+//go:build chaos
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"btc-giftcard/internal/chaos"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAckDelay(t *testing.T) {
+	defer chaos.Configure("")
+	assert.NoError(t, chaos.Configure("worker.ack_delay=50ms"))
+
+	ok := withAckDelay(func(ctx context.Context, messageID string, data []byte) error { return nil })
+	start := time.Now()
+	assert.NoError(t, ok(context.Background(), "1-0", nil))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "ACK held back")
+
+	failing := withAckDelay(func(ctx context.Context, messageID string, data []byte) error { return errors.New("boom") })
+	start = time.Now()
+	assert.Error(t, failing(context.Background(), "1-0", nil))
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "failures aren't delayed")
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	streams "btc-giftcard/pkg/queue"
+)
+
+// streamStatter is the part of queue.StreamQueue the scaling endpoint needs.
+type streamStatter interface {
+	Stats(ctx context.Context, stream, group string) (*streams.StreamStats, error)
+}
+
+// ScalingReport is the /scaling response: the consumer group's backlog and
+// how fast it is being worked off, for scaling workers on queue depth rather
+// than CPU. Every replica of a worker reports the same group, so any one of
+// them can be scraped.
+type ScalingReport struct {
+	streams.StreamStats
+	Backlog       int64     `json:"backlog"`         // Messages waiting or in progress: lag + pending
+	RatePerSecond float64   `json:"rate_per_second"` // Messages ACKed per second since the previous scrape (0 on the first)
+	DrainSeconds  float64   `json:"drain_seconds"`   // Backlog / rate; -1 if there is a backlog and nothing is being processed
+	ObservedAt    time.Time `json:"observed_at"`
+}
+
+// rateTracker turns successive group snapshots into a processing rate.
+type rateTracker struct {
+	mu        sync.Mutex
+	processed int64 // Entries read minus pending at the last snapshot
+	at        time.Time
+}
+
+// report builds a ScalingReport from stats, observed at now.
+func (t *rateTracker) report(stats *streams.StreamStats, now time.Time) *ScalingReport {
+	r := &ScalingReport{StreamStats: *stats, ObservedAt: now}
+
+	// Redis reports lag -1 when it can't tell (unread entries were trimmed);
+	// count the whole stream then, so the backlog is never underestimated.
+	lag := stats.Lag
+	if lag < 0 {
+		lag = stats.Length
+	}
+	r.Backlog = lag + stats.Pending
+
+	processed := stats.EntriesRead - stats.Pending
+	t.mu.Lock()
+	if !t.at.IsZero() && now.After(t.at) && processed >= t.processed {
+		r.RatePerSecond = float64(processed-t.processed) / now.Sub(t.at).Seconds()
+	}
+	t.processed, t.at = processed, now
+	t.mu.Unlock()
+
+	switch {
+	case r.Backlog == 0:
+		r.DrainSeconds = 0
+	case r.RatePerSecond > 0:
+		r.DrainSeconds = float64(r.Backlog) / r.RatePerSecond
+	default:
+		r.DrainSeconds = -1
+	}
+	return r
+}
+
+// scalingHandler serves the ScalingReport of group on stream as JSON. The
+// "backlog" field is meant for KEDA's metrics-api scaler (valueLocation:
+// backlog).
+func scalingHandler(queue streamStatter, stream, group string) http.HandlerFunc {
+	tracker := &rateTracker{}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		stats, err := queue.Stats(ctx, stream, group)
+		if err != nil {
+			if errors.Is(err, streams.ErrGroupNotFound) {
+				http.Error(w, "consumer group not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "redis unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(tracker.report(stats, time.Now().UTC()))
+	}
+}
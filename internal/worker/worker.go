@@ -0,0 +1,249 @@
+// Package worker provides the shared runtime for Redis Streams queue workers.
+//
+// Every worker needs the same wiring: load config, init the logger, connect to
+// Redis/Postgres (and optionally LND), declare the consumer group, run the
+// consume loop, expose health and scaling endpoints, and shut down cleanly on
+// SIGINT/SIGTERM.
+// Run does all of that so a worker's main.go only has to provide its handler:
+//
+//	worker.Run(ctx, worker.Options{
+//	    Name:         "fund-worker",
+//	    Stream:       "fund_card",
+//	    Group:        "fund_workers",
+//	    ConfigPath:   configPath,
+//	    Dependencies: []worker.Dependency{worker.Database},
+//	    Handler: func(deps *worker.Deps) (worker.HandlerFunc, error) {
+//	        h := newMessageHandler(deps.CardRepo, deps.TxRepo, provider)
+//	        return h.processMessage, nil
+//	    },
+//	})
+//
+// Workers that don't consume a stream (e.g. an LND subscription) set Loop
+// instead of Stream/Group/Handler and get the same wiring around it.
+//
+// Handlers are wrapped with two policies before being passed to the queue:
+//   - Idempotency: when the Database dependency is enabled, message IDs are
+//     recorded in processed_messages and redeliveries are skipped.
+//   - Dead-lettering: a message that fails MaxDeliveries times is copied to
+//     "<stream>:dlq" and ACKed so it stops blocking the pending list.
+//
+// For resilience testing, the ACK can be delayed with the chaos package
+// (CHAOS_FAULTS="worker.ack_delay=30s").
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"btc-giftcard/config"
+	"btc-giftcard/internal/guardrail"
+	"btc-giftcard/pkg/cache"
+	"btc-giftcard/pkg/logger"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/jinzhu/copier"
+	"go.uber.org/zap"
+)
+
+// HandlerFunc processes a single message from the stream.
+// Returning nil ACKs the message; returning an error leaves it pending for retry.
+type HandlerFunc func(ctx context.Context, messageID string, data []byte) error
+
+// LoopFunc runs a non-stream worker until ctx is cancelled.
+type LoopFunc func(ctx context.Context) error
+
+// Dependency names an optional external service a worker needs.
+// Redis is always initialized because the queue runs on it.
+type Dependency string
+
+const (
+	Database Dependency = "database" // Postgres pool + repositories
+	LND      Dependency = "lnd"      // LND gRPC client
+)
+
+// Options configures a worker run.
+type Options struct {
+	Name         string       // Consumer name prefix, e.g. "fund-worker"
+	Stream       string       // Redis stream to consume, e.g. "fund_card"
+	Group        string       // Consumer group, e.g. "fund_workers"
+	ConfigPath   config.Path  // Path to config.toml
+	Dependencies []Dependency // Services to initialize besides Redis
+
+	// Handler builds the message handler once dependencies are ready.
+	Handler func(deps *Deps) (HandlerFunc, error)
+
+	// Loop builds the main loop of a worker that doesn't consume a stream.
+	// Mutually exclusive with Stream, Group and Handler.
+	Loop func(deps *Deps) (LoopFunc, error)
+}
+
+func (o Options) validate() error {
+	if o.Name == "" {
+		return errors.New("worker name is required")
+	}
+	if o.Loop != nil {
+		if o.Stream != "" || o.Group != "" || o.Handler != nil {
+			return errors.New("loop workers cannot set stream, group or handler")
+		}
+	} else {
+		if o.Stream == "" {
+			return errors.New("stream is required")
+		}
+		if o.Group == "" {
+			return errors.New("group is required")
+		}
+		if o.Handler == nil {
+			return errors.New("handler is required")
+		}
+	}
+	for _, d := range o.Dependencies {
+		if d != Database && d != LND {
+			return fmt.Errorf("unknown dependency: %s", d)
+		}
+	}
+	return nil
+}
+
+func (o Options) needs(dep Dependency) bool {
+	for _, d := range o.Dependencies {
+		if d == dep {
+			return true
+		}
+	}
+	return false
+}
+
+// Run wires the worker's dependencies, starts the consume loop and health
+// endpoint, and blocks until ctx is cancelled or SIGINT/SIGTERM is received.
+func Run(ctx context.Context, opts Options) error {
+	if err := opts.validate(); err != nil {
+		return fmt.Errorf("invalid worker options: %w", err)
+	}
+
+	// Load configuration
+	var cfg config.ApiConfig
+	if err := config.Load(opts.ConfigPath, &cfg); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Initialize logger
+	var logCfg logger.Config
+	if err := copier.Copy(&logCfg, &cfg.Logging); err != nil {
+		return fmt.Errorf("failed to copy logging config: %w", err)
+	}
+	if err := logger.InitWithConfig(logger.GetEnv(), logCfg); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer logger.Sync()
+
+	logger.Info("Starting worker...", zap.String("worker", opts.Name), zap.String("stream", opts.Stream))
+
+	if err := guardrail.Validate(cfg); err != nil {
+		return fmt.Errorf("refusing to start: %w", err)
+	}
+
+	deps, err := newDeps(cfg, opts)
+	if err != nil {
+		return err
+	}
+	defer deps.close()
+
+	// Graceful shutdown context
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var loop LoopFunc
+	if opts.Loop != nil {
+		if loop, err = opts.Loop(deps); err != nil {
+			return fmt.Errorf("failed to build loop: %w", err)
+		}
+	} else {
+		if loop, err = consumeLoop(ctx, opts, cfg, deps); err != nil {
+			return err
+		}
+	}
+
+	debug, err := startDebugServer(cfg.Worker.DebugAddr)
+	if err != nil {
+		return err
+	}
+	health := startHealthServer(cfg.Worker.HealthAddr, deps, opts)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := loop(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Worker loop error", zap.Error(err))
+		}
+	}()
+
+	logger.Info("Worker is running",
+		zap.String("worker", opts.Name),
+		zap.String("health_addr", cfg.Worker.HealthAddr),
+	)
+
+	<-ctx.Done()
+	logger.Info("Received shutdown signal", zap.String("worker", opts.Name))
+
+	// Give the loop time to finish processing the current message
+	timeout := time.Duration(cfg.Worker.ShutdownTimeoutSeconds) * time.Second
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		logger.Warn("Worker loop did not stop before shutdown timeout", zap.Duration("timeout", timeout))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := health.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Failed to shut down health server", zap.Error(err))
+	}
+	if err := debug.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Failed to shut down debug server", zap.Error(err))
+	}
+
+	logger.Info("Worker shut down gracefully", zap.String("worker", opts.Name))
+	return nil
+}
+
+// consumeLoop builds the stream consumer loop: the handler wrapped with the
+// dead-letter, idempotency and metrics policies, consuming opts.Stream.
+func consumeLoop(ctx context.Context, opts Options, cfg config.ApiConfig, deps *Deps) (LoopFunc, error) {
+	handler, err := opts.Handler(deps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build handler: %w", err)
+	}
+	handler = withDeadLetter(opts.Stream, cfg.Worker.MaxDeliveries, deps.Queue, handler)
+	if deps.ProcessedRepo != nil {
+		handler = withIdempotency(opts.Stream, deps.ProcessedRepo, handler)
+	}
+	handler = withMetrics(handler)
+	handler = withAckDelay(handler)
+
+	if err := deps.Queue.DeclareStream(ctx, opts.Stream, opts.Group); err != nil {
+		return nil, fmt.Errorf("failed to declare the consumer group: %w", err)
+	}
+
+	consumerName := fmt.Sprintf("%s-%d", opts.Name, time.Now().Unix())
+	logger.Info("Consuming stream, waiting for messages...",
+		zap.String("stream", opts.Stream),
+		zap.String("group", opts.Group),
+		zap.String("consumer", consumerName),
+	)
+
+	return func(ctx context.Context) error {
+		return deps.Queue.Consume(ctx, opts.Stream, opts.Group, consumerName,
+			func(messageID string, data []byte) error {
+				return handler(ctx, messageID, data)
+			})
+	}, nil
+}
+
+// newQueue returns a StreamQueue bound to the shared Redis client.
+func newQueue() *streams.StreamQueue {
+	return streams.NewStreamQueue(cache.Client)
+}
@@ -0,0 +1,171 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"btc-giftcard/pkg/logger"
+	streams "btc-giftcard/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// Initialize logger for tests
+	_ = logger.Init("development")
+}
+
+func noopHandler(deps *Deps) (HandlerFunc, error) {
+	return func(ctx context.Context, messageID string, data []byte) error { return nil }, nil
+}
+
+func noopLoop(deps *Deps) (LoopFunc, error) {
+	return func(ctx context.Context) error { return nil }, nil
+}
+
+func TestOptions_Validate(t *testing.T) {
+	valid := Options{Name: "test-worker", Stream: "test", Group: "test_workers", Handler: noopHandler}
+
+	tests := []struct {
+		name        string
+		mutate      func(o *Options)
+		expectError bool
+	}{
+		{"Valid", func(o *Options) {}, false},
+		{"Valid with dependencies", func(o *Options) { o.Dependencies = []Dependency{Database, LND} }, false},
+		{"Missing name", func(o *Options) { o.Name = "" }, true},
+		{"Missing stream", func(o *Options) { o.Stream = "" }, true},
+		{"Missing group", func(o *Options) { o.Group = "" }, true},
+		{"Missing handler", func(o *Options) { o.Handler = nil }, true},
+		{"Unknown dependency", func(o *Options) { o.Dependencies = []Dependency{"kafka"} }, true},
+		{"Valid loop", func(o *Options) { *o = Options{Name: "test-worker", Loop: noopLoop} }, false},
+		{"Loop with stream", func(o *Options) { o.Handler = nil; o.Loop = noopLoop }, true},
+		{"Loop with handler", func(o *Options) { o.Stream, o.Group, o.Loop = "", "", noopLoop }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.mutate(&opts)
+			err := opts.validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOptions_Needs(t *testing.T) {
+	opts := Options{Dependencies: []Dependency{Database}}
+	assert.True(t, opts.needs(Database))
+	assert.False(t, opts.needs(LND))
+}
+
+func TestDeadLetterStream(t *testing.T) {
+	assert.Equal(t, "fund_card:dlq", DeadLetterStream("fund_card"))
+}
+
+func TestWithMetrics_CountsOutcomes(t *testing.T) {
+	counter := func(name string) int64 {
+		if v, ok := metrics.Get(name).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	processedBefore := counter(metricProcessed)
+	failedBefore := counter(metricFailed)
+
+	ok := withMetrics(func(ctx context.Context, messageID string, data []byte) error { return nil })
+	fail := withMetrics(func(ctx context.Context, messageID string, data []byte) error { return errors.New("boom") })
+
+	assert.NoError(t, ok(context.Background(), "1-0", nil))
+	assert.Error(t, fail(context.Background(), "2-0", nil))
+
+	assert.Equal(t, processedBefore+1, counter(metricProcessed))
+	assert.Equal(t, failedBefore+1, counter(metricFailed))
+}
+
+func TestWithDeadLetter_Disabled(t *testing.T) {
+	calls := 0
+	next := func(ctx context.Context, messageID string, data []byte) error {
+		calls++
+		return errors.New("boom")
+	}
+
+	// maxDeliveries <= 0 returns the handler unchanged (no Redis needed)
+	handler := withDeadLetter("test", 0, nil, next)
+	assert.Error(t, handler(context.Background(), "1-0", nil))
+	assert.Equal(t, 1, calls)
+}
+
+func TestCheckLoopback(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:8082", "[::1]:8082", "localhost:8082"} {
+		assert.NoError(t, checkLoopback(addr), addr)
+	}
+	for _, addr := range []string{":8082", "0.0.0.0:8082", "10.0.0.5:8082", "8082"} {
+		assert.Error(t, checkLoopback(addr), addr)
+	}
+}
+
+func TestRateTracker_Report(t *testing.T) {
+	tracker := &rateTracker{}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// First scrape: backlog but no rate yet
+	r := tracker.report(&streams.StreamStats{Lag: 40, Pending: 10, EntriesRead: 100}, start)
+	assert.Equal(t, int64(50), r.Backlog)
+	assert.Zero(t, r.RatePerSecond)
+	assert.Equal(t, float64(-1), r.DrainSeconds)
+
+	// 10s later 50 more messages were ACKed (entries read - pending: 90 -> 140)
+	r = tracker.report(&streams.StreamStats{Lag: 15, Pending: 5, EntriesRead: 145}, start.Add(10*time.Second))
+	assert.Equal(t, int64(20), r.Backlog)
+	assert.Equal(t, 5.0, r.RatePerSecond)
+	assert.Equal(t, 4.0, r.DrainSeconds)
+
+	// Unknown lag counts the whole stream
+	r = tracker.report(&streams.StreamStats{Length: 300, Lag: -1, Pending: 0, EntriesRead: 145}, start.Add(20*time.Second))
+	assert.Equal(t, int64(300), r.Backlog)
+
+	// Empty backlog drains immediately
+	r = tracker.report(&streams.StreamStats{EntriesRead: 145}, start.Add(30*time.Second))
+	assert.Zero(t, r.Backlog)
+	assert.Zero(t, r.DrainSeconds)
+}
+
+type fakeStatter struct {
+	stats *streams.StreamStats
+	err   error
+}
+
+func (f fakeStatter) Stats(ctx context.Context, stream, group string) (*streams.StreamStats, error) {
+	return f.stats, f.err
+}
+
+func TestScalingHandler(t *testing.T) {
+	handler := scalingHandler(fakeStatter{stats: &streams.StreamStats{Stream: "fund_card", Group: "fund_workers", Lag: 7, Pending: 3}}, "fund_card", "fund_workers")
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/scaling", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "fund_card", body["stream"])
+	assert.Equal(t, float64(10), body["backlog"])
+
+	rec = httptest.NewRecorder()
+	scalingHandler(fakeStatter{err: streams.ErrGroupNotFound}, "fund_card", "fund_workers")(rec, httptest.NewRequest(http.MethodGet, "/scaling", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	scalingHandler(fakeStatter{err: errors.New("connection refused")}, "fund_card", "fund_workers")(rec, httptest.NewRequest(http.MethodGet, "/scaling", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,12 @@
+-- Processed messages table: Records queue messages that a worker has fully handled.
+-- Workers check this table before processing so a crash between the side effects
+-- and the Redis ACK doesn't re-run the handler when the message is redelivered.
+CREATE TABLE IF NOT EXISTS processed_messages (
+    stream VARCHAR(100) NOT NULL,                -- Redis stream name (e.g., fund_card)
+    message_id VARCHAR(64) NOT NULL,             -- Redis stream message ID (e.g., 1700000000000-0)
+    processed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (stream, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_processed_messages_processed_at ON processed_messages(processed_at DESC);
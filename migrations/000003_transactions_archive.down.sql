@@ -0,0 +1,16 @@
+-- Move archived rows back so rolling back does not lose history
+INSERT INTO transactions (
+    id, card_id, type, redemption_method, tx_hash, payment_hash, payment_preimage,
+    lightning_invoice, from_address, to_address, btc_amount_sats, status,
+    confirmations, created_at, broadcast_at, confirmed_at
+)
+SELECT
+    id, card_id, type, redemption_method, tx_hash, payment_hash, payment_preimage,
+    lightning_invoice, from_address, to_address, btc_amount_sats, status,
+    confirmations, created_at, broadcast_at, confirmed_at
+FROM transactions_archive
+ON CONFLICT (id) DO NOTHING;
+
+DROP INDEX IF EXISTS idx_transactions_archive_card_id;
+
+DROP TABLE IF EXISTS transactions_archive;
@@ -0,0 +1,27 @@
+-- Archive of old transactions for terminal (redeemed/expired) cards.
+-- Same columns as transactions plus archived_at; rows are moved here by the
+-- archive_transactions job so the hot table and its indexes stay small.
+CREATE TABLE IF NOT EXISTS transactions_archive (
+    id UUID PRIMARY KEY,
+    card_id UUID NOT NULL,
+    type transaction_type NOT NULL,
+    redemption_method TEXT NULL,
+    tx_hash VARCHAR(64) NULL,
+    payment_hash VARCHAR(64) NULL,
+    payment_preimage VARCHAR(64) NULL,
+    lightning_invoice TEXT NULL,
+    from_address VARCHAR(100) NULL,
+    to_address VARCHAR(100) NULL,
+    btc_amount_sats BIGINT NOT NULL,
+    status transaction_status NOT NULL,
+    confirmations INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    broadcast_at TIMESTAMPTZ NULL,
+    confirmed_at TIMESTAMPTZ NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_transactions_archive_card FOREIGN KEY (card_id) REFERENCES cards (id) ON DELETE CASCADE
+);
+
+-- Archive is only queried per card (on demand), so a single index is enough
+CREATE INDEX IF NOT EXISTS idx_transactions_archive_card_id ON transactions_archive(card_id);
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_tx_hash ON transactions(tx_hash) WHERE tx_hash IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_cards_code ON cards(code);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_card_id ON transactions(card_id);
+DROP INDEX IF EXISTS idx_transactions_card_id_created_at;
@@ -0,0 +1,16 @@
+-- Index review (see `go run ./cmd/admin slow-queries`)
+--
+-- Already covered, no change needed:
+--   cards.status             → idx_cards_status
+--   cards.owner_email        → idx_cards_owner_email
+--   transactions.payment_hash → UNIQUE constraint index (transactions_payment_hash_key)
+
+-- ListByCardID filters on card_id and orders by created_at DESC: a composite
+-- index serves both without a sort, and supersedes the single-column index.
+CREATE INDEX IF NOT EXISTS idx_transactions_card_id_created_at ON transactions(card_id, created_at DESC);
+DROP INDEX IF EXISTS idx_transactions_card_id;
+
+-- Duplicates of the UNIQUE constraint indexes on the same columns: they only
+-- add write amplification.
+DROP INDEX IF EXISTS idx_cards_code;
+DROP INDEX IF EXISTS idx_transactions_tx_hash;
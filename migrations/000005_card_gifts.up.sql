@@ -0,0 +1,14 @@
+-- Optional gift personalization shown in notification emails and the voucher PDF.
+-- One row per card; absent for cards bought without a gift message.
+CREATE TABLE IF NOT EXISTS card_gifts (
+    card_id UUID PRIMARY KEY,
+    sender_name VARCHAR(100) NOT NULL DEFAULT '',
+    recipient_name VARCHAR(100) NOT NULL DEFAULT '',
+    message VARCHAR(500) NOT NULL DEFAULT '',   -- Plain text (HTML stripped by card service)
+    theme VARCHAR(32) NOT NULL DEFAULT 'classic',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMPTZ NULL,              -- Set when sent to the recipient; gift is read-only after
+
+    CONSTRAINT fk_card_gifts_card FOREIGN KEY (card_id) REFERENCES cards (id) ON DELETE CASCADE
+);
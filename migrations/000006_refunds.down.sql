@@ -0,0 +1,7 @@
+ALTER TABLE transactions_archive DROP COLUMN IF EXISTS external_reference;
+ALTER TABLE transactions_archive DROP COLUMN IF EXISTS fiat_amount_cents;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS external_reference;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fiat_amount_cents;
+
+-- Postgres cannot drop an enum value; 'refund' stays in transaction_type.
@@ -0,0 +1,10 @@
+-- Refunds: cashing out a card's remaining balance to the original payment method
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'refund';
+
+-- Fiat side of a transaction (refund amount paid out) and the external
+-- provider reference (e.g., payment provider refund ID)
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fiat_amount_cents BIGINT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS external_reference VARCHAR(100) NULL;
+
+ALTER TABLE transactions_archive ADD COLUMN IF NOT EXISTS fiat_amount_cents BIGINT NULL;
+ALTER TABLE transactions_archive ADD COLUMN IF NOT EXISTS external_reference VARCHAR(100) NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE cards DROP COLUMN IF EXISTS funding_target_sats;
+ALTER TABLE cards DROP COLUMN IF EXISTS funded_sats;
+
+-- Postgres cannot drop an enum value; 'partially_funded' stays in card_status.
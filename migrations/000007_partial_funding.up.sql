@@ -0,0 +1,14 @@
+-- Partial funding: large cards are funded in tranches as treasury allows.
+-- A partially funded card holds the sats reserved so far (btc_amount_sats)
+-- but can't be spent until funding completes and it becomes 'active'.
+ALTER TYPE card_status ADD VALUE IF NOT EXISTS 'partially_funded' AFTER 'funding';
+
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS funded_sats BIGINT NOT NULL DEFAULT 0;     -- Total sats reserved by funding tranches
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS funding_target_sats BIGINT NULL;           -- Sats the card must hold when fully funded (priced at first tranche)
+
+-- Fully funded cards funded before this migration
+UPDATE cards SET funded_sats = btc_amount_sats WHERE funded_at IS NOT NULL;
+
+-- Lookups by status use idx_cards_status. (A partial index on the new enum
+-- value can't be created here: Postgres forbids using an enum value in the
+-- transaction that added it.)
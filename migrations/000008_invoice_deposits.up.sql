@@ -0,0 +1,36 @@
+-- Incoming Lightning deposits (e.g., B2B top-ups paid to one of our invoices).
+-- A row is created pending when the invoice is issued; the invoice settlement
+-- worker marks it settled. Settled invoices that match no pending deposit are
+-- recorded too (account_id NULL), so nothing received goes unaccounted.
+CREATE TYPE deposit_status AS ENUM ('pending', 'settled');
+
+CREATE TABLE IF NOT EXISTS deposits (
+    id UUID PRIMARY KEY,
+    account_id VARCHAR(100) NULL,               -- Account credited; NULL = house treasury
+    payment_hash VARCHAR(64) NOT NULL UNIQUE,
+    payment_request TEXT NOT NULL DEFAULT '',
+    memo VARCHAR(255) NOT NULL DEFAULT '',      -- Carries "deposit:<id>" for correlation
+    expected_sats BIGINT NOT NULL DEFAULT 0,    -- 0 for unmatched deposits
+    received_sats BIGINT NULL,
+    settle_index BIGINT NULL,                   -- LND settle index (subscription resume point)
+    status deposit_status NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NULL,
+    settled_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_deposits_settle_index ON deposits (settle_index) WHERE settle_index IS NOT NULL;
+
+-- Append-only credits to accounts and the house treasury. One entry per settled deposit.
+CREATE TABLE IF NOT EXISTS treasury_ledger (
+    id UUID PRIMARY KEY,
+    account_id VARCHAR(100) NULL,               -- NULL = house treasury
+    deposit_id UUID NULL UNIQUE,
+    amount_sats BIGINT NOT NULL,                -- Positive = credit
+    entry_type VARCHAR(32) NOT NULL,            -- e.g. 'lightning_deposit'
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_treasury_ledger_deposit FOREIGN KEY (deposit_id) REFERENCES deposits (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_treasury_ledger_account_id ON treasury_ledger (account_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_redemption_id;
+
+ALTER TABLE transactions_archive DROP COLUMN IF EXISTS redemption_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS redemption_id;
@@ -0,0 +1,6 @@
+-- Split redemptions: one logical redemption paid partly over Lightning and
+-- partly on-chain. Both legs share a redemption_id (NULL for single-leg spends).
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS redemption_id UUID NULL;
+ALTER TABLE transactions_archive ADD COLUMN IF NOT EXISTS redemption_id UUID NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_redemption_id ON transactions (redemption_id) WHERE redemption_id IS NOT NULL;
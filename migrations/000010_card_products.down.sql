@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_cards_product_id;
+ALTER TABLE cards DROP CONSTRAINT IF EXISTS fk_cards_product;
+ALTER TABLE cards DROP COLUMN IF EXISTS product_id;
+
+DROP TABLE IF EXISTS card_products;
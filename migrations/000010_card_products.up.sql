@@ -0,0 +1,24 @@
+-- Card product catalog: fixed denominations (e.g. €25/€50/€100) with artwork
+-- and SKUs. Cards bought from a product keep a reference to it for reporting.
+CREATE TABLE IF NOT EXISTS card_products (
+    id UUID PRIMARY KEY,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    fiat_amount_cents BIGINT NOT NULL,          -- Face value
+    fiat_currency VARCHAR(3) NOT NULL,
+    artwork_url TEXT NOT NULL DEFAULT '',
+    -- Pricing rule: purchase price = face value + face value * fee_bps / 10000 + fee_fixed_cents
+    fee_bps BIGINT NOT NULL DEFAULT 0,
+    fee_fixed_cents BIGINT NOT NULL DEFAULT 0,
+    is_available BOOLEAN NOT NULL DEFAULT TRUE, -- Unavailable products can't be bought
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_card_products_amount CHECK (fiat_amount_cents > 0),
+    CONSTRAINT chk_card_products_fees CHECK (fee_bps >= 0 AND fee_fixed_cents >= 0)
+);
+
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS product_id UUID NULL;
+ALTER TABLE cards ADD CONSTRAINT fk_cards_product FOREIGN KEY (product_id) REFERENCES card_products (id);
+
+CREATE INDEX IF NOT EXISTS idx_cards_product_id ON cards (product_id) WHERE product_id IS NOT NULL;
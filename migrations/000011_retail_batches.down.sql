@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_cards_batch_id;
+ALTER TABLE cards DROP COLUMN IF EXISTS activated_at;
+ALTER TABLE cards DROP CONSTRAINT IF EXISTS fk_cards_batch;
+ALTER TABLE cards DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS card_batches;
+
+-- Postgres cannot drop an enum value; 'inactive' stays in card_status.
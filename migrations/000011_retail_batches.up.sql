@@ -0,0 +1,24 @@
+-- Retail inventory: cards are pre-generated in batches with zero balance and
+-- status 'inactive', printed, and activated at the point of sale (payment
+-- capture, then the normal created → funding → active flow).
+ALTER TYPE card_status ADD VALUE IF NOT EXISTS 'inactive' BEFORE 'created';
+
+CREATE TABLE IF NOT EXISTS card_batches (
+    id UUID PRIMARY KEY,
+    retailer VARCHAR(100) NOT NULL,              -- Retail partner the batch is distributed through
+    product_id UUID NULL REFERENCES card_products (id), -- Catalog product the cards are sold as (NULL = custom amount)
+    fiat_amount_cents BIGINT NOT NULL,           -- Face value of every card in the batch
+    fiat_currency VARCHAR(3) NOT NULL,
+    quantity INT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    printed_at TIMESTAMPTZ NULL,                 -- When codes were exported for printing
+
+    CONSTRAINT chk_card_batches_quantity CHECK (quantity > 0),
+    CONSTRAINT chk_card_batches_amount CHECK (fiat_amount_cents > 0)
+);
+
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS batch_id UUID NULL;
+ALTER TABLE cards ADD CONSTRAINT fk_cards_batch FOREIGN KEY (batch_id) REFERENCES card_batches (id);
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS activated_at TIMESTAMPTZ NULL; -- When a retail card was activated at the POS
+
+CREATE INDEX IF NOT EXISTS idx_cards_batch_id ON cards (batch_id) WHERE batch_id IS NOT NULL;
@@ -0,0 +1 @@
+-- Postgres cannot drop an enum value; 'fiat_payout' stays in transaction_type.
@@ -0,0 +1,4 @@
+-- Fiat payouts: redeeming a card to a bank account over SEPA Instant through
+-- an Open Banking PSP. The payout amount is in fiat_amount_cents (EUR) and the
+-- PSP payout ID in external_reference.
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'fiat_payout';
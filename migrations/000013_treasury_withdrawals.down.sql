@@ -0,0 +1,5 @@
+ALTER TABLE treasury_ledger DROP CONSTRAINT IF EXISTS fk_treasury_ledger_withdrawal;
+ALTER TABLE treasury_ledger DROP COLUMN IF EXISTS withdrawal_id;
+
+DROP TABLE IF EXISTS treasury_withdrawals;
+DROP TYPE IF EXISTS withdrawal_status;
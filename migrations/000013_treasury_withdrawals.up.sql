@@ -0,0 +1,28 @@
+-- Owner withdrawals of profit from the treasury. A withdrawal is requested by
+-- one operator and must be approved by another before anything is sent; the
+-- on-chain send is then recorded as a negative 'withdrawal' entry in
+-- treasury_ledger.
+CREATE TYPE withdrawal_status AS ENUM ('requested', 'approved', 'sent', 'rejected', 'failed');
+
+CREATE TABLE IF NOT EXISTS treasury_withdrawals (
+    id UUID PRIMARY KEY,
+    amount_sats BIGINT NOT NULL CHECK (amount_sats > 0),
+    destination_address VARCHAR(100) NOT NULL,  -- Must be in [withdrawals] allowed_destinations
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    requested_by VARCHAR(100) NOT NULL,
+    decided_by VARCHAR(100) NULL,               -- Approver or rejecter
+    status withdrawal_status NOT NULL DEFAULT 'requested',
+    tx_hash VARCHAR(64) NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMPTZ NULL,
+    sent_at TIMESTAMPTZ NULL,
+
+    -- Two-person rule, also enforced by the service
+    CONSTRAINT chk_withdrawal_two_person CHECK (decided_by IS NULL OR decided_by <> requested_by)
+);
+
+CREATE INDEX IF NOT EXISTS idx_treasury_withdrawals_created_at ON treasury_withdrawals (created_at DESC);
+
+ALTER TABLE treasury_ledger ADD COLUMN IF NOT EXISTS withdrawal_id UUID NULL UNIQUE;
+ALTER TABLE treasury_ledger ADD CONSTRAINT fk_treasury_ledger_withdrawal
+    FOREIGN KEY (withdrawal_id) REFERENCES treasury_withdrawals (id);
@@ -0,0 +1,3 @@
+ALTER TABLE cards DROP COLUMN IF EXISTS merchant_id;
+
+-- Postgres cannot drop an enum value; 'refunding' and 'refunded' stay in card_status.
@@ -0,0 +1,9 @@
+-- Refunds: a refund first moves the card to 'refunding' (balance kept and
+-- still reserved, but not spendable), then to 'refunded' with a zero balance
+-- once the payment provider accepted it, or back to 'active' if it declined.
+ALTER TYPE card_status ADD VALUE IF NOT EXISTS 'refunding' AFTER 'redeemed';
+ALTER TYPE card_status ADD VALUE IF NOT EXISTS 'refunded' AFTER 'refunding';
+
+-- Merchant a card was sold through (NULL = sold directly). Feature flags
+-- evaluated for a card (e.g. instant_refunds) use it as the subject's merchant.
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS merchant_id VARCHAR(100) NULL;
@@ -0,0 +1 @@
+-- Redaction is one-way: hashed invoices can't be restored.
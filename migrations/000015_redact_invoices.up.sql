@@ -0,0 +1,11 @@
+-- Paid BOLT11 invoices carry the payee's description (memo), which can hold
+-- personal data. Replace every stored invoice with its SHA-256, in the format
+-- database.HashInvoice writes ('sha256:<hex>'). New rows follow the
+-- [retention] invoice_memo_policy.
+UPDATE transactions
+SET lightning_invoice = 'sha256:' || encode(sha256(convert_to(lightning_invoice, 'UTF8')), 'hex')
+WHERE lightning_invoice IS NOT NULL AND lightning_invoice NOT LIKE 'sha256:%';
+
+UPDATE transactions_archive
+SET lightning_invoice = 'sha256:' || encode(sha256(convert_to(lightning_invoice, 'UTF8')), 'hex')
+WHERE lightning_invoice IS NOT NULL AND lightning_invoice NOT LIKE 'sha256:%';
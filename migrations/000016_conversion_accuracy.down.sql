@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS otc_fills;
+
+ALTER TABLE cards DROP COLUMN IF EXISTS funding_price;
@@ -0,0 +1,17 @@
+-- Price conversion accuracy: the BTC price each card was funded at, and the
+-- OTC purchases (fills) that actually bought the treasury's BTC. Comparing
+-- the two per currency and week shows how well the funding price tracks the
+-- realized cost.
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS funding_price DOUBLE PRECISION NULL;  -- Fiat per BTC used to price the card (quoted or market)
+
+CREATE TABLE IF NOT EXISTS otc_fills (
+    id UUID PRIMARY KEY,
+    fiat_currency VARCHAR(3) NOT NULL,
+    fiat_amount_cents BIGINT NOT NULL CHECK (fiat_amount_cents > 0),  -- Fiat paid, fees included
+    btc_amount_sats BIGINT NOT NULL CHECK (btc_amount_sats > 0),      -- BTC received
+    reference VARCHAR(100) NOT NULL DEFAULT '',                       -- OTC desk trade ID
+    filled_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_otc_fills_filled_at ON otc_fills (filled_at DESC);
@@ -0,0 +1,19 @@
+-- Restores the legacy wallet columns (nullable) only where the up migration
+-- retired them.
+DO $$
+BEGIN
+    IF to_regclass('legacy_card_wallets') IS NULL THEN
+        RETURN;
+    END IF;
+
+    ALTER TABLE cards ADD COLUMN IF NOT EXISTS wallet_address TEXT NULL;
+    ALTER TABLE cards ADD COLUMN IF NOT EXISTS encrypted_priv_key TEXT NULL;
+
+    UPDATE cards c
+    SET wallet_address = l.wallet_address, encrypted_priv_key = l.encrypted_priv_key
+    FROM legacy_card_wallets l
+    WHERE l.card_id = c.id;
+
+    DROP TABLE legacy_card_wallets;
+END
+$$;
@@ -0,0 +1,37 @@
+-- Databases created before the custodial model may still carry the per-card
+-- wallet columns (cards.wallet_address, cards.encrypted_priv_key): 000001
+-- creates cards IF NOT EXISTS, so it kept the old table as it was. Move any
+-- legacy wallet to legacy_card_wallets, so leftover funds can still be swept,
+-- and drop the columns. A database created by 000001 has neither column and
+-- this migration does nothing.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'cards'
+          AND column_name IN ('wallet_address', 'encrypted_priv_key')
+    ) THEN
+        RETURN;
+    END IF;
+
+    -- Either column may be missing on its own; add it so the copy below works
+    ALTER TABLE cards ADD COLUMN IF NOT EXISTS wallet_address TEXT NULL;
+    ALTER TABLE cards ADD COLUMN IF NOT EXISTS encrypted_priv_key TEXT NULL;
+
+    CREATE TABLE IF NOT EXISTS legacy_card_wallets (
+        card_id UUID PRIMARY KEY REFERENCES cards(id),
+        wallet_address TEXT NULL,
+        encrypted_priv_key TEXT NULL,                  -- Still encrypted with the legacy key
+        retired_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+    );
+
+    INSERT INTO legacy_card_wallets (card_id, wallet_address, encrypted_priv_key)
+    SELECT id, wallet_address::TEXT, encrypted_priv_key::TEXT
+    FROM cards
+    WHERE wallet_address IS NOT NULL OR encrypted_priv_key IS NOT NULL
+    ON CONFLICT (card_id) DO NOTHING;
+
+    ALTER TABLE cards DROP COLUMN wallet_address;
+    ALTER TABLE cards DROP COLUMN encrypted_priv_key;
+END
+$$;
@@ -0,0 +1,10 @@
+-- Codes replaced by card.Service.RotateCode after a suspected exposure. Kept
+-- for audit, and so a redemption attempt with an old code gets a specific
+-- error instead of "card not found".
+CREATE TABLE IF NOT EXISTS retired_card_codes (
+    code VARCHAR(50) PRIMARY KEY,                   -- The replaced code
+    card_id UUID NOT NULL REFERENCES cards(id),
+    retired_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_retired_card_codes_card_id ON retired_card_codes (card_id);
@@ -0,0 +1,13 @@
+#!/bin/bash
+set -e
+
+# Enable pg_stat_statements (requires shared_preload_libraries, see docker-compose.yml)
+# Used by `go run ./cmd/admin slow-queries`
+for db in btcgifter btcgifter_test;
+do
+
+    psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$db" <<-EOSQL
+        CREATE EXTENSION IF NOT EXISTS pg_stat_statements;
+EOSQL
+
+done
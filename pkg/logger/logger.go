@@ -1,7 +1,10 @@
 package logger
 
 import (
+	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -10,64 +13,169 @@ import (
 // Log is the global logger instance used throughout the application
 var Log *zap.Logger
 
+// level is the global logger's level, adjustable at runtime (see SetLevel and LevelHandler)
+var level = zap.NewAtomicLevelAt(zap.InfoLevel)
+
+// Config customizes the global logger beyond the environment defaults
+// (populated from config.toml [logging] section). Zero values keep the defaults.
+type Config struct {
+	Encoding string // "json" or "console" ("" = json in production, console otherwise)
+	Level    string // "debug", "info", "warn", "error" ("" = info in production, debug otherwise)
+
+	// File appends logs to this path besides stdout (on-prem deployments without a log collector)
+	File string
+
+	// Syslog sends logs to syslog: "local" for the local daemon, or "udp://host:514" / "tcp://host:514"
+	Syslog    string
+	SyslogTag string // Syslog tag ("" = btc-giftcard)
+
+	// Sampling of Debug and Info logs: per message and second, the first
+	// SampleInitial entries are logged, then every SampleThereafter-th.
+	// Warnings and errors are never sampled. 0 disables sampling.
+	SampleInitial    int
+	SampleThereafter int
+}
+
 // Init initializes the global logger based on the environment
 // environment: "development" for pretty console logs, "production" for JSON logs
 func Init(environment string) error {
-	var cfg zap.Config
-
-	if environment == "production" {
-		// Production: JSON format, Info level, write to stdout
-		cfg = zap.Config{
-			Level:            zap.NewAtomicLevelAt(zap.InfoLevel),
-			Encoding:         "json",
-			OutputPaths:      []string{"stdout"},
-			ErrorOutputPaths: []string{"stderr"},
-			EncoderConfig: zapcore.EncoderConfig{
-				TimeKey:        "timestamp",
-				LevelKey:       "level",
-				NameKey:        "logger",
-				CallerKey:      "caller",
-				MessageKey:     "message",
-				StacktraceKey:  "stacktrace",
-				LineEnding:     zapcore.DefaultLineEnding,
-				EncodeLevel:    zapcore.LowercaseLevelEncoder,
-				EncodeTime:     zapcore.ISO8601TimeEncoder,
-				EncodeDuration: zapcore.SecondsDurationEncoder,
-				EncodeCaller:   zapcore.ShortCallerEncoder,
-			},
+	return InitWithConfig(environment, Config{})
+}
+
+// InitWithConfig initializes the global logger with the environment defaults
+// overridden by cfg.
+func InitWithConfig(environment string, cfg Config) error {
+	production := environment == "production"
+
+	encoding := cfg.Encoding
+	if encoding == "" {
+		encoding = "console"
+		if production {
+			encoding = "json"
+		}
+	}
+	encoder, err := newEncoder(encoding, production)
+	if err != nil {
+		return err
+	}
+
+	lvl := zap.DebugLevel
+	if production {
+		lvl = zap.InfoLevel
+	}
+	if cfg.Level != "" {
+		if lvl, err = zapcore.ParseLevel(cfg.Level); err != nil {
+			return fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+		}
+	}
+	level.SetLevel(lvl)
+
+	sinks := []zapcore.WriteSyncer{zapcore.Lock(os.Stdout)}
+	if cfg.File != "" {
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		sinks = append(sinks, zapcore.Lock(f))
+	}
+	if cfg.Syslog != "" {
+		w, err := newSyslogSink(cfg.Syslog, cfg.SyslogTag)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, w)
+	}
+	out := zapcore.NewMultiWriteSyncer(sinks...)
+
+	var core zapcore.Core
+	if cfg.SampleInitial > 0 {
+		// Sample chatty levels only; warnings and errors always go through
+		chatty := zap.LevelEnablerFunc(func(l zapcore.Level) bool { return level.Enabled(l) && l < zap.WarnLevel })
+		important := zap.LevelEnablerFunc(func(l zapcore.Level) bool { return level.Enabled(l) && l >= zap.WarnLevel })
+		core = zapcore.NewTee(
+			zapcore.NewSamplerWithOptions(zapcore.NewCore(encoder, out, chatty), time.Second, cfg.SampleInitial, cfg.SampleThereafter),
+			zapcore.NewCore(encoder, out, important),
+		)
+	} else {
+		core = zapcore.NewCore(encoder, out, level)
+	}
+
+	Log = zap.New(core,
+		zap.AddCaller(),
+		zap.AddStacktrace(zap.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+	)
+	return nil
+}
+
+// newEncoder returns the JSON or console encoder. Production keeps the
+// long field names log collectors index; development uses short colored ones.
+func newEncoder(encoding string, production bool) (zapcore.Encoder, error) {
+	var encCfg zapcore.EncoderConfig
+	if production {
+		encCfg = zapcore.EncoderConfig{
+			TimeKey:        "timestamp",
+			LevelKey:       "level",
+			NameKey:        "logger",
+			CallerKey:      "caller",
+			MessageKey:     "message",
+			StacktraceKey:  "stacktrace",
+			LineEnding:     zapcore.DefaultLineEnding,
+			EncodeLevel:    zapcore.LowercaseLevelEncoder,
+			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			EncodeDuration: zapcore.SecondsDurationEncoder,
+			EncodeCaller:   zapcore.ShortCallerEncoder,
 		}
 	} else {
-		// Development: Pretty console format, Debug level, colored output
-		cfg = zap.Config{
-			Level:            zap.NewAtomicLevelAt(zap.DebugLevel),
-			Encoding:         "console",
-			OutputPaths:      []string{"stdout"},
-			ErrorOutputPaths: []string{"stderr"},
-			EncoderConfig: zapcore.EncoderConfig{
-				TimeKey:        "T",
-				LevelKey:       "L",
-				NameKey:        "N",
-				CallerKey:      "C",
-				MessageKey:     "M",
-				StacktraceKey:  "S",
-				LineEnding:     zapcore.DefaultLineEnding,
-				EncodeLevel:    zapcore.CapitalColorLevelEncoder,
-				EncodeTime:     zapcore.ISO8601TimeEncoder,
-				EncodeDuration: zapcore.StringDurationEncoder,
-				EncodeCaller:   zapcore.ShortCallerEncoder,
-			},
+		encCfg = zapcore.EncoderConfig{
+			TimeKey:        "T",
+			LevelKey:       "L",
+			NameKey:        "N",
+			CallerKey:      "C",
+			MessageKey:     "M",
+			StacktraceKey:  "S",
+			LineEnding:     zapcore.DefaultLineEnding,
+			EncodeLevel:    zapcore.CapitalColorLevelEncoder,
+			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			EncodeDuration: zapcore.StringDurationEncoder,
+			EncodeCaller:   zapcore.ShortCallerEncoder,
 		}
 	}
 
-	logger, err := cfg.Build()
-	if err != nil {
-		return err
+	switch encoding {
+	case "json":
+		if !production {
+			encCfg.EncodeLevel = zapcore.CapitalLevelEncoder // No color codes in JSON
+		}
+		return zapcore.NewJSONEncoder(encCfg), nil
+	case "console":
+		return zapcore.NewConsoleEncoder(encCfg), nil
+	default:
+		return nil, fmt.Errorf("invalid log encoding %q (supported: json, console)", encoding)
 	}
+}
 
-	Log = logger
+// SetLevel changes the global log level at runtime.
+func SetLevel(name string) error {
+	lvl, err := zapcore.ParseLevel(name)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q: %w", name, err)
+	}
+	level.SetLevel(lvl)
 	return nil
 }
 
+// GetLevel returns the current global log level.
+func GetLevel() string {
+	return level.Level().String()
+}
+
+// LevelHandler serves the global log level over HTTP: GET returns
+// {"level":"info"}, PUT with the same body changes it.
+func LevelHandler() http.Handler {
+	return level
+}
+
 // Sync flushes any buffered log entries
 // Should be called before application exits (typically with defer)
 func Sync() {
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitWithConfig_LevelAndEncoding(t *testing.T) {
+	require.NoError(t, InitWithConfig("production", Config{Level: "warn", Encoding: "console"}))
+	assert.Equal(t, "warn", GetLevel())
+
+	assert.Error(t, InitWithConfig("production", Config{Level: "loud"}))
+	assert.Error(t, InitWithConfig("production", Config{Encoding: "xml"}))
+	assert.Error(t, InitWithConfig("production", Config{Syslog: "ftp://host"}))
+}
+
+func TestInitWithConfig_FileSinkAndSampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, InitWithConfig("production", Config{File: path, SampleInitial: 2, SampleThereafter: 100}))
+
+	for i := 0; i < 10; i++ {
+		Info("message processed")
+	}
+	for i := 0; i < 3; i++ {
+		Warn("retrying")
+	}
+	Sync()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "message processed"), "info logs sampled")
+	assert.Equal(t, 3, strings.Count(string(data), "retrying"), "warnings never sampled")
+}
+
+func TestLevelHandler(t *testing.T) {
+	require.NoError(t, Init("production"))
+
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/debug/loglevel", bytes.NewBufferString(`{"level":"debug"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "debug", GetLevel())
+
+	require.NoError(t, SetLevel("error"))
+	rec = httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/loglevel", nil))
+	assert.JSONEq(t, `{"level":"error"}`, rec.Body.String())
+}
@@ -0,0 +1,35 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newSyslogSink connects to syslog: "local" for the local daemon, or
+// "udp://host:port" / "tcp://host:port" for a remote one. Entries are sent
+// at INFO priority; the level is part of the encoded entry.
+func newSyslogSink(addr, tag string) (zapcore.WriteSyncer, error) {
+	if tag == "" {
+		tag = "btc-giftcard"
+	}
+
+	network, raddr := "", ""
+	if addr != "local" {
+		var ok bool
+		network, raddr, ok = strings.Cut(addr, "://")
+		if !ok || (network != "udp" && network != "tcp") {
+			return nil, fmt.Errorf("invalid syslog address %q (expected local, udp://host:port or tcp://host:port)", addr)
+		}
+	}
+
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return zapcore.AddSync(w), nil
+}
@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newSyslogSink is unavailable: log/syslog doesn't support this platform.
+func newSyslogSink(_, _ string) (zapcore.WriteSyncer, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
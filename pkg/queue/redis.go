@@ -3,6 +3,7 @@ package queue
 import (
 	"btc-giftcard/pkg/logger"
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -10,6 +11,20 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrGroupNotFound is returned by Stats when the stream or consumer group doesn't exist
+var ErrGroupNotFound = errors.New("consumer group not found")
+
+// StreamStats is a snapshot of a consumer group's backlog on a stream.
+type StreamStats struct {
+	Stream      string `json:"stream"`
+	Group       string `json:"group"`
+	Length      int64  `json:"length"`       // Entries in the stream (trimmed to ~10000 by Publish)
+	Lag         int64  `json:"lag"`          // Entries not yet delivered to the group (-1 if Redis can't tell, e.g. after trimming unread entries)
+	Pending     int64  `json:"pending"`      // Delivered but not yet ACKed
+	Consumers   int64  `json:"consumers"`    // Consumers that joined the group (including ones that stopped)
+	EntriesRead int64  `json:"entries_read"` // Entries delivered to the group so far; its growth is the consume rate
+}
+
 // StreamQueue wraps Redis client for stream-based message queue operations
 type StreamQueue struct {
 	client *redis.Client
@@ -38,6 +53,57 @@ func (q *StreamQueue) DeclareStream(ctx context.Context, stream string, group st
 	return nil
 }
 
+// GroupExists reports whether the consumer group exists on the stream.
+// A missing stream is reported as a missing group, not an error.
+func (q *StreamQueue) GroupExists(ctx context.Context, stream string, group string) (bool, error) {
+	groups, err := q.client.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, g := range groups {
+		if g.Name == group {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Stats returns the backlog of a consumer group (XINFO GROUPS, Redis 7+ for
+// the lag and entries read). Returns ErrGroupNotFound if the stream or the
+// group doesn't exist.
+func (q *StreamQueue) Stats(ctx context.Context, stream string, group string) (*StreamStats, error) {
+	groups, err := q.client.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+
+	for _, g := range groups {
+		if g.Name != group {
+			continue
+		}
+		length, err := q.client.XLen(ctx, stream).Result()
+		if err != nil {
+			return nil, err
+		}
+		return &StreamStats{
+			Stream:      stream,
+			Group:       group,
+			Length:      length,
+			Lag:         g.Lag,
+			Pending:     g.Pending,
+			Consumers:   g.Consumers,
+			EntriesRead: g.EntriesRead,
+		}, nil
+	}
+	return nil, ErrGroupNotFound
+}
+
 // Publish adds a message to the specified stream
 // Returns the generated message ID
 func (q *StreamQueue) Publish(ctx context.Context, stream string, data []byte) (string, error) {
@@ -66,6 +66,61 @@ func TestStreamQueue_DeclareStream(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestStreamQueue_GroupExists(t *testing.T) {
+	q := setupTestRedis(t)
+	defer cleanupTestRedis(t)
+
+	ctx := context.Background()
+
+	exists, err := q.GroupExists(ctx, "test:missing", "test-group")
+	require.NoError(t, err)
+	assert.False(t, exists, "missing stream")
+
+	require.NoError(t, q.DeclareStream(ctx, "test:stream", "test-group"))
+
+	exists, err = q.GroupExists(ctx, "test:stream", "test-group")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = q.GroupExists(ctx, "test:stream", "other-group")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestStreamQueue_Stats(t *testing.T) {
+	q := setupTestRedis(t)
+	defer cleanupTestRedis(t)
+
+	ctx := context.Background()
+	stream := "test:stats"
+
+	_, err := q.Stats(ctx, stream, "test-group")
+	assert.ErrorIs(t, err, ErrGroupNotFound, "missing stream")
+
+	require.NoError(t, q.DeclareStream(ctx, stream, "test-group"))
+	for i := 0; i < 5; i++ {
+		_, err := q.Publish(ctx, stream, []byte(fmt.Sprintf("message-%d", i)))
+		require.NoError(t, err)
+	}
+
+	// Deliver 2 messages without ACKing them
+	_, err = cache.Client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: "test-group", Consumer: "c1", Streams: []string{stream, ">"}, Count: 2,
+	}).Result()
+	require.NoError(t, err)
+
+	stats, err := q.Stats(ctx, stream, "test-group")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stats.Length)
+	assert.Equal(t, int64(3), stats.Lag)
+	assert.Equal(t, int64(2), stats.Pending)
+	assert.Equal(t, int64(2), stats.EntriesRead)
+	assert.Equal(t, int64(1), stats.Consumers)
+
+	_, err = q.Stats(ctx, stream, "other-group")
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
+
 func TestStreamQueue_Publish(t *testing.T) {
 	q := setupTestRedis(t)
 	defer cleanupTestRedis(t)